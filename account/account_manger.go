@@ -1,10 +1,14 @@
 package account
 
 import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
 	"sync"
 
 	"github.com/Ankr-network/ankr-chain/common"
 	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/tendermint/tendermint/crypto/ed25519"
 )
 
 var (
@@ -12,32 +16,122 @@ var (
 	instanceAM *AccountManager
 )
 
+const (
+	StoreAdminAccountPrefix = "adminacc:"
+)
+
+var adminAccountTypes = []common.AccountType{common.AccountAdminOP, common.AccountAdminValidator, common.AccountAdminFound, common.AccountAdminMetering}
+
+// adminPubKeyNames are the store keys of the admin public keys set by the key msg, which override the ones of the manager
+var adminPubKeyNames = map[common.AccountType]string{
+	common.AccountAdminOP:        common.ADMIN_OP_PUBKEY_NAME,
+	common.AccountAdminValidator: common.ADMIN_OP_VAL_PUBKEY_NAME,
+	common.AccountAdminFound:     common.ADMIN_OP_FUND_PUBKEY_NAME,
+	common.AccountAdminMetering:  common.ADMIN_OP_METERING_PUBKEY_NAME,
+}
+
 type AccountManager struct {
-	adminAccMap map[common.AccountType]string
+	adminAccMap  map[common.AccountType]string
+	adminAccLock sync.RWMutex
+}
+
+func adminAccountKey(opType common.AccountType) []byte {
+	return []byte(fmt.Sprintf("%s%d", StoreAdminAccountPrefix, opType))
 }
 
 func (am *AccountManager) Init(store appstore.AppStore) {
+	am.adminAccLock.RLock()
+	defer am.adminAccLock.RUnlock()
+
 	for k, v := range am.adminAccMap {
 		store.AddAccount(v, k)
 	}
 }
 
+// Load replaces the built-in admin public keys with the ones set by the genesis app state
+func (am *AccountManager) Load(store appstore.AppStore) {
+	am.adminAccLock.Lock()
+	defer am.adminAccLock.Unlock()
+
+	for _, opType := range adminAccountTypes {
+		if pubKey := store.Get(adminAccountKey(opType)); len(pubKey) > 0 {
+			am.adminAccMap[opType] = string(pubKey)
+		}
+	}
+}
+
+// SetAdminOpAccount replaces the admin public key of the type and saves it into the store, so it is loaded after restart
+func (am *AccountManager) SetAdminOpAccount(store appstore.AppStore, opType common.AccountType, pubKey string) error {
+	isAdminType := false
+	for _, adminType := range adminAccountTypes {
+		if adminType == opType {
+			isAdminType = true
+			break
+		}
+	}
+	if !isAdminType {
+		return fmt.Errorf("not admin account type: %d", opType)
+	}
+
+	am.adminAccLock.Lock()
+	defer am.adminAccLock.Unlock()
+
+	store.Set(adminAccountKey(opType), []byte(pubKey))
+	am.adminAccMap[opType] = pubKey
+
+	return nil
+}
+
 func (am *AccountManager) GenesisAccountAddress() string {
+	am.adminAccLock.RLock()
+	defer am.adminAccLock.RUnlock()
+
     return am.adminAccMap[common.AccountGenesis]
 }
 
 func (am *AccountManager) FoundAccountAddress() string {
+	am.adminAccLock.RLock()
+	defer am.adminAccLock.RUnlock()
+
 	return am.adminAccMap[common.AccountFound]
 }
 
 func (am *AccountManager) AdminOpAccount(opType common.AccountType) string {
+	am.adminAccLock.RLock()
+	defer am.adminAccLock.RUnlock()
+
 	return am.adminAccMap[opType]
 }
 
+// AdminPubKey returns the base64 admin public key of the type, the one set in the store by the key msg goes first
+func (am *AccountManager) AdminPubKey(store appstore.AppStore, opType common.AccountType) string {
+	if keyName, ok := adminPubKeyNames[opType]; ok {
+		if pubKey := store.Get([]byte(keyName)); len(pubKey) > 0 {
+			return string(pubKey)
+		}
+	}
+
+	return am.AdminOpAccount(opType)
+}
+
+// IsAdminPubKey reports whether the public key of the signature is the admin public key of the type, the public key is
+// in the encoded form of the signature key
+func (am *AccountManager) IsAdminPubKey(store appstore.AppStore, opType common.AccountType, pubKey []byte) bool {
+	adminPubKeyBytes, err := base64.StdEncoding.DecodeString(am.AdminPubKey(store, opType))
+	if err != nil || len(adminPubKeyBytes) != ed25519.PubKeyEd25519Size {
+		return false
+	}
+
+	var adminPubKey ed25519.PubKeyEd25519
+	copy(adminPubKey[:], adminPubKeyBytes)
+
+	return bytes.Equal(pubKey, adminPubKey.Bytes())
+}
+
 func AccountManagerInstance() *AccountManager{
 	onceAM.Do(func(){
 		if common.RM == common.RunModeTesting {
-			instanceAM = &AccountManager{map[common.AccountType]string{
+			instanceAM = &AccountManager{adminAccMap: map[common.AccountType]string{
 				common.AccountGenesis : "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67",
 				common.AccountFound : "64BC85F08C03F42B17EAAF5AFFAF9BFAF96CFCB85CA2F3",
 				common.AccountAdminOP : "sxhP4F6OLZKNPQ2lG13WcHzitNX9++h56cppBDhwMlI=",
@@ -46,7 +140,7 @@ func AccountManagerInstance() *AccountManager{
 				common.AccountAdminMetering : "wvHG3EddBbXQHcyJal0CS/YQcNYtEbFYxejnqf9OhM4=",
 			}}
 		} else if common.RM == common.RunModeProd {
-			instanceAM = &AccountManager{map[common.AccountType]string{
+			instanceAM = &AccountManager{adminAccMap: map[common.AccountType]string{
 				common.AccountGenesis : "52E90523B5262E3AC2582F08A23068EE898D445EDF4D18",
 				common.AccountFound : "47A65FBF3FADD12B81959AA3D8DF5E300E8C9FBFF98770",
 				common.AccountAdminOP : "j90knB4tx3d6xi9KefyCl2FwS/hd/jpEj+cbHdzFcqM=",
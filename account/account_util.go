@@ -17,3 +17,12 @@ func DecodeAccount(cdc *amino.Codec, bytes []byte) (accInfo common.AccountInfo)
 	cdc.MustUnmarshalBinaryBare(bytes, &accInfo)
 	return
 }
+
+func EncodeMultiSigAccount(cdc *amino.Codec, msInfo *common.MultiSigAccountInfo) []byte {
+	return cdc.MustMarshalBinaryBare(msInfo)
+}
+
+func DecodeMultiSigAccount(cdc *amino.Codec, bytes []byte) (msInfo common.MultiSigAccountInfo) {
+	cdc.MustUnmarshalBinaryBare(bytes, &msInfo)
+	return
+}
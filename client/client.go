@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -18,10 +19,12 @@ import (
 	tmliteProxy "github.com/tendermint/tendermint/lite/proxy"
 	"github.com/tendermint/tendermint/rpc/client"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	rpcclient "github.com/tendermint/tendermint/rpc/lib/client"
 )
 
 type Client struct {
 	cHttp *client.HTTP
+	cRPC  *rpcclient.JSONRPCClient
 	cdc   *amino.Codec
 }
 
@@ -35,6 +38,10 @@ var storeNameMap = map[string]string {
 	"/store/account" : iavl.IavlStoreAccountKey,
 	"/store/currency" : iavl.IAvlStoreContractKey,
 	"/store/statisticalinfo" : iavl.IAvlStoreMainKey,
+	"/store/multisigaccount" : iavl.IavlStoreAccountKey,
+	"/store/mingasprice" : iavl.IAvlStoreMainKey,
+	"/store/feecurrency" : iavl.IAvlStoreMainKey,
+	"/store/upgradeplan" : iavl.IAvlStoreMainKey,
 }
 
 func getStoreName(path string) (string, error) {
@@ -48,7 +55,12 @@ func getStoreName(path string) (string, error) {
 func NewClient(nodeUrl string) *Client {
 	cHttp := client.NewHTTP(nodeUrl, "/websocket")
 
-	return &Client{cHttp, amino.NewCodec()}
+	cRPC := rpcclient.NewJSONRPCClient(nodeUrl)
+	rpcCdc := cRPC.Codec()
+	ctypes.RegisterAmino(rpcCdc)
+	cRPC.SetCodec(rpcCdc)
+
+	return &Client{cHttp, cRPC, amino.NewCodec()}
 }
 
 func (c *Client) Query(path string, req interface{}, resp interface{}) (err error) {
@@ -77,14 +89,14 @@ func (c *Client) Query(path string, req interface{}, resp interface{}) (err erro
 	return nil
 }
 
-func (c *Client) verifyProof(home string, reqPath string, resp abcitypes.ResponseQuery, proofVal []byte) error {
+func (c *Client) certifiedAppHash(home string, height int64) ([]byte, error) {
      if home == "" {
-     	return errors.New("home dir can't blank when need to verify")
+     	return nil, errors.New("home dir can't blank when need to verify")
 	 }
 
 	st, err := c.cHttp.Status()
 	if err !=nil {
-		return err
+		return nil, err
 	}
 
 	verifier, err := tmliteProxy.NewVerifier(
@@ -95,14 +107,23 @@ func (c *Client) verifyProof(home string, reqPath string, resp abcitypes.Respons
 		10,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	sHeader, err := tmliteProxy.GetCertifiedCommit(resp.Height, c.cHttp,  verifier)
+	sHeader, err := tmliteProxy.GetCertifiedCommit(height, c.cHttp,  verifier)
 	switch {
 	case tmliteErr.IsErrCommitNotFound(err):
-		return fmt.Errorf("can't find commit info: %w", err)
+		return nil, fmt.Errorf("can't find commit info: %w", err)
 	case err != nil:
+		return nil, err
+	}
+
+	return sHeader.Header.AppHash, nil
+}
+
+func (c *Client) verifyProof(home string, reqPath string, resp abcitypes.ResponseQuery, proofVal []byte) error {
+	appHash, err := c.certifiedAppHash(home, resp.Height)
+	if err != nil {
 		return err
 	}
 
@@ -118,13 +139,13 @@ func (c *Client) verifyProof(home string, reqPath string, resp abcitypes.Respons
 	kp = kp.AppendKey(resp.Key, merkle.KeyEncodingURL)
 
 	if resp.Value == nil {
-		err = prt.VerifyAbsence(resp.Proof, sHeader.Header.AppHash, kp.String())
+		err = prt.VerifyAbsence(resp.Proof, appHash, kp.String())
 		if err != nil {
 			return fmt.Errorf("failed to prove merkle proof:%w", err)
 		}
 		return nil
 	}
-	err = prt.VerifyValue(resp.Proof, sHeader.Header.AppHash, kp.String(), proofVal)
+	err = prt.VerifyValue(resp.Proof, appHash, kp.String(), proofVal)
 	if err != nil {
 		return fmt.Errorf("failed to prove merkle proof: %w", err)
 	}
@@ -165,6 +186,131 @@ func (c *Client) QueryWithOption(path string, height int64, needProofVerify bool
 	return nil
 }
 
+// Range lists a page of the kv pairs of the store in the range of the req at the height, the latest one if height is 0.
+// The page is verified against the certified app hash if needProofVerify is true.
+func (c *Client) Range(req *ankrcmm.RangeQueryReq, height int64, needProofVerify bool, home string) (*ankrcmm.RangeQueryResp, error) {
+	reqDataBytes, err := c.cdc.MarshalJSON(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resultQ, err := c.cHttp.ABCIQueryWithOptions("/store/"+iavl.RangeQueryPath, reqDataBytes, client.ABCIQueryOptions{height, needProofVerify})
+	if err != nil {
+		return nil, err
+	}
+
+	if resultQ.Response.Code != code.CodeTypeOK {
+		return nil, fmt.Errorf("Client range query response code not ok, code=%d, log=%s", resultQ.Response.Code, resultQ.Response.Log)
+	}
+
+	var qResp ankrcmm.QueryResp
+	err = c.cdc.UnmarshalJSON(resultQ.Response.Value, &qResp)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := new(ankrcmm.RangeQueryResp)
+	err = c.cdc.UnmarshalJSON(qResp.RespData, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	if needProofVerify {
+		err = c.verifyRangeProof(home, req, resultQ.Response, resp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+func (c *Client) verifyRangeProof(home string, req *ankrcmm.RangeQueryReq, resQuery abcitypes.ResponseQuery, resp *ankrcmm.RangeQueryResp) error {
+	if resQuery.Proof == nil || len(resQuery.Proof.Ops) != 1 {
+		return errors.New("no store proof of the range query")
+	}
+
+	storeRoot, err := iavl.VerifyRangeProof(req, resp)
+	if err != nil {
+		return fmt.Errorf("failed to prove range proof: %w", err)
+	}
+
+	op, err := iavl.IavlStoreMultiOpDecoder(resQuery.Proof.Ops[0])
+	if err != nil {
+		return err
+	}
+
+	roots, err := op.Run([][]byte{storeRoot})
+	if err != nil {
+		return fmt.Errorf("failed to prove store proof: %w", err)
+	}
+
+	appHash, err := c.certifiedAppHash(home, resQuery.Height)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(roots[0], appHash) {
+		return fmt.Errorf("range query app hash mismatch: expected %X, got %X", appHash, roots[0])
+	}
+
+	return nil
+}
+
+// EstimateGas executes the tx on the node's last committed state without changing anything, the tx can be unsigned
+func (c *Client) EstimateGas(txBytes []byte) (*ankrcmm.SimulateTxResp, error) {
+	resp := new(ankrcmm.SimulateTxResp)
+	err := c.Query("/simulate", &ankrcmm.SimulateTxReq{TxBytes: txBytes}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// BlockGas returns the gas used by the txs of the block being delivered, or the last block after it is committed
+func (c *Client) BlockGas() (*ankrcmm.BlockGasQueryResp, error) {
+	resp := new(ankrcmm.BlockGasQueryResp)
+	err := c.Query("/blockgas", &ankrcmm.BlockGasQueryReq{}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// Snapshots lists the state snapshots the node serves
+func (c *Client) Snapshots() (*ankrcmm.SnapshotsQueryResp, error) {
+	resp := new(ankrcmm.SnapshotsQueryResp)
+	err := c.Query("/snapshots", &ankrcmm.SnapshotsQueryReq{}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (c *Client) SnapshotChunk(height int64, format uint32, chunk uint32) ([]byte, error) {
+	resp := new(ankrcmm.SnapshotChunkQueryResp)
+	err := c.Query("/snapshotchunk", &ankrcmm.SnapshotChunkQueryReq{height, format, chunk}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Chunk, nil
+}
+
+// CommitInfo returns the root hashes of the stores committed at the height, the latest one if height is 0
+func (c *Client) CommitInfo(height int64) (*ankrcmm.CommitInfoQueryResp, error) {
+	resp := new(ankrcmm.CommitInfoQueryResp)
+	err := c.Query("/commitinfo", &ankrcmm.CommitInfoQueryReq{height}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 func (c *Client) BroadcastTxCommitWithRawResult(txBytes []byte) (*ctypes.ResultBroadcastTxCommit, error){
 	result, err := c.cHttp.BroadcastTxCommit(txBytes)
 	if err != nil {
@@ -277,6 +423,17 @@ func (c *Client) Validators(height *int64) (*ctypes.ResultValidators, error) {
 	return c.cHttp.Validators(height)
 }
 
+// ConsensusParams isn't wrapped by the tendermint http client, so it is called through the json rpc client
+func (c *Client) ConsensusParams(height *int64) (*ctypes.ResultConsensusParams, error) {
+	result := new(ctypes.ResultConsensusParams)
+	_, err := c.cRPC.Call("consensus_params", map[string]interface{}{"height": height}, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (c *Client) SubscribeAndWait(subscriber string, queryStr string, waitTimeOut time.Duration, maxSubCap int, out chan ctypes.ResultEvent) error {
 	c.cHttp.Start()
 
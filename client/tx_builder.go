@@ -16,6 +16,8 @@ type TxMsgHeader struct {
 	GasPrice  ankrcmm.Amount
 	Memo      string
 	Version   string
+	FeePayer  string
+	TimeoutHeight int64
 }
 
 type TxMsgBuilder struct {
@@ -30,11 +32,42 @@ func NewTxMsgBuilder(msgHeader TxMsgHeader, msgData tx.ImplTxMsg, serializer tx.
 }
 
 func (builder *TxMsgBuilder) BuildOnly(nonce uint64) ([]byte, error) {
-	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData}
+	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData, FeePayer: builder.msgHeader.FeePayer, TimeoutHeight: builder.msgHeader.TimeoutHeight}
 
 	return  txMsg.SignAndMarshal(builder.serializer, builder.key)
 }
 
+func (builder *TxMsgBuilder) BuildUnsigned(nonce uint64) *tx.TxMsg {
+	return &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData, FeePayer: builder.msgHeader.FeePayer, TimeoutHeight: builder.msgHeader.TimeoutHeight}
+}
+
+// BuildAppend adds the builder key's signature to the partial signed tx and keeps the signatures existed
+func (builder *TxMsgBuilder) BuildAppend(txMsg *tx.TxMsg) ([]byte, error) {
+	err := txMsg.AppendSign(builder.serializer, builder.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return builder.serializer.Serialize(txMsg)
+}
+
+// EstimateGas simulates the unsigned tx on the node, and the gas used can be set as the gas limit of the real tx
+func (builder *TxMsgBuilder) EstimateGas(c *Client) (*ankrcmm.SimulateTxResp, error) {
+	signer := builder.msgData.SignerAddr()
+	resp := &ankrcmm.NonceQueryResp{}
+	err := c.Query("/store/nonce", &ankrcmm.NonceQueryReq{signer[0]}, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	txBytes, err := builder.serializer.Serialize(builder.BuildUnsigned(resp.Nonce))
+	if err != nil {
+		return nil, err
+	}
+
+	return c.EstimateGas(txBytes)
+}
+
 func (builder *TxMsgBuilder) BuildAndCommitWithRawResult(c *Client) (*ctypes.ResultBroadcastTxCommit, error){
 	signer := builder.msgData.SignerAddr()
 	resp := &ankrcmm.NonceQueryResp{}
@@ -45,7 +78,7 @@ func (builder *TxMsgBuilder) BuildAndCommitWithRawResult(c *Client) (*ctypes.Res
 
 	nonce := resp.Nonce
 
-	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData}
+	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData, FeePayer: builder.msgHeader.FeePayer, TimeoutHeight: builder.msgHeader.TimeoutHeight}
 
 	txBytes, err := txMsg.SignAndMarshal(builder.serializer, builder.key)
 	if err != nil {
@@ -65,7 +98,7 @@ func (builder *TxMsgBuilder) BuildAndCommit(c *Client) (txHash string, commitHei
 
 	nonce := resp.Nonce
 
-	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData}
+	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData, FeePayer: builder.msgHeader.FeePayer, TimeoutHeight: builder.msgHeader.TimeoutHeight}
 
 	txBytes, err := txMsg.SignAndMarshal(builder.serializer, builder.key)
 	if err != nil {
@@ -85,7 +118,7 @@ func (builder *TxMsgBuilder) BuildAndBroadcastSync(c *Client) (data []byte, txHa
 
 	nonce := resp.Nonce
 
-	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData}
+	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData, FeePayer: builder.msgHeader.FeePayer, TimeoutHeight: builder.msgHeader.TimeoutHeight}
 
 	txBytes, err := txMsg.SignAndMarshal(builder.serializer, builder.key)
 	if err != nil {
@@ -114,7 +147,7 @@ func (builder *TxMsgBuilder) BuildAndBroadcastAsync(c *Client) (data []byte, txH
 
 	nonce := resp.Nonce
 
-	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData}
+	txMsg := &tx.TxMsg{ChID: builder.msgHeader.ChID, Nonce: nonce, GasLimit: builder.msgHeader.GasLimit, GasPrice: builder.msgHeader.GasPrice, Memo: builder.msgHeader.Memo, Version: builder.msgHeader.Version, ImplTxMsg: builder.msgData, FeePayer: builder.msgHeader.FeePayer, TimeoutHeight: builder.msgHeader.TimeoutHeight}
 
 	txBytes, err := txMsg.SignAndMarshal(builder.serializer, builder.key)
 	if err != nil {
@@ -0,0 +1,538 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/batch"
+	"github.com/Ankr-network/ankr-chain/tx/cdcv0"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/ankr-chain/tx/contract"
+	"github.com/Ankr-network/ankr-chain/tx/gasprice"
+	"github.com/Ankr-network/ankr-chain/tx/key"
+	"github.com/Ankr-network/ankr-chain/tx/metering"
+	"github.com/Ankr-network/ankr-chain/tx/multisig"
+	"github.com/Ankr-network/ankr-chain/tx/permission"
+	"github.com/Ankr-network/ankr-chain/tx/token"
+	"github.com/Ankr-network/ankr-chain/tx/upgrade"
+	"github.com/Ankr-network/ankr-chain/tx/validator"
+	tmcrypto "github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+const (
+	TxFormatProto = "proto"
+	TxFormatCDCV1 = "cdcv1"
+	TxFormatCDCV0 = "cdcv0"
+	TxFormatV0    = "v0"
+)
+
+// DecodedTx is the readable form of a tx in any wire format, the msg content is rendered into Payload by the
+// schema registered for its type
+type DecodedTx struct {
+	Hash          string        `json:"hash"`
+	Format        string        `json:"format"`
+	Type          string        `json:"type"`
+	ChainID       string        `json:"chainid,omitempty"`
+	Nonce         uint64        `json:"nonce"`
+	Signers       []string      `json:"signers"`
+	Fee           *DecodedFee   `json:"fee,omitempty"`
+	Memo          string        `json:"memo,omitempty"`
+	Version       string        `json:"version,omitempty"`
+	TimeoutHeight int64         `json:"timeoutheight,omitempty"`
+	Signs         []DecodedSign `json:"signs"`
+	Payload       interface{}   `json:"payload"`
+}
+
+type DecodedAmount struct {
+	Symbol  string `json:"symbol"`
+	Decimal int64  `json:"decimal"`
+	Value   string `json:"value"`
+}
+
+// DecodedFee shows the fee settings of the tx, MaxFee is the fee charged if all the gas limit is used up
+type DecodedFee struct {
+	Payer    string        `json:"payer"`
+	GasLimit string        `json:"gaslimit"`
+	GasPrice DecodedAmount `json:"gasprice"`
+	MaxFee   DecodedAmount `json:"maxfee"`
+}
+
+type DecodedSign struct {
+	Address    string `json:"address,omitempty"`
+	PubKeyType string `json:"pubkeytype,omitempty"`
+	PubKey     string `json:"pubkey,omitempty"`
+	Signed     string `json:"signed,omitempty"`
+	R          string `json:"r,omitempty"`
+	S          string `json:"s,omitempty"`
+	PubPEM     string `json:"pubpem,omitempty"`
+}
+
+type TransferPayload struct {
+	From    string          `json:"from"`
+	To      string          `json:"to"`
+	Amounts []DecodedAmount `json:"amounts"`
+}
+
+type ValidatorPayload struct {
+	Action       string        `json:"action"`
+	From         string        `json:"from"`
+	Name         string        `json:"name"`
+	PubKeyType   string        `json:"pubkeytype"`
+	PubKey       string        `json:"pubkey"`
+	StakeAddress string        `json:"stakeaddress"`
+	StakeAmount  DecodedAmount `json:"stakeamount"`
+	ValidHeight  uint64        `json:"validheight"`
+	SetFlag      uint32        `json:"setflag"`
+}
+
+type SetCertPayload struct {
+	From      string `json:"from"`
+	DCName    string `json:"dcname"`
+	PemBase64 string `json:"pembase64"`
+}
+
+type RemoveCertPayload struct {
+	From   string `json:"from"`
+	DCName string `json:"dcname"`
+	NSName string `json:"nsname"`
+}
+
+type MeteringPayload struct {
+	From   string `json:"from"`
+	DCName string `json:"dcname"`
+	NSName string `json:"nsname"`
+	Value  string `json:"value"`
+}
+
+type KeyPayload struct {
+	From     string `json:"from"`
+	KeyName  string `json:"keyname"`
+	KeyValue string `json:"keyvalue"`
+}
+
+// ContractDeployPayload shows the size and sha256 hash of the contract codes rather than the codes themselves
+type ContractDeployPayload struct {
+	From      string `json:"from"`
+	Name      string `json:"name"`
+	CodesSize int    `json:"codessize"`
+	CodesHash string `json:"codeshash"`
+	CodesDesc string `json:"codesdesc"`
+}
+
+type ContractInvokePayload struct {
+	From         string `json:"from"`
+	ContractAddr string `json:"contractaddr"`
+	Method       string `json:"method"`
+	Args         string `json:"args"`
+	RtnType      string `json:"rtntype"`
+}
+
+type AddRolePayload struct {
+	From         string `json:"from"`
+	Name         string `json:"name"`
+	RoleType     string `json:"roletype"`
+	PubKey       string `json:"pubkey"`
+	ContractAddr string `json:"contractaddr"`
+}
+
+type MultiSigAccountPayload struct {
+	From         string   `json:"from"`
+	MultiSigAddr string   `json:"multisigaddr"`
+	Threshold    uint32   `json:"threshold"`
+	PubKeys      []string `json:"pubkeys"`
+}
+
+type BatchSubMsg struct {
+	Type    string      `json:"type"`
+	Payload interface{} `json:"payload"`
+}
+
+type BatchPayload struct {
+	From string        `json:"from"`
+	Msgs []BatchSubMsg `json:"msgs"`
+}
+
+type MinGasPricePayload struct {
+	From        string        `json:"from"`
+	MinGasPrice DecodedAmount `json:"mingasprice"`
+}
+
+type FeeCurrencyPayload struct {
+	From         string `json:"from"`
+	Symbol       string `json:"symbol"`
+	ExchangeRate string `json:"exchangerate"`
+	Remove       bool   `json:"remove"`
+}
+
+type UpgradePayload struct {
+	From   string `json:"from"`
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+	Info   string `json:"info"`
+}
+
+// V0Payload keeps the fields of the v0 tx which has no corresponding tx msg
+type V0Payload struct {
+	Fields []string `json:"fields"`
+}
+
+// TxMsgSchema renders the tx msg of one type into its payload
+type TxMsgSchema func(msg interface{}) (interface{}, error)
+
+var (
+	txMsgSchemaMap  = make(map[string]TxMsgSchema)
+	txMsgSchemaLock sync.RWMutex
+)
+
+// RegisterTxMsgSchema adds or replaces the schema of msgType, so the decoder can render the tx msgs not built in
+func RegisterTxMsgSchema(msgType string, schema TxMsgSchema) {
+	txMsgSchemaLock.Lock()
+	defer txMsgSchemaLock.Unlock()
+
+	txMsgSchemaMap[msgType] = schema
+}
+
+func txMsgSchema(msgType string) (TxMsgSchema, bool) {
+	txMsgSchemaLock.RLock()
+	defer txMsgSchemaLock.RUnlock()
+
+	schema, ok := txMsgSchemaMap[msgType]
+
+	return schema, ok
+}
+
+// renderPayload renders the msg with the schema of its type, the msg itself is returned if there is no schema
+func renderPayload(msgType string, msg interface{}) (interface{}, error) {
+	schema, ok := txMsgSchema(msgType)
+	if !ok {
+		return msg, nil
+	}
+
+	return schema(msg)
+}
+
+func init() {
+	RegisterTxMsgSchema(txcmm.TxMsgTypeTransfer, transferSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeValidator, validatorSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeSetCertMsg, setCertSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeRemoveCertMsg, removeCertSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeMeteringMsg, meteringSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeKeyMsg, keySchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeContractDeployMsg, contractDeploySchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeContractInvokeMsg, contractInvokeSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeAddRole, addRoleSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeMultiSigAccountMsg, multiSigAccountSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeBatchMsg, batchSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeMinGasPriceMsg, minGasPriceSchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeFeeCurrencyMsg, feeCurrencySchema)
+	RegisterTxMsgSchema(txcmm.TxMsgTypeUpgradeMsg, upgradeSchema)
+}
+
+func unexpectedMsg(msgType string, msg interface{}) error {
+	return fmt.Errorf("unexpected %s msg: %T", msgType, msg)
+}
+
+func decodedAmount(amount ankrcmm.Amount) DecodedAmount {
+	return DecodedAmount{amount.Cur.Symbol, amount.Cur.Decimal, new(big.Int).SetBytes(amount.Value).String()}
+}
+
+func decodedPubKey(pubKey tmcrypto.PubKey) (string, string) {
+	switch pk := pubKey.(type) {
+	case ed25519.PubKeyEd25519:
+		return ankrcrypto.CryptoED25519, base64.StdEncoding.EncodeToString(pk[:])
+	case secp256k1.PubKeySecp256k1:
+		return ankrcrypto.CryptoSECP256K1, base64.StdEncoding.EncodeToString(pk[:])
+	case nil:
+		return "", ""
+	default:
+		return fmt.Sprintf("%T", pubKey), base64.StdEncoding.EncodeToString(pubKey.Bytes())
+	}
+}
+
+func transferSchema(msg interface{}) (interface{}, error) {
+	var from, to string
+	var amounts []ankrcmm.Amount
+	switch m := msg.(type) {
+	case *token.TransferMsg:
+		from, to, amounts = m.FromAddr, m.ToAddr, m.Amounts
+	case *cdcv0.TransferMsg:
+		from, to, amounts = m.FromAddr, m.ToAddr, m.Amounts
+	default:
+		return nil, unexpectedMsg(txcmm.TxMsgTypeTransfer, msg)
+	}
+
+	payload := &TransferPayload{From: from, To: to, Amounts: make([]DecodedAmount, 0, len(amounts))}
+	for _, amount := range amounts {
+		payload.Amounts = append(payload.Amounts, decodedAmount(amount))
+	}
+
+	return payload, nil
+}
+
+func validatorSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*validator.ValidatorMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeValidator, msg)
+	}
+
+	action := fmt.Sprintf("unknown(%d)", m.Action)
+	switch m.Action {
+	case 1:
+		action = "create"
+	case 2:
+		action = "update"
+	case 3:
+		action = "remove"
+	}
+
+	return &ValidatorPayload{
+		Action:       action,
+		From:         m.FromAddress,
+		Name:         m.Name,
+		PubKeyType:   m.PubKey.Type,
+		PubKey:       base64.StdEncoding.EncodeToString(m.PubKey.Data),
+		StakeAddress: m.StakeAddress,
+		StakeAmount:  decodedAmount(m.StakeAmount),
+		ValidHeight:  m.ValidHeight,
+		SetFlag:      uint32(m.SetFlag),
+	}, nil
+}
+
+func setCertSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*metering.SetCertMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeSetCertMsg, msg)
+	}
+
+	return &SetCertPayload{m.FromAddr, m.DCName, m.PemBase64}, nil
+}
+
+func removeCertSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*metering.RemoveCertMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeRemoveCertMsg, msg)
+	}
+
+	return &RemoveCertPayload{m.FromAddr, m.DCName, m.NSName}, nil
+}
+
+func meteringSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*metering.MeteringMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeMeteringMsg, msg)
+	}
+
+	return &MeteringPayload{m.FromAddr, m.DCName, m.NSName, m.Value}, nil
+}
+
+func keySchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*key.KeyMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeKeyMsg, msg)
+	}
+
+	return &KeyPayload{m.FromAddr, m.KeyName, m.KeyValue}, nil
+}
+
+func contractDeploySchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*contract.ContractDeployMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeContractDeployMsg, msg)
+	}
+
+	return &ContractDeployPayload{m.FromAddr, m.Name, len(m.Codes), fmt.Sprintf("%X", sha256.Sum256(m.Codes)), m.CodesDesc}, nil
+}
+
+func contractInvokeSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*contract.ContractInvokeMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeContractInvokeMsg, msg)
+	}
+
+	return &ContractInvokePayload{m.FromAddr, m.ContractAddr, m.Method, m.Args, m.RtnType}, nil
+}
+
+func addRoleSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*permission.AddRoleMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeAddRole, msg)
+	}
+
+	roleType := fmt.Sprintf("unknown(%d)", m.RoleType)
+	switch m.RoleType {
+	case ankrcmm.RoleGeneral:
+		roleType = "general"
+	case ankrcmm.RoleContract:
+		roleType = "contract"
+	}
+
+	_, pubKey := decodedPubKey(m.PubKey)
+
+	return &AddRolePayload{m.FromAddr, m.Name, roleType, pubKey, m.ContractAddr}, nil
+}
+
+func multiSigAccountSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*multisig.MultiSigAccountMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeMultiSigAccountMsg, msg)
+	}
+
+	return &MultiSigAccountPayload{m.FromAddr, m.MultiSigAddr, m.Threshold, m.PubKeys}, nil
+}
+
+func batchSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*batch.BatchMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeBatchMsg, msg)
+	}
+
+	payload := &BatchPayload{From: m.FromAddr, Msgs: make([]BatchSubMsg, 0, len(m.Msgs))}
+	for _, subMsg := range m.Msgs {
+		if subMsg == nil {
+			return nil, fmt.Errorf("blank sub msg in batch")
+		}
+
+		subPayload, err := renderPayload(subMsg.Type(), subMsg)
+		if err != nil {
+			return nil, err
+		}
+
+		payload.Msgs = append(payload.Msgs, BatchSubMsg{subMsg.Type(), subPayload})
+	}
+
+	return payload, nil
+}
+
+func minGasPriceSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*gasprice.MinGasPriceMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeMinGasPriceMsg, msg)
+	}
+
+	return &MinGasPricePayload{m.FromAddr, decodedAmount(m.MinGasPrice)}, nil
+}
+
+func feeCurrencySchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*gasprice.FeeCurrencyMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeFeeCurrencyMsg, msg)
+	}
+
+	return &FeeCurrencyPayload{m.FromAddr, m.Symbol, m.ExchangeRate, m.Remove}, nil
+}
+
+func upgradeSchema(msg interface{}) (interface{}, error) {
+	m, ok := msg.(*upgrade.UpgradeMsg)
+	if !ok {
+		return nil, unexpectedMsg(txcmm.TxMsgTypeUpgradeMsg, msg)
+	}
+
+	return &UpgradePayload{m.FromAddr, m.Name, m.Height, m.Info}, nil
+}
+
+func decodedSigns(signs []ankrcrypto.Signature) []DecodedSign {
+	decSigns := make([]DecodedSign, 0, len(signs))
+	for _, sign := range signs {
+		decSign := DecodedSign{R: sign.R, S: sign.S, PubPEM: sign.PubPEM}
+		if sign.PubKey != nil {
+			decSign.Address = sign.PubKey.Address().String()
+			decSign.PubKeyType, decSign.PubKey = decodedPubKey(sign.PubKey)
+		}
+		if len(sign.Signed) > 0 {
+			decSign.Signed = base64.StdEncoding.EncodeToString(sign.Signed)
+		}
+
+		decSigns = append(decSigns, decSign)
+	}
+
+	return decSigns
+}
+
+func decodedFee(feePayer string, gasLimit []byte, gasPrice ankrcmm.Amount) *DecodedFee {
+	gasLimitInt := new(big.Int).SetBytes(gasLimit)
+	maxFee := new(big.Int).Mul(gasLimitInt, new(big.Int).SetBytes(gasPrice.Value))
+
+	return &DecodedFee{
+		Payer:    feePayer,
+		GasLimit: gasLimitInt.String(),
+		GasPrice: decodedAmount(gasPrice),
+		MaxFee:   DecodedAmount{gasPrice.Cur.Symbol, gasPrice.Cur.Decimal, maxFee.String()},
+	}
+}
+
+func nonBlankAddrs(addrs []string) []string {
+	nonBlank := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		if addr != "" {
+			nonBlank = append(nonBlank, addr)
+		}
+	}
+
+	return nonBlank
+}
+
+func newDecodedTx(format string, txHash string, txMsg *tx.TxMsg) (*DecodedTx, error) {
+	payload, err := renderPayload(txMsg.Type(), txMsg.ImplTxMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	decTx := &DecodedTx{
+		Hash:          txHash,
+		Format:        format,
+		Type:          txMsg.Type(),
+		ChainID:       string(txMsg.ChID),
+		Nonce:         txMsg.Nonce,
+		Signers:       nonBlankAddrs(txMsg.SignerAddr()),
+		Memo:          txMsg.Memo,
+		Version:       txMsg.Version,
+		TimeoutHeight: txMsg.TimeoutHeight,
+		Signs:         decodedSigns(txMsg.Signs),
+		Payload:       payload,
+	}
+
+	if format != TxFormatV0 {
+		feePayer := ""
+		if len(decTx.Signers) > 0 {
+			feePayer = txMsg.FeePayerAddr()
+		}
+		decTx.Fee = decodedFee(feePayer, txMsg.GasLimit, txMsg.GasPrice)
+	}
+
+	return decTx, nil
+}
+
+func newDecodedTxCDCV0(txHash string, txMsg *tx.TxMsgCDCV0) (*DecodedTx, error) {
+	payload, err := renderPayload(txMsg.Type(), txMsg.ImplTxMsgCDCV0)
+	if err != nil {
+		return nil, err
+	}
+
+	signers := nonBlankAddrs(txMsg.SignerAddr())
+	feePayer := ""
+	if len(signers) > 0 {
+		feePayer = signers[0]
+	}
+
+	return &DecodedTx{
+		Hash:    txHash,
+		Format:  TxFormatCDCV0,
+		Type:    txMsg.Type(),
+		ChainID: string(txMsg.ChID),
+		Nonce:   txMsg.Nonce,
+		Signers: signers,
+		Fee:     decodedFee(feePayer, txMsg.GasLimit, txMsg.GasPrice),
+		Memo:    txMsg.Memo,
+		Version: txMsg.Version,
+		Signs:   decodedSigns(txMsg.Signs),
+		Payload: payload,
+	}, nil
+}
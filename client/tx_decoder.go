@@ -4,29 +4,52 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	ankrcmm "github.com/Ankr-network/ankr-chain/common"
 	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
 	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/key"
 	"github.com/Ankr-network/ankr-chain/tx/metering"
+	"github.com/Ankr-network/ankr-chain/tx/permission"
 	"github.com/Ankr-network/ankr-chain/tx/serializer"
 	"github.com/Ankr-network/ankr-chain/tx/token"
 	"github.com/Ankr-network/ankr-chain/tx/v0"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/crypto/tmhash"
 )
 
 type v0TxHandler func(txMsgData interface{}) (*tx.TxMsg, error)
 
 type TxDecoder struct {
-	cdcTxSerializer *serializer.TxSerializerCDC
-	v0TxSerializer  *v0.TxSerializerV0
-	v0TxHandlerMap  map[string]v0TxHandler
+	cdcTxSerializer   *serializer.TxSerializerCDC
+	protoTxSerializer *serializer.TxSerializerProto
+	txCDCV1           *amino.Codec
+	v0TxSerializer    *v0.TxSerializerV0
+	v0TxHandlerMap    map[string]v0TxHandler
+}
+
+// decoderTxMsgRegistry returns the registry with KeyMsg and AddRoleMsg registered besides the ones the node accepts,
+// so the txs built with them can be inspected too
+func decoderTxMsgRegistry() *tx.TxMsgRegistry {
+	registry := serializer.NewBuiltinTxMsgRegistry()
+	registry.Register(tx.TxMsgInfo{Type: new(key.KeyMsg).Type(), CodecName: "ankr-chain/tx/key/KeyMsg", Msg: &key.KeyMsg{}})
+	registry.Register(tx.TxMsgInfo{Type: new(permission.AddRoleMsg).Type(), CodecName: "ankr-chain/tx/permission/AddRoleMsg", Msg: &permission.AddRoleMsg{}})
+
+	return registry
 }
 
 func NewTxDecoder() *TxDecoder {
-	cdcTxSerializer := serializer.NewTxSerializerCDC()
+	return NewTxDecoderWithRegistry(decoderTxMsgRegistry())
+}
+
+// NewTxDecoderWithRegistry creates the decoder which knows every tx msg in the registry, the private msgs without
+// a registered TxMsgSchema are displayed as they are
+func NewTxDecoderWithRegistry(registry *tx.TxMsgRegistry) *TxDecoder {
+	cdcTxSerializer := serializer.NewTxSerializerCDCWithRegistry(registry)
 	v0TxSerializer  := &v0.TxSerializerV0{}
 
-	txD := &TxDecoder{ cdcTxSerializer: cdcTxSerializer, v0TxSerializer: v0TxSerializer}
+	txD := &TxDecoder{ cdcTxSerializer: cdcTxSerializer, protoTxSerializer: serializer.NewTxSerializerProtoWithRegistry(registry), txCDCV1: serializer.CreateTxCDCWithRegistry(registry), v0TxSerializer: v0TxSerializer}
 
 	txD.v0TxHandlerMap = map[string]v0TxHandler{
 		ankrcmm.TrxSendPrefix :     txD.sendTxHandler,
@@ -155,13 +178,29 @@ func (d *TxDecoder) meteringHandler(txMsgData interface{}) (*tx.TxMsg, error) {
 	}, nil
 }
 
+func (d *TxDecoder) deserializeCDCV1(txBytes []byte) (*tx.TxMsg, error) {
+	if len(txBytes) == 0 {
+		return nil, errors.New("nil tx")
+	}
+
+	var txMsg tx.TxMsg
+	err := d.txCDCV1.UnmarshalBinaryLengthPrefixed(txBytes, &txMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &txMsg, nil
+}
+
 func (d *TxDecoder) Decode(tx []byte) (*tx.TxMsg, error){
-	cdcTxSerializer := serializer.NewTxSerializerCDC()
-	if cdcTxSerializer == nil {
-		return nil, errors.New("can't creat tx cdc serializer")
+	if serializer.IsProtoTx(tx) {
+		txMsg, err := d.protoTxSerializer.DeserializeProto(tx)
+		if err == nil {
+			return txMsg, nil
+		}
 	}
 
-	txMsg, err := cdcTxSerializer.DeserializeCDCV1(tx)
+	txMsg, err := d.deserializeCDCV1(tx)
 	if err != nil {
 		txType, data, err := d.v0TxSerializer.Deserialize(tx)
 		if err != nil {
@@ -176,6 +215,44 @@ func (d *TxDecoder) Decode(tx []byte) (*tx.TxMsg, error){
 	}
 
 	return txMsg, nil
+}
+
+// DecodeTx decodes the tx in the proto, CDCV1, CDCV0 or V0 format into the readable DecodedTx
+func (d *TxDecoder) DecodeTx(txBytes []byte) (*DecodedTx, error) {
+	txHash := fmt.Sprintf("%X", tmhash.Sum(txBytes))
+
+	if serializer.IsProtoTx(txBytes) {
+		txMsg, err := d.protoTxSerializer.DeserializeProto(txBytes)
+		if err == nil {
+			return newDecodedTx(TxFormatProto, txHash, txMsg)
+		}
+	}
+
+	txMsg, err := d.deserializeCDCV1(txBytes)
+	if err == nil {
+		return newDecodedTx(TxFormatCDCV1, txHash, txMsg)
+	}
+
+	txMsgCDCV0, err := d.cdcTxSerializer.DeserializeCDCV0(txBytes)
+	if err == nil {
+		return newDecodedTxCDCV0(txHash, txMsgCDCV0)
+	}
+
+	txType, data, err := d.v0TxSerializer.Deserialize(txBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if txVoHandler, ok := d.v0TxHandlerMap[txType]; ok {
+		txMsg, err := txVoHandler(data)
+		if err != nil {
+			return nil, err
+		}
+
+		return newDecodedTx(TxFormatV0, txHash, txMsg)
+	}
 
+	fields, _ := data.([]string)
 
+	return &DecodedTx{Hash: txHash, Format: TxFormatV0, Type: strings.TrimRight(txType, "=:"), Signers: []string{}, Signs: []DecodedSign{}, Payload: &V0Payload{fields}}, nil
 }
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/ankr-chain/tx/contract"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/Ankr-network/ankr-chain/tx/token"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeTx(t *testing.T) {
+	key := crypto.NewSecretKeyEd25519("wmyZZoMedWlsPUDVCOy+TiVcrIBPcn3WJN8k5cPQgIvC8cbcR10FtdAdzIlqXQJL9hBw1i0RsVjF6Oep/06Ezg==")
+	gasPrice := ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()}
+
+	tfMsg := &token.TransferMsg{FromAddr: "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67",
+		ToAddr:  "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB",
+		Amounts: []ankrcmm.Amount{{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(6000000000000000000).Bytes()}},
+	}
+	txMsg := &tx.TxMsg{ChID: "ankr-chain", Nonce: 2, GasLimit: new(big.Int).SetUint64(1000).Bytes(), GasPrice: gasPrice, Memo: "transfer", Version: "1.0.2", ImplTxMsg: tfMsg}
+	txBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), key)
+	assert.Equal(t, nil, err)
+
+	decTx, err := NewTxDecoder().DecodeTx(txBytes)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, TxFormatCDCV1, decTx.Format)
+	assert.Equal(t, txcmm.TxMsgTypeTransfer, decTx.Type)
+	assert.Equal(t, []string{tfMsg.FromAddr}, decTx.Signers)
+	assert.Equal(t, "10000000000000000", decTx.Fee.MaxFee.Value)
+	assert.Equal(t, &TransferPayload{tfMsg.FromAddr, tfMsg.ToAddr, []DecodedAmount{{"ANKR", 18, "6000000000000000000"}}}, decTx.Payload)
+
+	ciMsg := &contract.ContractInvokeMsg{FromAddr: tfMsg.FromAddr, ContractAddr: "0000000000000000000000000000000000000000000001", Method: "balanceOf", Args: "[]", RtnType: "string"}
+	txMsg = &tx.TxMsg{ChID: "ankr-chain", Nonce: 3, GasLimit: new(big.Int).SetUint64(1000).Bytes(), GasPrice: gasPrice, ImplTxMsg: ciMsg}
+	txBytes, err = txMsg.SignAndMarshal(serializer.NewTxSerializerProto(), key)
+	assert.Equal(t, nil, err)
+
+	decTx, err = NewTxDecoder().DecodeTx(txBytes)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, TxFormatProto, decTx.Format)
+	assert.Equal(t, &ContractInvokePayload{ciMsg.FromAddr, ciMsg.ContractAddr, ciMsg.Method, ciMsg.Args, ciMsg.RtnType}, decTx.Payload)
+	assert.Equal(t, tfMsg.FromAddr, decTx.Signs[0].Address)
+
+	decTx, err = NewTxDecoder().DecodeTx([]byte("set_bal=B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67:100:1:pub:sig"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, TxFormatV0, decTx.Format)
+	assert.Equal(t, "set_bal", decTx.Type)
+}
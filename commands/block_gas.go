@@ -0,0 +1,7 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+func AddBlockGasNodeFlags(cmd *cobra.Command, maxBlockGas int64) {
+	cmd.Flags().Int64("maxblockgas", maxBlockGas, "The max total gas of the txs in a block, -1 means unlimited. It only takes effect on the genesis block, and the value in genesis consensus params is preferred")
+}
@@ -0,0 +1,8 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+func AddCongestionNodeFlags(cmd *cobra.Command, pendingTxs int, gasPriceMultiple uint64) {
+	cmd.Flags().Int("congestionpendingtxs", pendingTxs, "The number of the pending txs in mempool from which only the new txs paying congestiongaspricemultiple times the min gas price are accepted, 0 means never congested")
+	cmd.Flags().Uint64("congestiongaspricemultiple", gasPriceMultiple, "The multiple of the min gas price the new txs must pay while the mempool is congested")
+}
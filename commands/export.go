@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/log"
+	"github.com/Ankr-network/ankr-chain/store/appstore/iavl"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/go-amino"
+	cryptoAmino "github.com/tendermint/tendermint/crypto/encoding/amino"
+	"github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+)
+
+// ExportCmd dumps the app state of the stopped node into a genesis file, whose validators and other fields are
+// copied from the current genesis file and should be reviewed before starting the new chain
+var ExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the app state at the height into a genesis file",
+	RunE:  exportState,
+}
+
+func init() {
+	ExportCmd.Flags().Int64("height", 0, "The height of the app state exported, the latest one if 0")
+	ExportCmd.Flags().String("output", "", "The genesis file exported into, print it if blank")
+}
+
+func exportState(cmd *cobra.Command, args []string) error {
+	height, err := cmd.Flags().GetInt64("height")
+	if err != nil {
+		return err
+	}
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+
+	genDoc, err := types.GenesisDocFromFile(config.GenesisFile())
+	if err != nil {
+		return fmt.Errorf("can't load the genesis file: %v", err)
+	}
+
+	appStore := iavl.NewIavlStoreAppWithBackend(config.DBDir(), config.AppDBBackend, log.DefaultRootLogger.With("module", "AppStore"))
+	snapshot, err := appStore.Export(height)
+	if err != nil {
+		return fmt.Errorf("can't export the app state: %v", err)
+	}
+
+	appStateBytes, err := json.Marshal(&ankrcmm.GenesisAppState{Snapshot: snapshot})
+	if err != nil {
+		return err
+	}
+
+	genDoc.GenesisTime = tmtime.Now()
+	genDoc.AppState    = appStateBytes
+	if err := genDoc.ValidateAndComplete(); err != nil {
+		return err
+	}
+
+	if output != "" {
+		if err := genDoc.SaveAs(output); err != nil {
+			return err
+		}
+
+		log.DefaultRootLogger.Info("Exported the app state", "height", snapshot.Height, "file", output)
+
+		return nil
+	}
+
+	cdc := amino.NewCodec()
+	cryptoAmino.RegisterAmino(cdc)
+	genDocBytes, err := cdc.MarshalJSONIndent(genDoc, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(genDocBytes))
+
+	return nil
+}
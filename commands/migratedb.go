@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/Ankr-network/ankr-chain/log"
+	"github.com/Ankr-network/ankr-chain/store/appstore/iavl"
+	"github.com/spf13/cobra"
+)
+
+func AddAppDBNodeFlags(cmd *cobra.Command, appDBBackend string) {
+	cmd.Flags().String("appdbbackend", appDBBackend, "The db backend of the app store: goleveldb, cleveldb (built with gcc), badgerdb or memdb")
+}
+
+// MigrateDBCmd copies the app store db of the stopped node into the db of another backend, the node uses it after the
+// migrated db is moved into the db dir and appdbbackend is set to the new backend
+var MigrateDBCmd = &cobra.Command{
+	Use:   "migrate-db",
+	Short: "Copy the app store db into the db of another backend",
+	RunE:  migrateDB,
+}
+
+func init() {
+	MigrateDBCmd.Flags().String("frombackend", "", "The db backend migrated from, the configured appdbbackend if blank")
+	MigrateDBCmd.Flags().String("tobackend", "", "The db backend migrated to")
+	MigrateDBCmd.Flags().String("todir", "", "The dir the migrated db is created in, it must differ from the db dir and be empty")
+}
+
+func migrateDB(cmd *cobra.Command, args []string) error {
+	fromBackend, err := cmd.Flags().GetString("frombackend")
+	if err != nil {
+		return err
+	}
+
+	toBackend, err := cmd.Flags().GetString("tobackend")
+	if err != nil {
+		return err
+	}
+
+	toDir, err := cmd.Flags().GetString("todir")
+	if err != nil {
+		return err
+	}
+
+	if fromBackend == "" {
+		fromBackend = config.AppDBBackend
+	}
+
+	if toBackend == "" || toDir == "" {
+		return fmt.Errorf("tobackend and todir are required")
+	}
+
+	if filepath.Clean(toDir) == filepath.Clean(config.DBDir()) {
+		return fmt.Errorf("todir can't be the db dir %s", config.DBDir())
+	}
+
+	if entries, err := ioutil.ReadDir(toDir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("todir %s isn't empty", toDir)
+	}
+
+	srcDB, err := iavl.NewAppDB(fromBackend, config.DBDir())
+	if err != nil {
+		return err
+	}
+	defer srcDB.Close()
+
+	dstDB, err := iavl.NewAppDB(toBackend, toDir)
+	if err != nil {
+		return err
+	}
+	defer dstDB.Close()
+
+	count, err := iavl.MigrateDB(srcDB, dstDB, log.DefaultRootLogger.With("module", "AppStore"))
+	if err != nil {
+		return fmt.Errorf("can't migrate the app store db: %v", err)
+	}
+
+	log.DefaultRootLogger.Info("Migrated the app store db", "from", fromBackend, "to", toBackend, "dir", toDir, "count", count)
+
+	return nil
+}
@@ -0,0 +1,7 @@
+package commands
+
+import "github.com/spf13/cobra"
+
+func AddPendingNonceNodeFlags(cmd *cobra.Command, window uint64) {
+	cmd.Flags().Uint64("pendingnoncewindow", window, "The max number of the pending txs with the successive nonces per account in mempool")
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/Ankr-network/ankr-chain/log"
+	"github.com/Ankr-network/ankr-chain/store/appstore/iavl"
+	"github.com/spf13/cobra"
+)
+
+func AddRecoveryNodeFlags(cmd *cobra.Command, appHashMismatchRollback bool) {
+	cmd.Flags().Bool("apphashmismatchrollback", appHashMismatchRollback, "Roll the app state back to the last agreed height before halting when the app hash mismatches")
+}
+
+// RollbackCmd shows the app hash mismatch recorded by the stopped node and rolls its app state back, the blocks after
+// the height are replayed when the node is restarted
+var RollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Show the app hash mismatch recorded and roll the app state back to the height",
+	RunE:  rollbackState,
+}
+
+func init() {
+	RollbackCmd.Flags().Int64("height", 0, "The height the app state rolled back to, the last agreed one of the mismatch recorded if 0")
+	RollbackCmd.Flags().Bool("show", false, "Only show the app hash mismatch recorded")
+}
+
+func rollbackState(cmd *cobra.Command, args []string) error {
+	height, err := cmd.Flags().GetInt64("height")
+	if err != nil {
+		return err
+	}
+
+	show, err := cmd.Flags().GetBool("show")
+	if err != nil {
+		return err
+	}
+
+	appStore := iavl.NewIavlStoreAppWithBackend(config.DBDir(), config.AppDBBackend, log.DefaultRootLogger.With("module", "AppStore"))
+
+	mismatch := appStore.AppHashMismatch()
+	if mismatch != nil {
+		mismatchBytes, err := json.MarshalIndent(mismatch, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(mismatchBytes))
+	} else {
+		fmt.Println("No app hash mismatch recorded")
+	}
+
+	if show {
+		return nil
+	}
+
+	if height == 0 {
+		if mismatch == nil {
+			return fmt.Errorf("the height is required since no app hash mismatch is recorded")
+		}
+
+		height = mismatch.AgreedHeight
+	}
+
+	if err := appStore.LoadVersion(height); err != nil {
+		return fmt.Errorf("can't roll back the app state: %v", err)
+	}
+
+	log.DefaultRootLogger.Info("Rolled back the app state", "height", appStore.Height(), "appHash", fmt.Sprintf("%X", appStore.APPHash()))
+
+	return nil
+}
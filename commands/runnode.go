@@ -40,5 +40,12 @@ func NewRunNodeCmd(nodeProvider node.AnkrNodeProvider) *cobra.Command {
 	tmcorecmd.AddNodeFlags(cmd)
 	AddHistoryStorageNodeFlags(cmd, config.HistoryDB.Type, config.HistoryDB.Host, config.HistoryDB.Name)
 	AddPeerFilterNodeFlags(cmd, config.AllowedPeers)
+	AddPendingNonceNodeFlags(cmd, config.PendingNonceWindow)
+	AddCongestionNodeFlags(cmd, config.CongestionPendingTxs, config.CongestionGasPriceMultiple)
+	AddBlockGasNodeFlags(cmd, config.MaxBlockGas)
+	AddSnapshotNodeFlags(cmd, config.SnapshotInterval, config.SnapshotKeepRecent)
+	AddRecoveryNodeFlags(cmd, config.AppHashMismatchRollback)
+	AddPruningNodeFlags(cmd, config.Pruning, config.PruningKeepRecent, config.PruningKeepEvery)
+	AddAppDBNodeFlags(cmd, config.AppDBBackend)
 	return cmd
 }
@@ -0,0 +1,389 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Ankr-network/ankr-chain/client"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	ankrchain "github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/log"
+	"github.com/Ankr-network/ankr-chain/store/appstore/iavl"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/Ankr-network/ankr-chain/upgrade"
+	"github.com/spf13/cobra"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/blockchain"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	tmcorenode "github.com/tendermint/tendermint/node"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
+)
+
+const snapshotChunkRetries = 3
+
+func AddSnapshotNodeFlags(cmd *cobra.Command, snapshotInterval int64, snapshotKeepRecent int) {
+	cmd.Flags().Int64("snapshotinterval", snapshotInterval, "The block interval of taking the state snapshots served to the new nodes, 0 means no snapshot")
+	cmd.Flags().Int("snapshotkeeprecent", snapshotKeepRecent, "The number of the latest snapshots kept")
+}
+
+func AddPruningNodeFlags(cmd *cobra.Command, pruning string, pruningKeepRecent int64, pruningKeepEvery int64) {
+	cmd.Flags().String("pruning", pruning, "The pruning of the app state history: nothing, everything (keep the latest pruningkeeprecent versions) or keepevery (also keep every pruningkeepevery version)")
+	cmd.Flags().Int64("pruningkeeprecent", pruningKeepRecent, "The number of the latest versions of the app state kept")
+	cmd.Flags().Int64("pruningkeepevery", pruningKeepEvery, "The interval of the versions kept before the latest ones by the pruning keepevery")
+}
+
+// snapshotSource is where the snapshot and the headers verifying it are fetched from, the client of a trusted node
+type snapshotSource interface {
+	Snapshots() ([]*ankrcmm.SnapshotInfo, error)
+	SnapshotChunk(height int64, format uint32, chunk uint32) ([]byte, error)
+	Block(height int64) (*types.Block, error)
+	Commit(height int64) (*types.SignedHeader, error)
+	Validators(height int64) (*types.ValidatorSet, error)
+	ConsensusParams(height int64) (*types.ConsensusParams, error)
+}
+
+type clientSnapshotSource struct {
+	c *client.Client
+}
+
+func (css *clientSnapshotSource) Snapshots() ([]*ankrcmm.SnapshotInfo, error) {
+	resp, err := css.c.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Snapshots, nil
+}
+
+func (css *clientSnapshotSource) SnapshotChunk(height int64, format uint32, chunk uint32) ([]byte, error) {
+	return css.c.SnapshotChunk(height, format, chunk)
+}
+
+func (css *clientSnapshotSource) Block(height int64) (*types.Block, error) {
+	result, err := css.c.Block(&height)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Block, nil
+}
+
+func (css *clientSnapshotSource) Commit(height int64) (*types.SignedHeader, error) {
+	result, err := css.c.Commit(&height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.SignedHeader, nil
+}
+
+// Validators builds the set without NewValidatorSet, which would move the proposer priorities fetched
+func (css *clientSnapshotSource) Validators(height int64) (*types.ValidatorSet, error) {
+	result, err := css.c.Validators(&height)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := &types.ValidatorSet{Validators: result.Validators}
+	vals.GetProposer()
+
+	return vals, nil
+}
+
+func (css *clientSnapshotSource) ConsensusParams(height int64) (*types.ConsensusParams, error) {
+	result, err := css.c.ConsensusParams(&height)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result.ConsensusParams, nil
+}
+
+// RestoreCmd bootstraps the empty node from the snapshot served by the trusted node instead of replaying all the blocks.
+// The block at the snapshot height must hash to the trusted hash, and the next header signed by its validators gives
+// the app hash at the height. The app hash commits to the state only from the upgrade store-apphash, the snapshots
+// taken before it are checked against the total tx count only, so the node serving them must be fully trusted. Only
+// the block at the snapshot height is saved into the block store, so the node can't serve the blocks below it.
+var RestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the app state of the empty node from the snapshot of the trusted node",
+	RunE:  restoreState,
+}
+
+func init() {
+	RestoreCmd.Flags().String("node", "tcp://localhost:26657", "The rpc address of the trusted node serving the snapshots")
+	RestoreCmd.Flags().Int64("height", 0, "The height of the snapshot restored")
+	RestoreCmd.Flags().String("trusthash", "", "The hash in hex of the trusted block at the height")
+	RestoreCmd.Flags().Bool("list", false, "Only list the snapshots served by the node")
+}
+
+func restoreState(cmd *cobra.Command, args []string) error {
+	nodeUrl, err := cmd.Flags().GetString("node")
+	if err != nil {
+		return err
+	}
+
+	height, err := cmd.Flags().GetInt64("height")
+	if err != nil {
+		return err
+	}
+
+	trustHashHex, err := cmd.Flags().GetString("trusthash")
+	if err != nil {
+		return err
+	}
+
+	list, err := cmd.Flags().GetBool("list")
+	if err != nil {
+		return err
+	}
+
+	source := &clientSnapshotSource{client.NewClient(nodeUrl)}
+
+	if list {
+		snapshots, err := source.Snapshots()
+		if err != nil {
+			return fmt.Errorf("can't list the snapshots: %v", err)
+		}
+
+		snapshotsBytes, err := json.MarshalIndent(snapshots, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(snapshotsBytes))
+
+		return nil
+	}
+
+	if height <= 0 {
+		return fmt.Errorf("the height is required")
+	}
+
+	trustHash, err := hex.DecodeString(trustHashHex)
+	if err != nil || len(trustHash) == 0 {
+		return fmt.Errorf("invalid trusthash %q", trustHashHex)
+	}
+
+	genDoc, err := types.GenesisDocFromFile(config.GenesisFile())
+	if err != nil {
+		return fmt.Errorf("can't load the genesis file: %v", err)
+	}
+
+	tmConfig := config.TendermintCoreConfig()
+
+	blockStoreDB, err := tmcorenode.DefaultDBProvider(&tmcorenode.DBContext{ID: "blockstore", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer blockStoreDB.Close()
+
+	stateDB, err := tmcorenode.DefaultDBProvider(&tmcorenode.DBContext{ID: "state", Config: tmConfig})
+	if err != nil {
+		return err
+	}
+	defer stateDB.Close()
+
+	app := ankrchain.NewAnkrChainApplicationWithBackend(config.DBDir(), config.AppDBBackend, ankrcmm.APPName, serializer.NewBuiltinTxMsgRegistry(), upgrade.NewUpgradeManager(), log.DefaultRootLogger.With("module", "AnkrChainApp"))
+
+	if err := restoreSnapshot(app, source, genDoc.ChainID, height, trustHash, blockStoreDB, stateDB); err != nil {
+		return err
+	}
+
+	log.DefaultRootLogger.Info("Restored the snapshot, the node can be started now", "height", height, "appHash", fmt.Sprintf("%X", app.Info(abcitypes.RequestInfo{}).LastBlockAppHash))
+
+	return nil
+}
+
+// restoreSnapshot verifies the block at the height against the trusted hash and the next header against the validators
+// of the block, restores the snapshot whose app hash is the one in the next header, then saves the block and the state
+// at the height, so the tendermint handshake finds the stores and the app at the same height with the same app hash.
+// The state restored is checked against the app hash as far as the snapshot format permits.
+func restoreSnapshot(app *ankrchain.AnkrChainApplication, source snapshotSource, chainID string, height int64, trustHash []byte, blockStoreDB dbm.DB, stateDB dbm.DB) error {
+	if blockchain.LoadBlockStoreStateJSON(blockStoreDB).Height != 0 || !sm.LoadState(stateDB).IsEmpty() {
+		return fmt.Errorf("the block store and the state store must be empty")
+	}
+	if app.Info(abcitypes.RequestInfo{}).LastBlockHeight != 0 {
+		return fmt.Errorf("the app state must be empty")
+	}
+
+	block, err := source.Block(height)
+	if err != nil {
+		return fmt.Errorf("can't fetch the block %d: %v", height, err)
+	}
+	if err := block.ValidateBasic(); err != nil {
+		return fmt.Errorf("invalid block %d: %v", height, err)
+	}
+	if block.Height != height || block.ChainID != chainID {
+		return fmt.Errorf("the block %d of the chain %s isn't the one expected", block.Height, block.ChainID)
+	}
+	if !bytes.Equal(block.Hash(), trustHash) {
+		return fmt.Errorf("the block %d hash %X mismatches the trusted hash %X", height, block.Hash(), trustHash)
+	}
+
+	blockParts := block.MakePartSet(types.BlockPartSizeBytes)
+
+	// the app hash after the block is in the next header, which is verified by the commit of the validators of the block
+	nextHeader, err := source.Commit(height+1)
+	if err != nil {
+		return fmt.Errorf("can't fetch the commit %d: %v", height+1, err)
+	}
+	if nextHeader.Header == nil || nextHeader.Commit == nil || nextHeader.Height != height+1 || nextHeader.ChainID != chainID {
+		return fmt.Errorf("invalid signed header %d", height+1)
+	}
+	if !bytes.Equal(nextHeader.LastBlockID.Hash, trustHash) || !nextHeader.LastBlockID.PartsHeader.Equals(blockParts.Header()) {
+		return fmt.Errorf("the header %d doesn't follow the trusted block", height+1)
+	}
+
+	vals, err := source.Validators(height)
+	if err != nil {
+		return fmt.Errorf("can't fetch the validators %d: %v", height, err)
+	}
+	nextVals, err := source.Validators(height+1)
+	if err != nil {
+		return fmt.Errorf("can't fetch the validators %d: %v", height+1, err)
+	}
+	nextNextVals, err := source.Validators(height+2)
+	if err != nil {
+		return fmt.Errorf("can't fetch the validators %d: %v", height+2, err)
+	}
+	if !bytes.Equal(vals.Hash(), block.ValidatorsHash) || !bytes.Equal(nextVals.Hash(), block.NextValidatorsHash) ||
+		!bytes.Equal(nextVals.Hash(), nextHeader.ValidatorsHash) || !bytes.Equal(nextNextVals.Hash(), nextHeader.NextValidatorsHash) {
+		return fmt.Errorf("the validators fetched mismatch the headers")
+	}
+
+	if !bytes.Equal(nextHeader.Commit.BlockID.Hash, nextHeader.Hash()) {
+		return fmt.Errorf("the commit %d isn't for the header", height+1)
+	}
+	if err := nextVals.VerifyCommit(chainID, nextHeader.Commit.BlockID, height+1, nextHeader.Commit); err != nil {
+		return fmt.Errorf("invalid commit %d: %v", height+1, err)
+	}
+
+	// the commit of the block is saved as its seen commit, the handshake doesn't need the one in the next block
+	signedHeader, err := source.Commit(height)
+	if err != nil {
+		return fmt.Errorf("can't fetch the commit %d: %v", height, err)
+	}
+	if signedHeader.Commit == nil {
+		return fmt.Errorf("invalid signed header %d", height)
+	}
+	if err := vals.VerifyCommit(chainID, types.BlockID{Hash: trustHash, PartsHeader: blockParts.Header()}, height, signedHeader.Commit); err != nil {
+		return fmt.Errorf("invalid commit %d: %v", height, err)
+	}
+
+	params, err := source.ConsensusParams(height)
+	if err != nil {
+		return fmt.Errorf("can't fetch the consensus params %d: %v", height, err)
+	}
+	nextParams, err := source.ConsensusParams(height+1)
+	if err != nil {
+		return fmt.Errorf("can't fetch the consensus params %d: %v", height+1, err)
+	}
+	if !bytes.Equal(params.Hash(), block.ConsensusHash) || !bytes.Equal(nextParams.Hash(), nextHeader.ConsensusHash) {
+		return fmt.Errorf("the consensus params fetched mismatch the headers")
+	}
+
+	if err := applySnapshot(app, source, height, nextHeader.AppHash); err != nil {
+		return err
+	}
+
+	appInfo := app.Info(abcitypes.RequestInfo{})
+	if appInfo.LastBlockHeight != height || !bytes.Equal(appInfo.LastBlockAppHash, nextHeader.AppHash) {
+		return fmt.Errorf("the app restored is at the height %d with the app hash %X, expected %d and %X", appInfo.LastBlockHeight, appInfo.LastBlockAppHash, height, nextHeader.AppHash)
+	}
+
+	// SaveBlock only appends the next block, so the block store starts right below the height
+	blockchain.BlockStoreStateJSON{Height: height-1}.Save(blockStoreDB)
+	blockchain.NewBlockStore(blockStoreDB).SaveBlock(block, blockParts, signedHeader.Commit)
+
+	// SaveState stores the validators of the height after the next one and the params of the next one, so the states
+	// before the block are saved first to store the validators and the params loaded at the height and the next one
+	state := sm.State{
+		Version:                          sm.Version{Consensus: block.Version, Software: version.TMCoreSemVer},
+		ChainID:                          chainID,
+		LastBlockHeight:                  height-1,
+		LastBlockTotalTx:                 block.TotalTxs-block.NumTxs,
+		LastBlockID:                      block.LastBlockID,
+		LastBlockTime:                    block.Time,
+		NextValidators:                   nextVals,
+		Validators:                       vals,
+		LastValidators:                   types.NewValidatorSet(nil),
+		LastHeightValidatorsChanged:      height+1,
+		ConsensusParams:                  *params,
+		LastHeightConsensusParamsChanged: height,
+		LastResultsHash:                  block.LastResultsHash,
+		AppHash:                          block.AppHash,
+	}
+	if height > 1 {
+		stateBefore := state.Copy()
+		stateBefore.LastBlockHeight             = height-2
+		stateBefore.NextValidators              = vals
+		stateBefore.LastHeightValidatorsChanged = height
+		sm.SaveState(stateDB, stateBefore)
+	}
+	sm.SaveState(stateDB, state)
+
+	state.LastBlockHeight                  = height
+	state.LastBlockTotalTx                 = block.TotalTxs
+	state.LastBlockID                      = nextHeader.LastBlockID
+	state.NextValidators                   = nextNextVals
+	state.Validators                       = nextVals
+	state.LastValidators                   = vals
+	state.LastHeightValidatorsChanged      = height+2
+	state.ConsensusParams                  = *nextParams
+	state.LastHeightConsensusParamsChanged = height+1
+	state.LastResultsHash                  = nextHeader.LastResultsHash
+	state.AppHash                          = nextHeader.AppHash
+	sm.SaveState(stateDB, state)
+
+	return nil
+}
+
+// applySnapshot fetches the chunks in order, the chunk failing the check of its hash is fetched again a few times. The
+// snapshot of SnapshotFormatNodes is preferred, it's checked against the app hash.
+func applySnapshot(app *ankrchain.AnkrChainApplication, source snapshotSource, height int64, appHash []byte) error {
+	snapshots, err := source.Snapshots()
+	if err != nil {
+		return fmt.Errorf("can't list the snapshots: %v", err)
+	}
+
+	var info *ankrcmm.SnapshotInfo
+	for _, s := range snapshots {
+		if s.Height == height && (s.Format == iavl.SnapshotFormatNodes || s.Format == iavl.SnapshotFormat) {
+			if info == nil || s.Format == iavl.SnapshotFormatNodes {
+				info = s
+			}
+		}
+	}
+	if info == nil {
+		return fmt.Errorf("no snapshot at the height %d served", height)
+	}
+
+	if result := app.OfferSnapshot(info, appHash); result != ankrchain.SnapshotOfferAccept {
+		return fmt.Errorf("the snapshot %d is rejected: %d", height, result)
+	}
+
+	for index := uint32(0); index < info.Chunks; index++ {
+		for retry := 0; ; retry++ {
+			chunk, err := source.SnapshotChunk(info.Height, info.Format, index)
+			if err != nil {
+				return fmt.Errorf("can't fetch the snapshot chunk %d: %v", index, err)
+			}
+
+			result := app.ApplySnapshotChunk(index, chunk)
+			if result == ankrchain.SnapshotApplyAccept {
+				break
+			}
+			if result != ankrchain.SnapshotApplyRetry || retry >= snapshotChunkRetries {
+				return fmt.Errorf("can't apply the snapshot chunk %d: %d", index, result)
+			}
+
+			log.DefaultRootLogger.Info("Fetch the snapshot chunk again", "index", index, "retry", retry+1)
+		}
+	}
+
+	return nil
+}
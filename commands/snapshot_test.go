@@ -0,0 +1,229 @@
+package commands
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	ankrchain "github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/store/appstore/iavl"
+	"github.com/Ankr-network/ankr-chain/upgrade"
+	"github.com/stretchr/testify/assert"
+	abcitypes "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/blockchain"
+	"github.com/tendermint/tendermint/consensus"
+	"github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/proxy"
+	sm "github.com/tendermint/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+	tmtime "github.com/tendermint/tendermint/types/time"
+)
+
+type testSnapshotSource struct {
+	snapshots       []*ankrcmm.SnapshotInfo
+	chunks          map[uint32][]byte
+	corruptChunks   map[uint32]int
+	blocks          map[int64]*types.Block
+	signedHeaders   map[int64]*types.SignedHeader
+	vals            *types.ValidatorSet
+	consensusParams *types.ConsensusParams
+}
+
+func (tss *testSnapshotSource) Snapshots() ([]*ankrcmm.SnapshotInfo, error) {
+	return tss.snapshots, nil
+}
+
+// SnapshotChunk corrupts the chunk the times set in corruptChunks before returning it intact
+func (tss *testSnapshotSource) SnapshotChunk(height int64, format uint32, chunk uint32) ([]byte, error) {
+	chunkBytes := append([]byte(nil), tss.chunks[chunk]...)
+	if tss.corruptChunks[chunk] > 0 {
+		tss.corruptChunks[chunk]--
+		chunkBytes[len(chunkBytes)-1]++
+	}
+
+	return chunkBytes, nil
+}
+
+func (tss *testSnapshotSource) Block(height int64) (*types.Block, error) {
+	if block, ok := tss.blocks[height]; ok {
+		return block, nil
+	}
+
+	return nil, fmt.Errorf("no block %d", height)
+}
+
+func (tss *testSnapshotSource) Commit(height int64) (*types.SignedHeader, error) {
+	if signedHeader, ok := tss.signedHeaders[height]; ok {
+		return signedHeader, nil
+	}
+
+	return nil, fmt.Errorf("no commit %d", height)
+}
+
+func (tss *testSnapshotSource) Validators(height int64) (*types.ValidatorSet, error) {
+	return tss.vals.Copy(), nil
+}
+
+func (tss *testSnapshotSource) ConsensusParams(height int64) (*types.ConsensusParams, error) {
+	params := *tss.consensusParams
+	return &params, nil
+}
+
+// newTestSnapshotSource snapshots the app at the height 3 and signs the block 3 and the header 4 carrying its app hash,
+// the app hash commits to the stores from the height 1 if storeAppHash
+func newTestSnapshotSource(t *testing.T, chainID string, storeAppHash bool) *testSnapshotSource {
+	srcApp := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	srcApp.InitChain(abcitypes.RequestInitChain{ChainId: chainID})
+	for h := int64(1); h <= 3; h++ {
+		srcApp.BeginBlock(abcitypes.RequestBeginBlock{Header: abcitypes.Header{Height: h}})
+		if storeAppHash && h == 1 {
+			srcApp.AppStore().SetUpgradeDone(upgrade.UpgradeStoreAppHash, h)
+		}
+		srcApp.AppStore().SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(1000 + h).Bytes()})
+		srcApp.Commit()
+	}
+
+	info, err := srcApp.AppStore().CreateSnapshot(3)
+	assert.Equal(t, nil, err)
+
+	chunks := make(map[uint32][]byte)
+	for i := uint32(0); i < info.Chunks; i++ {
+		chunks[i] = srcApp.LoadSnapshotChunk(3, info.Format, i)
+	}
+
+	vals, privVals := types.RandValidatorSet(2, 10)
+	params         := types.DefaultConsensusParams()
+
+	commitOf := func(blockID types.BlockID, height int64) *types.Commit {
+		commit, err := types.MakeCommit(blockID, height, 0, types.NewVoteSet(chainID, height, 0, types.PrecommitType, vals), privVals)
+		assert.Equal(t, nil, err)
+		return commit
+	}
+
+	headerOf := func(height int64, lastBlockID types.BlockID, lastCommit *types.Commit, appHash []byte) *types.Block {
+		block := types.MakeBlock(height, nil, lastCommit, nil)
+		block.ChainID            = chainID
+		block.Time               = tmtime.Now()
+		block.TotalTxs           = 5
+		block.LastBlockID        = lastBlockID
+		block.ValidatorsHash     = vals.Hash()
+		block.NextValidatorsHash = vals.Hash()
+		block.ConsensusHash      = params.Hash()
+		block.AppHash            = appHash
+		block.LastResultsHash    = types.ABCIResults{}.Hash()
+		block.ProposerAddress    = vals.GetProposer().Address
+		return block
+	}
+
+	lastBlockID := types.BlockID{Hash: make([]byte, 32), PartsHeader: types.PartSetHeader{Total: 1, Hash: make([]byte, 32)}}
+	block       := headerOf(3, lastBlockID, commitOf(lastBlockID, 2), []byte("apphash2"))
+	blockID     := types.BlockID{Hash: block.Hash(), PartsHeader: block.MakePartSet(types.BlockPartSizeBytes).Header()}
+	commit      := commitOf(blockID, 3)
+
+	nextBlock   := headerOf(4, blockID, commit, info.AppHash)
+	nextBlockID := types.BlockID{Hash: nextBlock.Hash(), PartsHeader: nextBlock.MakePartSet(types.BlockPartSizeBytes).Header()}
+
+	return &testSnapshotSource{
+		snapshots:       []*ankrcmm.SnapshotInfo{info},
+		chunks:          chunks,
+		corruptChunks:   make(map[uint32]int),
+		blocks:          map[int64]*types.Block{3: block},
+		signedHeaders:   map[int64]*types.SignedHeader{
+			3: {Header: &block.Header, Commit: commit},
+			4: {Header: &nextBlock.Header, Commit: commitOf(nextBlockID, 4)},
+		},
+		vals:            vals,
+		consensusParams: params,
+	}
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	chainID := "ankr-chain"
+	source  := newTestSnapshotSource(t, chainID, false)
+	block   := source.blocks[3]
+	appHash := source.snapshots[0].AppHash
+
+	// the block not matching the trusted hash is refused before anything is restored
+	dstApp       := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	blockStoreDB := db.NewMemDB()
+	stateDB      := db.NewMemDB()
+	assert.NotEqual(t, nil, restoreSnapshot(dstApp, source, chainID, 3, make([]byte, 32), blockStoreDB, stateDB))
+	assert.Equal(t, int64(0), dstApp.Info(abcitypes.RequestInfo{}).LastBlockHeight)
+	assert.Equal(t, int64(0), blockchain.LoadBlockStoreStateJSON(blockStoreDB).Height)
+	assert.Equal(t, true, sm.LoadState(stateDB).IsEmpty())
+
+	// the app hash not signed by the validators is refused
+	forgedHeader := *source.signedHeaders[4].Header
+	forgedHeader.AppHash = []byte("forged")
+	source.signedHeaders[4] = &types.SignedHeader{Header: &forgedHeader, Commit: source.signedHeaders[4].Commit}
+	assert.NotEqual(t, nil, restoreSnapshot(dstApp, source, chainID, 3, block.Hash(), blockStoreDB, stateDB))
+	assert.Equal(t, int64(0), dstApp.Info(abcitypes.RequestInfo{}).LastBlockHeight)
+
+	// the chunk failing the verification is fetched again
+	source  = newTestSnapshotSource(t, chainID, false)
+	block   = source.blocks[3]
+	appHash = source.snapshots[0].AppHash
+	source.corruptChunks[0] = 1
+	assert.Equal(t, nil, restoreSnapshot(dstApp, source, chainID, 3, block.Hash(), blockStoreDB, stateDB))
+	assert.Equal(t, 0, source.corruptChunks[0])
+
+	appInfo := dstApp.Info(abcitypes.RequestInfo{})
+	assert.Equal(t, int64(3), appInfo.LastBlockHeight)
+	assert.Equal(t, appHash, appInfo.LastBlockAppHash)
+
+	blockStore := blockchain.NewBlockStore(blockStoreDB)
+	assert.Equal(t, int64(3), blockStore.Height())
+	assert.Equal(t, block.Hash(), blockStore.LoadBlock(3).Hash())
+	assert.Equal(t, block.Hash(), blockStore.LoadSeenCommit(3).BlockID.Hash)
+
+	state := sm.LoadState(stateDB)
+	assert.Equal(t, int64(3), state.LastBlockHeight)
+	assert.Equal(t, appHash, []byte(state.AppHash))
+	for h := int64(3); h <= 5; h++ {
+		vals, err := sm.LoadValidators(stateDB, h)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, source.vals.Hash(), vals.Hash())
+	}
+	for h := int64(3); h <= 4; h++ {
+		params, err := sm.LoadConsensusParams(stateDB, h)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, source.consensusParams.Hash(), params.Hash())
+	}
+
+	// tendermint starts from the restored height without replaying the blocks below it
+	proxyApp := proxy.NewAppConns(proxy.NewLocalClientCreator(dstApp))
+	assert.Equal(t, nil, proxyApp.Start())
+	defer proxyApp.Stop()
+	handshaker := consensus.NewHandshaker(stateDB, state, blockStore, &types.GenesisDoc{ChainID: chainID})
+	assert.Equal(t, nil, handshaker.Handshake(proxyApp))
+
+	// the node restored can't restore again
+	assert.NotEqual(t, nil, restoreSnapshot(dstApp, source, chainID, 3, block.Hash(), blockStoreDB, stateDB))
+}
+
+func TestRestoreSnapshotNodes(t *testing.T) {
+	chainID := "ankr-chain"
+	source  := newTestSnapshotSource(t, chainID, true)
+	block   := source.blocks[3]
+	appHash := source.snapshots[0].AppHash
+	assert.Equal(t, iavl.SnapshotFormatNodes, source.snapshots[0].Format)
+
+	dstApp       := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	blockStoreDB := db.NewMemDB()
+	stateDB      := db.NewMemDB()
+	assert.Equal(t, nil, restoreSnapshot(dstApp, source, chainID, 3, block.Hash(), blockStoreDB, stateDB))
+
+	appInfo := dstApp.Info(abcitypes.RequestInfo{})
+	assert.Equal(t, int64(3), appInfo.LastBlockHeight)
+	assert.Equal(t, appHash, appInfo.LastBlockAppHash)
+
+	state      := sm.LoadState(stateDB)
+	blockStore := blockchain.NewBlockStore(blockStoreDB)
+	proxyApp   := proxy.NewAppConns(proxy.NewLocalClientCreator(dstApp))
+	assert.Equal(t, nil, proxyApp.Start())
+	defer proxyApp.Stop()
+	handshaker := consensus.NewHandshaker(stateDB, state, blockStore, &types.GenesisDoc{ChainID: chainID})
+	assert.Equal(t, nil, handshaker.Handshake(proxyApp))
+}
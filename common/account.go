@@ -27,6 +27,7 @@ const (
 	AccountAdminMetering
 	AccountGeneral
 	AccountContract
+	AccountMultiSig
 )
 
 type AccountInfo struct {
@@ -38,6 +39,20 @@ type AccountInfo struct {
 	Roles   []string     `json:"roles"`
 }
 
+type MultiSigAccountInfo struct {
+	Address   string   `json:"address"`
+	Threshold uint32   `json:"threshold"`
+	PubKeys   []string `json:"pubkeys"`
+}
+
+// FeeCurrencyInfo whitelists a currency for paying the tx fee, ExchangeRate is the amount of the currency equivalent to
+// one ANKR in whole tokens, it can be a decimal or fraction, eg. "0.5" or "1/2". The smallest units are converted by
+// the decimals of the currency and ANKR.
+type FeeCurrencyInfo struct {
+	Symbol       string `json:"symbol"`
+	ExchangeRate string `json:"exchangerate"`
+}
+
 type AllowanceInfo struct {
 	sender  string
 	spender string
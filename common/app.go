@@ -24,6 +24,7 @@ const (
 	RMV_CRT_NONCE string = "rmv_crt_nonce"
 	SET_OP_NONCE string = "admin_nonce"
 	SET_VAL_NONCE string = "val_nonce"
+	ADMIN_OP_PUBKEY_NAME string = "admin_op_pubkey"
 	ADMIN_OP_VAL_PUBKEY_NAME string = "admin_op_val_pubkey"
 	ADMIN_OP_FUND_PUBKEY_NAME string = "admin_op_fund_pubkey"
 	ADMIN_OP_METERING_PUBKEY_NAME string = "admin_op_metering_pubkey"
@@ -46,3 +47,10 @@ type CommitID struct {
 	Hash    []byte
 }
 
+// CommitState is the last committed state reported to the consensus engine in the Info handshake
+type CommitState struct {
+	Height  int64
+	AppHash []byte
+	ChainID string
+}
+
@@ -47,4 +47,20 @@ const (
 	CodeTypeRoleExisted              uint32 = 39
 	CodeTypeRoleNotExisted           uint32 = 40
 	CodeTypeRoleNotMismatch          uint32 = 41
+	CodeTypeMultiSigInvalidThreshold uint32 = 42
+	CodeTypeMultiSigInvalidPubKey    uint32 = 43
+	CodeTypeMultiSigAccountExisted   uint32 = 44
+	CodeTypeMultiSigAccountNotExist  uint32 = 45
+	CodeTypeMultiSigNotEnoughSigns   uint32 = 46
+	CodeTypeBatchMsgInvalid          uint32 = 47
+	CodeTypeInvalidMinGasPrice       uint32 = 48
+	CodeTypeInvalidFeeCurrency       uint32 = 49
+	CodeTypeInvalidFeePayer          uint32 = 50
+	CodeTypeTxExpired                uint32 = 51
+	CodeTypeUnknownTxMsgType         uint32 = 52
+	CodeTypeBlockGasExhausted        uint32 = 53
+	CodeTypeSnapshotChunkNotFound    uint32 = 54
+	CodeTypeInvalidUpgradePlan       uint32 = 55
+	CodeTypeCommitInfoNotFound       uint32 = 56
+	CodeTypeHeightPruned             uint32 = 57
 )
@@ -0,0 +1,319 @@
+package common
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	GenesisAdminOP        = "adminop"
+	GenesisAdminValidator = "adminvalidator"
+	GenesisAdminFound     = "adminfound"
+	GenesisAdminMetering  = "adminmetering"
+)
+
+var genesisAdminTypeMap = map[string]AccountType{
+	GenesisAdminOP:        AccountAdminOP,
+	GenesisAdminValidator: AccountAdminValidator,
+	GenesisAdminFound:     AccountAdminFound,
+	GenesisAdminMetering:  AccountAdminMetering,
+}
+
+type GenesisBalance struct {
+	Symbol string `json:"symbol"`
+	Value  string `json:"value"`
+}
+
+// GenesisAccount is the account allocated at genesis, the nonce 0 means the default one of a new account
+type GenesisAccount struct {
+	Address  string           `json:"address"`
+	Nonce    uint64           `json:"nonce,omitempty"`
+	Balances []GenesisBalance `json:"balances"`
+}
+
+// GenesisAdmin replaces the built-in admin public key of the type with the base64 encoded ed25519 public key
+type GenesisAdmin struct {
+	Type   string `json:"type"`
+	PubKey string `json:"pubkey"`
+}
+
+// GenesisCurrency is only transferable if it is bound to a contract by ContractAddr
+type GenesisCurrency struct {
+	Symbol       string `json:"symbol"`
+	Decimal      int64  `json:"decimal"`
+	TotalSupply  string `json:"totalsupply"`
+	ContractAddr string `json:"contractaddr,omitempty"`
+}
+
+// GenesisContract is the pre-deployed contract, its codes are prefixed with the code prefix as the deployed ones
+type GenesisContract struct {
+	Addr      string `json:"addr"`
+	Name      string `json:"name"`
+	Owner     string `json:"owneraddr"`
+	Codes     []byte `json:"codes"`
+	CodesDesc string `json:"codesdesc,omitempty"`
+}
+
+// GenesisRole is bound to the addresses, its public key is base64 encoded
+type GenesisRole struct {
+	Name         string   `json:"name"`
+	RoleType     RoleType `json:"roletype"`
+	PubKey       string   `json:"pubkey"`
+	ContractAddr string   `json:"contractaddr,omitempty"`
+	Addresses    []string `json:"addresses,omitempty"`
+}
+
+// GenesisStoreKV is a key value of the store, the key is kept readable and the value is base64 encoded
+type GenesisStoreKV struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// GenesisStorePrefix groups the key values under the key prefix, the blank prefix holds the keys not under any known prefix
+type GenesisStorePrefix struct {
+	Name   string           `json:"name"`
+	Prefix string           `json:"prefix"`
+	KVs    []GenesisStoreKV `json:"kvs"`
+}
+
+type GenesisStore struct {
+	Name     string               `json:"name"`
+	Prefixes []GenesisStorePrefix `json:"prefixes"`
+}
+
+// GenesisSnapshot is the whole app state exported at the height, it is imported before the other parts of the genesis app state
+// are applied. The accounts allocated besides the snapshot can only hold ANKR or the currencies declared in the genesis app state.
+type GenesisSnapshot struct {
+	Height  int64          `json:"height"`
+	ChainID string         `json:"chainid"`
+	Stores  []GenesisStore `json:"stores"`
+}
+
+// GenesisAppState is the app_state of the genesis file, every entry is applied in order so all nodes get the same state
+type GenesisAppState struct {
+	Snapshot    *GenesisSnapshot  `json:"snapshot,omitempty"`
+	Accounts    []GenesisAccount  `json:"accounts,omitempty"`
+	Admins      []GenesisAdmin    `json:"admins,omitempty"`
+	Currencies  []GenesisCurrency `json:"currencies,omitempty"`
+	Contracts   []GenesisContract `json:"contracts,omitempty"`
+	Roles       []GenesisRole     `json:"roles,omitempty"`
+	MinGasPrice *GenesisBalance   `json:"mingasprice,omitempty"`
+}
+
+// ParseGenesisAppState parses and validates the app state, nil is returned for the blank one. The unknown fields are rejected,
+// so a misspelled field never leaves its part of the state silently unset.
+func ParseGenesisAppState(appStateBytes []byte) (*GenesisAppState, error) {
+	appStateBytes = bytes.TrimSpace(appStateBytes)
+	if len(appStateBytes) == 0 || bytes.Equal(appStateBytes, []byte("null")) {
+		return nil, nil
+	}
+
+	var appState GenesisAppState
+	decoder := json.NewDecoder(bytes.NewReader(appStateBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&appState); err != nil {
+		return nil, fmt.Errorf("invalid genesis app state: %s", err.Error())
+	}
+
+	if err := appState.ValidateBasic(); err != nil {
+		return nil, err
+	}
+
+	return &appState, nil
+}
+
+// GenesisAdminAccountType returns the account type of the admin type name
+func GenesisAdminAccountType(adminType string) (AccountType, bool) {
+	accType, ok := genesisAdminTypeMap[adminType]
+	return accType, ok
+}
+
+func isGenesisAddress(addr string) bool {
+	if len(addr) != KeyAddressLen {
+		return false
+	}
+
+	_, err := hex.DecodeString(addr)
+
+	return err == nil
+}
+
+// ParseGenesisValue parses the decimal integer value, which must be positive unless allowZero
+func ParseGenesisValue(value string, allowZero bool) (*big.Int, error) {
+	valueInt, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid value %s", value)
+	}
+
+	if valueInt.Sign() < 0 || (!allowZero && valueInt.Sign() == 0) {
+		return nil, fmt.Errorf("invalid value %s", value)
+	}
+
+	return valueInt, nil
+}
+
+func (gsn *GenesisSnapshot) ValidateBasic() error {
+	storeMap := make(map[string]bool)
+	for _, gStore := range gsn.Stores {
+		if gStore.Name == "" {
+			return errors.New("genesis snapshot, blank store name")
+		}
+		if storeMap[gStore.Name] {
+			return fmt.Errorf("genesis snapshot, duplicated store %s", gStore.Name)
+		}
+
+		keyMap := make(map[string]bool)
+		for _, gPrefix := range gStore.Prefixes {
+			for _, kv := range gPrefix.KVs {
+				if kv.Key == "" || !strings.HasPrefix(kv.Key, gPrefix.Prefix) {
+					return fmt.Errorf("genesis snapshot, invalid key %s under the prefix %s: store=%s", kv.Key, gPrefix.Prefix, gStore.Name)
+				}
+				if keyMap[kv.Key] {
+					return fmt.Errorf("genesis snapshot, duplicated key %s: store=%s", kv.Key, gStore.Name)
+				}
+
+				keyMap[kv.Key] = true
+			}
+		}
+
+		storeMap[gStore.Name] = true
+	}
+
+	return nil
+}
+
+func (gs *GenesisAppState) ValidateBasic() error {
+	if gs.Snapshot != nil {
+		if err := gs.Snapshot.ValidateBasic(); err != nil {
+			return err
+		}
+	}
+
+	contractMap := make(map[string]bool)
+	for _, gContract := range gs.Contracts {
+		if !isGenesisAddress(gContract.Addr) {
+			return fmt.Errorf("genesis contract, invalid address %s", gContract.Addr)
+		}
+		if contractMap[gContract.Addr] {
+			return fmt.Errorf("genesis contract, duplicated address %s", gContract.Addr)
+		}
+		if gContract.Name == "" {
+			return fmt.Errorf("genesis contract, blank name: address=%s", gContract.Addr)
+		}
+		if !isGenesisAddress(gContract.Owner) {
+			return fmt.Errorf("genesis contract, invalid owner address %s", gContract.Owner)
+		}
+		if len(gContract.Codes) <= CodePrefixLen {
+			return fmt.Errorf("genesis contract, invalid code size %d: address=%s", len(gContract.Codes), gContract.Addr)
+		}
+
+		contractMap[gContract.Addr] = true
+	}
+
+	currencyMap := map[string]bool{"ANKR": true}
+	for _, gCur := range gs.Currencies {
+		if gCur.Symbol == "" {
+			return errors.New("genesis currency, blank symbol")
+		}
+		if currencyMap[gCur.Symbol] {
+			return fmt.Errorf("genesis currency, duplicated symbol %s", gCur.Symbol)
+		}
+		if gCur.Decimal < 0 || gCur.Decimal > 18 {
+			return fmt.Errorf("genesis currency, invalid decimal %d: symbol=%s", gCur.Decimal, gCur.Symbol)
+		}
+		if _, err := ParseGenesisValue(gCur.TotalSupply, false); err != nil {
+			return fmt.Errorf("genesis currency, %s: symbol=%s", err.Error(), gCur.Symbol)
+		}
+		if gCur.ContractAddr != "" && !contractMap[gCur.ContractAddr] {
+			return fmt.Errorf("genesis currency, unknown contract %s: symbol=%s", gCur.ContractAddr, gCur.Symbol)
+		}
+
+		currencyMap[gCur.Symbol] = true
+	}
+
+	accountMap := make(map[string]bool)
+	for _, gAcc := range gs.Accounts {
+		if !isGenesisAddress(gAcc.Address) {
+			return fmt.Errorf("genesis account, invalid address %s", gAcc.Address)
+		}
+		if accountMap[gAcc.Address] {
+			return fmt.Errorf("genesis account, duplicated address %s", gAcc.Address)
+		}
+
+		symbolMap := make(map[string]bool)
+		for _, gBal := range gAcc.Balances {
+			if !currencyMap[gBal.Symbol] {
+				return fmt.Errorf("genesis account, unknown currency %s: address=%s", gBal.Symbol, gAcc.Address)
+			}
+			if symbolMap[gBal.Symbol] {
+				return fmt.Errorf("genesis account, duplicated currency %s: address=%s", gBal.Symbol, gAcc.Address)
+			}
+			if _, err := ParseGenesisValue(gBal.Value, true); err != nil {
+				return fmt.Errorf("genesis account, %s: address=%s, symbol=%s", err.Error(), gAcc.Address, gBal.Symbol)
+			}
+
+			symbolMap[gBal.Symbol] = true
+		}
+
+		accountMap[gAcc.Address] = true
+	}
+
+	adminMap := make(map[string]bool)
+	for _, gAdmin := range gs.Admins {
+		if _, ok := genesisAdminTypeMap[gAdmin.Type]; !ok {
+			return fmt.Errorf("genesis admin, unknown type %s", gAdmin.Type)
+		}
+		if adminMap[gAdmin.Type] {
+			return fmt.Errorf("genesis admin, duplicated type %s", gAdmin.Type)
+		}
+		pubKeyBytes, err := base64.StdEncoding.DecodeString(gAdmin.PubKey)
+		if err != nil || len(pubKeyBytes) != 32 {
+			return fmt.Errorf("genesis admin, invalid ed25519 public key %s: type=%s", gAdmin.PubKey, gAdmin.Type)
+		}
+
+		adminMap[gAdmin.Type] = true
+	}
+
+	roleMap := make(map[string]bool)
+	for _, gRole := range gs.Roles {
+		if gRole.Name == "" {
+			return errors.New("genesis role, blank name")
+		}
+		if roleMap[gRole.Name] {
+			return fmt.Errorf("genesis role, duplicated name %s", gRole.Name)
+		}
+		if gRole.RoleType != RoleGeneral && gRole.RoleType != RoleContract {
+			return fmt.Errorf("genesis role, invalid role type %d: name=%s", gRole.RoleType, gRole.Name)
+		}
+		if _, err := base64.StdEncoding.DecodeString(gRole.PubKey); err != nil {
+			return fmt.Errorf("genesis role, invalid public key %s: name=%s", gRole.PubKey, gRole.Name)
+		}
+		if gRole.RoleType == RoleContract && !isGenesisAddress(gRole.ContractAddr) {
+			return fmt.Errorf("genesis role, invalid contract address %s: name=%s", gRole.ContractAddr, gRole.Name)
+		}
+		for _, addr := range gRole.Addresses {
+			if !isGenesisAddress(addr) {
+				return fmt.Errorf("genesis role, invalid bound address %s: name=%s", addr, gRole.Name)
+			}
+		}
+
+		roleMap[gRole.Name] = true
+	}
+
+	if gs.MinGasPrice != nil {
+		if !currencyMap[gs.MinGasPrice.Symbol] {
+			return fmt.Errorf("genesis min gas price, unknown currency %s", gs.MinGasPrice.Symbol)
+		}
+		if _, err := ParseGenesisValue(gs.MinGasPrice.Value, false); err != nil {
+			return fmt.Errorf("genesis min gas price, %s", err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGenesisAppState(t *testing.T) {
+	appState, err := ParseGenesisAppState([]byte(`{
+		"accounts": [{"address": "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", "balances": [{"symbol": "ANKR", "value": "1000"}, {"symbol": "TEST", "value": "10"}]}],
+		"currencies": [{"symbol": "TEST", "decimal": 6, "totalsupply": "1000000"}],
+		"mingasprice": {"symbol": "ANKR", "value": "10000000000000"}
+	}`))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(appState.Accounts), 1)
+	assert.Equal(t, appState.Currencies[0].Decimal, int64(6))
+
+	appState, err = ParseGenesisAppState([]byte("null"))
+	assert.Equal(t, err, nil)
+	assert.Nil(t, appState)
+
+	_, err = ParseGenesisAppState([]byte(`{"acounts": []}`))
+	assert.NotEqual(t, err, nil)
+
+	_, err = ParseGenesisAppState([]byte(`{"accounts": [{"address": "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", "balances": [{"symbol": "TEST", "value": "10"}]}]}`))
+	assert.NotEqual(t, err, nil)
+
+	_, err = ParseGenesisAppState([]byte(`{"accounts": [{"address": "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", "balances": [{"symbol": "ANKR", "value": "-1"}]}]}`))
+	assert.NotEqual(t, err, nil)
+
+	_, err = ParseGenesisAppState([]byte(`{"admins": [{"type": "adminop", "pubkey": "invalid"}]}`))
+	assert.NotEqual(t, err, nil)
+}
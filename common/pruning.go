@@ -0,0 +1,55 @@
+package common
+
+import (
+	"fmt"
+)
+
+const (
+	// PruningNothing keeps all the versions of the stores
+	PruningNothing = "nothing"
+
+	// PruningEverything keeps only the latest KeepRecent versions
+	PruningEverything = "everything"
+
+	// PruningKeepEvery keeps the latest KeepRecent versions and every KeepEvery version before them
+	PruningKeepEvery = "keepevery"
+)
+
+type PruningOptions struct {
+	Strategy   string `json:"strategy"`
+	KeepRecent int64  `json:"keeprecent"`
+	KeepEvery  int64  `json:"keepevery"`
+}
+
+func NewPruningOptions(strategy string, keepRecent int64, keepEvery int64) (PruningOptions, error) {
+	opts := PruningOptions{strategy, keepRecent, keepEvery}
+
+	switch strategy {
+	case PruningNothing:
+	case PruningEverything:
+		if keepRecent <= 0 {
+			return opts, fmt.Errorf("pruning %s requires keeprecent > 0, got %d", strategy, keepRecent)
+		}
+	case PruningKeepEvery:
+		if keepRecent <= 0 || keepEvery <= 0 {
+			return opts, fmt.Errorf("pruning %s requires keeprecent > 0 and keepevery > 0, got %d and %d", strategy, keepRecent, keepEvery)
+		}
+	default:
+		return opts, fmt.Errorf("invalid pruning strategy: %s", strategy)
+	}
+
+	return opts, nil
+}
+
+// ShouldPrune tells if the version is pruned when the latest version is committed
+func (opts PruningOptions) ShouldPrune(version int64, latestVersion int64) bool {
+	if opts.Strategy == PruningNothing || version <= 0 || version > latestVersion-opts.KeepRecent {
+		return false
+	}
+
+	if opts.Strategy == PruningKeepEvery && version%opts.KeepEvery == 0 {
+		return false
+	}
+
+	return true
+}
@@ -13,6 +13,16 @@ type NonceQueryResp struct {
 	Nonce      uint64  `json:"nonce"`
 }
 
+type MultiSigAccountQueryReq struct {
+	Address string  `json:"address"`
+}
+
+type MultiSigAccountQueryResp struct {
+	Address   string   `json:"address"`
+	Threshold uint32   `json:"threshold"`
+	PubKeys   []string `json:"pubkeys"`
+}
+
 type BalanceQueryReq struct {
 	Address string  `json:"address"`
 	Symbol  string  `json:"symbol"`
@@ -22,6 +32,24 @@ type BalanceQueryResp struct {
 	Amount string      `json:"amount"`
 }
 
+type MinGasPriceQueryReq struct {
+	Symbol string  `json:"symbol"`
+}
+
+type MinGasPriceQueryResp struct {
+	Symbol string  `json:"symbol"`
+	Value  string  `json:"value"`
+}
+
+type FeeCurrencyQueryReq struct {
+	Symbol string  `json:"symbol"`
+}
+
+type FeeCurrencyQueryResp struct {
+	Symbol       string  `json:"symbol"`
+	ExchangeRate string  `json:"exchangerate"`
+}
+
 type CertKeyQueryReq struct {
 	DCName string  `json:"dcname"`
 }
@@ -104,4 +132,93 @@ type RoleQueryResp struct {
 	RType        RoleType `json:"roletype"`
 	PubKey       string   `json:"pubkey"`
 	ContractAddr string   `json:"contractaddr"`
-}
\ No newline at end of file
+}
+type BlockGasQueryReq struct {
+}
+
+type BlockGasQueryResp struct {
+	Height      int64 `json:"height"`
+	MaxBlockGas int64 `json:"maxblockgas"`
+	GasUsed     int64 `json:"gasused"`
+	TxCount     int64 `json:"txcount"`
+}
+
+type SimulateTxReq struct {
+	TxBytes []byte `json:"txbytes"`
+}
+
+type SimulateTxTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type SimulateTxResp struct {
+	Code           uint32          `json:"code"`
+	Log            string          `json:"log"`
+	GasWanted      int64           `json:"gaswanted"`
+	GasUsed        int64           `json:"gasused"`
+	Fee            Amount          `json:"fee"`
+	Tags           []SimulateTxTag `json:"tags"`
+	ContractResult string          `json:"contractresult"`
+}
+
+type SnapshotsQueryReq struct {
+}
+
+type SnapshotsQueryResp struct {
+	Snapshots []*SnapshotInfo `json:"snapshots"`
+}
+
+type SnapshotChunkQueryReq struct {
+	Height int64  `json:"height"`
+	Format uint32 `json:"format"`
+	Chunk  uint32 `json:"chunk"`
+}
+
+type SnapshotChunkQueryResp struct {
+	Chunk []byte `json:"chunk"`
+}
+
+type UpgradePlanQueryReq struct {
+}
+
+type UpgradePlanQueryResp struct {
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+	Info   string `json:"info"`
+}
+
+type CommitInfoQueryReq struct {
+	Height int64 `json:"height"`
+}
+
+type CommitInfoQueryResp struct {
+	Height  int64       `json:"height"`
+	AppHash []byte      `json:"apphash"`
+	Stores  []StoreHash `json:"stores"`
+}
+
+// RangeQueryReq lists the kv pairs of the store in the range. Start and End are appended to Prefix, End is excluded,
+// and Cursor is the NextKey returned by the last page.
+type RangeQueryReq struct {
+	Store   string `json:"store"`
+	Prefix  []byte `json:"prefix"`
+	Start   []byte `json:"start"`
+	End     []byte `json:"end"`
+	Reverse bool   `json:"reverse"`
+	Limit   int    `json:"limit"`
+	Cursor  []byte `json:"cursor"`
+}
+
+type KVPair struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// RangeQueryResp has the kv pairs of the page and the key the next page starts from, nil if it is the last page. Proof
+// is the iavl range proof of the page encoded by amino if the proof is required.
+type RangeQueryResp struct {
+	KVs     []KVPair `json:"kvs"`
+	NextKey []byte   `json:"nextkey"`
+	Proof   []byte   `json:"proof"`
+}
@@ -0,0 +1,27 @@
+package common
+
+// StoreHash is the root hash of the store committed at the version
+type StoreHash struct {
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+	Hash    []byte `json:"hash"`
+}
+
+// StoreHashDiff compares the root hash of the store at the mismatching height with the one at the last agreed height,
+// so the stores changed by the mismatching block can be found
+type StoreHashDiff struct {
+	Name       string `json:"name"`
+	AgreedHash []byte `json:"agreedhash"`
+	Hash       []byte `json:"hash"`
+	Changed    bool   `json:"changed"`
+}
+
+// AppHashMismatch records the height whose app hash doesn't match the one agreed by the chain, and AgreedHeight is the
+// last height the app store can be rolled back to
+type AppHashMismatch struct {
+	Height          int64           `json:"height"`
+	AgreedHeight    int64           `json:"agreedheight"`
+	AppHash         []byte          `json:"apphash"`
+	ExpectedAppHash []byte          `json:"expectedapphash"`
+	Stores          []StoreHashDiff `json:"stores"`
+}
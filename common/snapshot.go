@@ -0,0 +1,13 @@
+package common
+
+// SnapshotInfo describes the app state snapshot taken at the height, which is split into chunks. The metadata holds
+// the commit info and the chunk hashes, and Hash is the sha256 of the metadata. How much of the state restored is
+// checked against the app hash depends on the format, see the snapshot formats of the app store.
+type SnapshotInfo struct {
+	Height   int64  `json:"height"`
+	Format   uint32 `json:"format"`
+	Chunks   uint32 `json:"chunks"`
+	Hash     []byte `json:"hash"`
+	AppHash  []byte `json:"apphash"`
+	Metadata []byte `json:"metadata"`
+}
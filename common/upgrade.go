@@ -0,0 +1,8 @@
+package common
+
+// UpgradePlan is scheduled by the admin, the upgrade named is applied by BeginBlock at the height
+type UpgradePlan struct {
+	Name   string `json:"name"`
+	Height int64  `json:"height"`
+	Info   string `json:"info"`
+}
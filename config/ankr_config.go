@@ -1,6 +1,7 @@
 package config
 
 import (
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
 	tmcoreconfig "github.com/tendermint/tendermint/config"
 )
 
@@ -23,6 +24,17 @@ type AnkrConfig struct {
 	Instrumentation *tmcoreconfig.InstrumentationConfig `mapstructure:"instrumentation"`
 	HistoryDB       *HistoryDBConfig
 	AllowedPeers    string
+	PendingNonceWindow uint64
+	CongestionPendingTxs       int
+	CongestionGasPriceMultiple uint64
+	MaxBlockGas     int64
+	SnapshotInterval   int64
+	SnapshotKeepRecent int
+	AppHashMismatchRollback bool
+	Pruning            string
+	PruningKeepRecent  int64
+	PruningKeepEvery   int64
+	AppDBBackend       string
 }
 
 func (ac *AnkrConfig) SetRoot(root string) *AnkrConfig {
@@ -54,6 +66,58 @@ func DefaultLogLevel() string {
 	return "error"
 }
 
+func DefaultPendingNonceWindow() uint64 {
+	return 16
+}
+
+// DefaultCongestionPendingTxs returns 0, which means the mempool is never congested
+func DefaultCongestionPendingTxs() int {
+	return 0
+}
+
+func DefaultCongestionGasPriceMultiple() uint64 {
+	return 2
+}
+
+// DefaultMaxBlockGas returns -1, which means the block gas is unlimited
+func DefaultMaxBlockGas() int64 {
+	return -1
+}
+
+// DefaultSnapshotInterval returns 0, which means no snapshot is taken
+func DefaultSnapshotInterval() int64 {
+	return 0
+}
+
+func DefaultSnapshotKeepRecent() int {
+	return 2
+}
+
+// DefaultAppHashMismatchRollback returns false, which means the app store is kept as it is for diagnosing when the
+// app hash mismatches
+func DefaultAppHashMismatchRollback() bool {
+	return false
+}
+
+// DefaultPruning returns everything, which means only the latest PruningKeepRecent versions of the app state are kept
+func DefaultPruning() string {
+	return ankrcmm.PruningEverything
+}
+
+func DefaultPruningKeepRecent() int64 {
+	return 100
+}
+
+// DefaultPruningKeepEvery returns 0, it is only used by the pruning keepevery
+func DefaultPruningKeepEvery() int64 {
+	return 0
+}
+
+// DefaultAppDBBackend returns goleveldb, the backend of the app store db before it is configurable
+func DefaultAppDBBackend() string {
+	return "goleveldb"
+}
+
 func DefaultHistoryDBConfig() *HistoryDBConfig {
 	return &HistoryDBConfig{
 		Type:  "",
@@ -76,6 +140,17 @@ func DefaultAnkrConfig() *AnkrConfig {
 		tmcoreconfig.DefaultInstrumentationConfig(),
 		DefaultHistoryDBConfig(),
 		  "",
+		DefaultPendingNonceWindow(),
+		DefaultCongestionPendingTxs(),
+		DefaultCongestionGasPriceMultiple(),
+		DefaultMaxBlockGas(),
+		DefaultSnapshotInterval(),
+		DefaultSnapshotKeepRecent(),
+		DefaultAppHashMismatchRollback(),
+		DefaultPruning(),
+		DefaultPruningKeepRecent(),
+		DefaultPruningKeepEvery(),
+		DefaultAppDBBackend(),
 	}
 }
 
@@ -5,7 +5,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"strings"
+	"sync"
 
 	"github.com/Ankr-network/ankr-chain/account"
 	ankrcmm "github.com/Ankr-network/ankr-chain/common"
@@ -15,11 +15,10 @@ import (
 	"github.com/Ankr-network/ankr-chain/store/appstore"
 	"github.com/Ankr-network/ankr-chain/store/appstore/iavl"
 	"github.com/Ankr-network/ankr-chain/tx"
-	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
-	_ "github.com/Ankr-network/ankr-chain/tx/metering"
+	"github.com/Ankr-network/ankr-chain/tx/gasprice"
 	"github.com/Ankr-network/ankr-chain/tx/serializer"
-	_ "github.com/Ankr-network/ankr-chain/tx/token"
 	"github.com/Ankr-network/ankr-chain/tx/v0"
+	"github.com/Ankr-network/ankr-chain/upgrade"
 	val "github.com/Ankr-network/ankr-chain/tx/validator"
 	akver "github.com/Ankr-network/ankr-chain/version"
 	"github.com/tendermint/tendermint/abci/types"
@@ -38,14 +37,34 @@ type AnkrChainApplication struct {
 	latestAPPHash []byte
 	app          appstore.AppStore
 	txSerializer tx.TxSerializer
+	txSerializerProto *serializer.TxSerializerProto
 	contract     contract.Contract
 	pubsubServer *tmpubsub.Server
 	logger       log.Logger
 	minGasPrice  ankrcmm.Amount
+	noncePool    *tx.NoncePool
+	checkedTxs   *checkedTxPool
+	congestionPendingTxs       int
+	congestionGasPriceMultiple uint64
+	txMsgRegistry *tx.TxMsgRegistry
+	upgradeManager *upgrade.UpgradeManager
+	blockGasMeter *blockGasMeter
+	defaultMaxBlockGas int64
+	snapshotInterval   int64
+	snapshotKeepRecent int
+	snapshotting       int32
+	appHashMismatchRollback bool
+	haltHandler             func(err error)
+	checkState              appstore.AppStore
+	checkStateLocker        sync.Mutex
 }
 
 func NewAppStore(dbDir string, l log.Logger) appstore.AppStore {
-	appStore := iavl.NewIavlStoreApp(dbDir, l)
+	return NewAppStoreWithBackend(dbDir, iavl.DefaultDBBackend, l)
+}
+
+func NewAppStoreWithBackend(dbDir string, dbBackend string, l log.Logger) appstore.AppStore {
+	appStore := iavl.NewIavlStoreAppWithBackend(dbDir, dbBackend, l)
 	router.QueryRouterInstance().AddQueryHandler("store", appStore)
 
 	return  appStore
@@ -59,44 +78,168 @@ func NewMockAppStore() appstore.AppStore {
 }
 
 func NewAnkrChainApplication(dbDir string, appName string, l log.Logger) *AnkrChainApplication {
-	appStore := NewAppStore(dbDir, l.With("module", "AppStore"))
+	return NewAnkrChainApplicationWithRegistry(dbDir, appName, serializer.NewBuiltinTxMsgRegistry(), l)
+}
+
+// NewAnkrChainApplicationWithRegistry creates the application which only accepts the tx msgs in the registry,
+// the private msgs should be registered into it before calling this
+func NewAnkrChainApplicationWithRegistry(dbDir string, appName string, registry *tx.TxMsgRegistry, l log.Logger) *AnkrChainApplication {
+	return NewAnkrChainApplicationWithBackend(dbDir, iavl.DefaultDBBackend, appName, registry, upgrade.NewUpgradeManager(), l)
+}
+
+// NewAnkrChainApplicationWithBackend creates the application like NewAnkrChainApplicationWithRegistry, its app store
+// is on the db backend and it applies the upgrades registered into the upgrade manager
+func NewAnkrChainApplicationWithBackend(dbDir string, dbBackend string, appName string, registry *tx.TxMsgRegistry, upgradeManager *upgrade.UpgradeManager, l log.Logger) *AnkrChainApplication {
+	appStore := NewAppStoreWithBackend(dbDir, dbBackend, l.With("module", "AppStore"))
 
 	v0.MsgRouterInstance().SetLogger(l.With("module", "V0TxMsgRouter"))
 
-	chainID := appStore.ChainID()
+	account.AccountManagerInstance().Load(appStore)
+	upgradeManager.Load(appStore)
+
+	state := appStore.LastCommitState()
 
-	return &AnkrChainApplication{
-		ChainId:      ankrcmm.ChainID(chainID),
+	app := &AnkrChainApplication{
+		ChainId:      ankrcmm.ChainID(state.ChainID),
 		APPName:      appName,
+		latestHeight: state.Height,
 		app:          appStore,
-		txSerializer: serializer.NewTxSerializerCDC(),
+		txSerializer: serializer.NewTxSerializerCDCWithRegistry(registry),
+		txSerializerProto: serializer.NewTxSerializerProtoWithRegistry(registry),
 		contract:     contract.NewContract(appStore, l.With("module", "contract")),
 		logger:       l,
 		minGasPrice:  ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()},
+		noncePool:    tx.NewNoncePool(tx.DefaultPendingNonceWindow),
+		checkedTxs:   newCheckedTxPool(),
+		txMsgRegistry: registry,
+		upgradeManager: upgradeManager,
+		blockGasMeter: newBlockGasMeter(appStore.MaxBlockGas()),
+		defaultMaxBlockGas: -1,
 	}
+
+	router.QueryRouterInstance().AddQueryHandler("simulate", NewSimulateQueryHandler(app))
+	router.QueryRouterInstance().AddQueryHandler("blockgas", NewBlockGasQueryHandler(app))
+	router.QueryRouterInstance().AddQueryHandler("snapshots", NewSnapshotsQueryHandler(app))
+	router.QueryRouterInstance().AddQueryHandler("snapshotchunk", NewSnapshotChunkQueryHandler(app))
+	router.QueryRouterInstance().AddQueryHandler("commitinfo", NewCommitInfoQueryHandler(app))
+
+	app.resetCheckState()
+
+	return app
 }
 
 func NewMockAnkrChainApplication(appName string, l log.Logger) *AnkrChainApplication {
-	appStore := NewMockAppStore()
+	return NewMockAnkrChainApplicationWithRegistry(appName, serializer.NewBuiltinTxMsgRegistry(), l)
+}
+
+// NewMockAnkrChainApplicationWithRegistry creates the mock application which only accepts the tx msgs in the registry
+func NewMockAnkrChainApplicationWithRegistry(appName string, registry *tx.TxMsgRegistry, l log.Logger) *AnkrChainApplication {
+	appStore       := NewMockAppStore()
+	upgradeManager := upgrade.NewUpgradeManager()
 
 	account.AccountManagerInstance().Init(appStore)
+	upgradeManager.Load(appStore)
 
-	return &AnkrChainApplication{
+	app := &AnkrChainApplication{
 		APPName:      appName,
 		app:          appStore,
-		txSerializer: serializer.NewTxSerializerCDC(),
+		txSerializer: serializer.NewTxSerializerCDCWithRegistry(registry),
+		txSerializerProto: serializer.NewTxSerializerProtoWithRegistry(registry),
 		contract:     contract.NewContract(appStore, l.With("module", "contract")),
 		logger:       l,
 		minGasPrice:  ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()},
+		noncePool:    tx.NewNoncePool(tx.DefaultPendingNonceWindow),
+		checkedTxs:   newCheckedTxPool(),
+		txMsgRegistry: registry,
+		upgradeManager: upgradeManager,
+		blockGasMeter: newBlockGasMeter(appStore.MaxBlockGas()),
+		defaultMaxBlockGas: -1,
 	}
+
+	router.QueryRouterInstance().AddQueryHandler("simulate", NewSimulateQueryHandler(app))
+	router.QueryRouterInstance().AddQueryHandler("blockgas", NewBlockGasQueryHandler(app))
+	router.QueryRouterInstance().AddQueryHandler("snapshots", NewSnapshotsQueryHandler(app))
+	router.QueryRouterInstance().AddQueryHandler("snapshotchunk", NewSnapshotChunkQueryHandler(app))
+	router.QueryRouterInstance().AddQueryHandler("commitinfo", NewCommitInfoQueryHandler(app))
+
+	app.resetCheckState()
+
+	return app
 }
 
 func (app *AnkrChainApplication) SetLogger(l log.Logger) {
 	app.logger = l
 }
 
-func (app *AnkrChainApplication) MinGasPrice() ankrcmm.Amount {
-	return app.minGasPrice
+// MinGasPrice returns the min gas price of the symbol set by the admin, the ANKR one falls back to the default value
+// if it hasn't been set. Other currencies must be in the fee currency whitelist, and their min gas price is converted
+// from ANKR's by the exchange rate and the decimals if it hasn't been set. The false result means the symbol isn't accepted as the fee currency.
+func (app *AnkrChainApplication) MinGasPrice(symbol string) (ankrcmm.Amount, bool) {
+	return app.minGasPriceOf(app.app, symbol)
+}
+
+func (app *AnkrChainApplication) minGasPriceOf(store appstore.AppStore, symbol string) (ankrcmm.Amount, bool) {
+	ankrMinGasPrice := app.minGasPrice
+	minGasPrice, _, _, _, err := store.MinGasPrice(ankrMinGasPrice.Cur.Symbol, 0, false)
+	if err == nil && minGasPrice != nil {
+		ankrMinGasPrice = *minGasPrice
+	}
+
+	if symbol == ankrMinGasPrice.Cur.Symbol {
+		return ankrMinGasPrice, true
+	}
+
+	feeCurInfo, _, _, _, err := store.FeeCurrency(symbol, 0, false)
+	if err != nil || feeCurInfo == nil {
+		return ankrcmm.Amount{}, false
+	}
+
+	minGasPrice, _, _, _, err = store.MinGasPrice(symbol, 0, false)
+	if err == nil && minGasPrice != nil {
+		return *minGasPrice, true
+	}
+
+	rate, err := gasprice.ParseExchangeRate(feeCurInfo.ExchangeRate)
+	if err != nil {
+		return ankrcmm.Amount{}, false
+	}
+
+	curInfo, _, _, _, err := store.CurrencyInfo(symbol, 0, false)
+	if err != nil || curInfo == nil || gasprice.VerifyFeeCurrencyDecimal(curInfo.Decimal) != nil {
+		return ankrcmm.Amount{}, false
+	}
+
+	priceVal := gasprice.ConvertGasPrice(new(big.Int).SetBytes(ankrMinGasPrice.Value), rate, curInfo.Decimal)
+
+	return ankrcmm.Amount{ankrcmm.Currency{symbol, curInfo.Decimal}, priceVal.Bytes()}, true
+}
+
+func (app *AnkrChainApplication) SetPendingNonceWindow(window uint64) {
+	app.noncePool.SetWindow(window)
+}
+
+// SetCongestionOptions makes CheckTx accept only the new txs paying gasPriceMultiple times the min gas price once
+// pendingTxs txs are pending in the mempool, 0 pendingTxs means never congested
+func (app *AnkrChainApplication) SetCongestionOptions(pendingTxs int, gasPriceMultiple uint64) {
+	app.congestionPendingTxs       = pendingTxs
+	app.congestionGasPriceMultiple = gasPriceMultiple
+}
+
+// SetDefaultMaxBlockGas sets the max block gas used by InitChain if the genesis consensus params don't set it
+func (app *AnkrChainApplication) SetDefaultMaxBlockGas(maxBlockGas int64) {
+	app.defaultMaxBlockGas = maxBlockGas
+}
+
+func (app *AnkrChainApplication) NoncePool() *tx.NoncePool {
+	return app.noncePool
+}
+
+func (app *AnkrChainApplication) TxMsgRegistry() *tx.TxMsgRegistry {
+	return app.txMsgRegistry
+}
+
+func (app *AnkrChainApplication) UpgradeManager() *upgrade.UpgradeManager {
+	return app.upgradeManager
 }
 
 func (app *AnkrChainApplication) AppStore() appstore.AppStore {
@@ -149,13 +292,23 @@ func (app *AnkrChainApplication) ChainStateInfo() ankrcmm.ChainStateInfo {
 	return app
 }
 
+// Info reports the state persisted with the last commit, so tendermint replays the blocks after it on the handshake
+// if the app crashed before committing them
 func (app *AnkrChainApplication) Info(req types.RequestInfo) types.ResponseInfo {
+	state := app.app.LastCommitState()
+
+	if app.ChainId == "" && state.ChainID != "" {
+		app.ChainId = ankrcmm.ChainID(state.ChainID)
+	}
+
+	app.logger.Info("AnkrChainApplication Info", "height", state.Height, "appHash", fmt.Sprintf("%X", state.AppHash), "chainID", state.ChainID)
+
 	return types.ResponseInfo{
 		Data:             app.APPName,
 		Version:          version.ABCIVersion,
 		AppVersion:       akver.APPVersion,
-		LastBlockHeight:  app.app.Height(),
-		LastBlockAppHash: app.app.APPHash(),
+		LastBlockHeight:  state.Height,
+		LastBlockAppHash: state.AppHash,
 	}
 }
 
@@ -169,20 +322,43 @@ func (app *AnkrChainApplication) dispossTxWithCDCV1(tx []byte) (*tx.TxMsg, uint3
 		if app.logger != nil {
 			app.logger.Error("can't deserialize tx", "err", err)
 		}
+		if serializer.IsUnknownTxMsgErr(err) {
+			return nil, code.CodeTypeUnknownTxMsgType, fmt.Sprintf("unknown tx msg type: err=%s", err.Error())
+		}
 		return nil, code.CodeTypeDecodingError, fmt.Sprintf("can't deserialize tx: tx=%v, err=%s", tx, err.Error())
 	} else {
 		if txMsg.ChID != app.ChainId {
 			return nil, code.CodeTypeMismatchChainID, fmt.Sprintf("can't mistach the chain id, txChainID=%s, appChainID=%s", txMsg.ChID, app.ChainId)
 		}
 
-		if txMsg.Type() == txcmm.TxMsgTypeTransfer &&  txMsg.Version != "1.0.2" {
-			return nil, code.CodeTypeMismatchTxVersion, fmt.Sprintf("expected version 1.0.2 for new tx transfer, txVersion=%s", txMsg.Version)
+		if !app.upgradeManager.AcceptTxVersion(upgrade.TxFormatCDCV1, txMsg.Type(), txMsg.Version) {
+			return nil, code.CodeTypeMismatchTxVersion, fmt.Sprintf("can't mistach the tx version%v, txType=%s, txVersion=%s", app.upgradeManager.TxVersions(upgrade.TxFormatCDCV1, txMsg.Type()), txMsg.Type(), txMsg.Version)
 		}
 	}
 
 	return txMsg, code.CodeTypeOK, ""
 }
 
+func (app *AnkrChainApplication) dispossTxWithProto(tx []byte) (*tx.TxMsg, uint32, string) {
+	txMsg, err := app.txSerializerProto.DeserializeProto(tx)
+	if err != nil {
+		if app.logger != nil {
+			app.logger.Error("can't deserialize proto tx", "err", err)
+		}
+		return nil, code.CodeTypeDecodingError, fmt.Sprintf("can't deserialize proto tx: tx=%v, err=%s", tx, err.Error())
+	}
+
+	if txMsg.ChID != app.ChainId {
+		return nil, code.CodeTypeMismatchChainID, fmt.Sprintf("can't mistach the chain id, txChainID=%s, appChainID=%s", txMsg.ChID, app.ChainId)
+	}
+
+	if !app.upgradeManager.AcceptTxVersion(upgrade.TxFormatProto, txMsg.Type(), txMsg.Version) {
+		return nil, code.CodeTypeMismatchTxVersion, fmt.Sprintf("can't mistach the tx version%v, txType=%s, txVersion=%s", app.upgradeManager.TxVersions(upgrade.TxFormatProto, txMsg.Type()), txMsg.Type(), txMsg.Version)
+	}
+
+	return txMsg, code.CodeTypeOK, ""
+}
+
 func (app *AnkrChainApplication) dispossTxWithCDCV0(tx []byte) (*tx.TxMsgCDCV0, uint32, string) {
 	txMsg, err := app.txSerializer.DeserializeCDCV0(tx)
 	if err != nil {
@@ -195,35 +371,75 @@ func (app *AnkrChainApplication) dispossTxWithCDCV0(tx []byte) (*tx.TxMsgCDCV0,
 			return nil, code.CodeTypeMismatchChainID, fmt.Sprintf("can't mistach the chain id, txChainID=%s, appChainID=%s", txMsg.ChID, app.ChainId)
 		}
 
-		if txMsg.Version != "0.31.5" &&  txMsg.Version != "1.0" && txMsg.Version != "" {
-			return nil, code.CodeTypeMismatchTxVersion, fmt.Sprintf("can't mistach the tx version(0.31.5, 1.0 or 0), txVersion=%s", txMsg.Version)
+		if !app.upgradeManager.AcceptTxVersion(upgrade.TxFormatCDCV0, txMsg.Type(), txMsg.Version) {
+			return nil, code.CodeTypeMismatchTxVersion, fmt.Sprintf("can't mistach the tx version%v, txType=%s, txVersion=%s", app.upgradeManager.TxVersions(upgrade.TxFormatCDCV0, txMsg.Type()), txMsg.Type(), txMsg.Version)
 		}
 	}
 
 	return txMsg, code.CodeTypeOK, ""
 }
 
+// deliverTxWithBlockGas delivers the tx only if its gas limit fits in the gas left of the block
+func (app *AnkrChainApplication) deliverTxWithBlockGas(gasLimit []byte, deliverTx func() types.ResponseDeliverTx) types.ResponseDeliverTx {
+	codeVal, logStr := app.blockGasMeter.verify(gasLimit)
+	if codeVal != code.CodeTypeOK {
+		return types.ResponseDeliverTx{Code: codeVal, Log: logStr}
+	}
+
+	respDeliverTx := deliverTx()
+	app.blockGasMeter.consume(respDeliverTx.GasUsed)
+
+	return respDeliverTx
+}
+
 func (app *AnkrChainApplication) DeliverTx(tx []byte) types.ResponseDeliverTx {
+	// the tx leaves the mempool once it is in a block
+	app.checkedTxs.remove(checkedTxHash(tx))
+
+	if serializer.IsProtoTx(tx) {
+		txMsg, codeVal, logStr := app.dispossTxWithProto(tx)
+		if codeVal != code.CodeTypeOK {
+			return types.ResponseDeliverTx{Code: codeVal, Log: logStr}
+		}
+
+		return app.deliverTxWithBlockGas(txMsg.GasLimit, func() types.ResponseDeliverTx { return txMsg.DeliverTx(&protoTxContext{app}) })
+	}
+
 	txMsg, codeVal, logStr := app.dispossTxWithCDCV1(tx)
 	if codeVal == code.CodeTypeOK {
-		return txMsg.DeliverTx(app)
+		return app.deliverTxWithBlockGas(txMsg.GasLimit, func() types.ResponseDeliverTx { return txMsg.DeliverTx(app) })
+	} else if codeVal == code.CodeTypeUnknownTxMsgType {
+		return types.ResponseDeliverTx{Code: codeVal, Log: logStr}
 	} else {
 		app.logger.Info("AnkrChainApplication DeliverTx new tx cdcv1 serialize error, switch to cdcv0 tx", "logStr", logStr)
 		txMsgCDCV0, codeVal, logStr := app.dispossTxWithCDCV0(tx)
 		if codeVal == code.CodeTypeOK {
-			return txMsgCDCV0.DeliverTx(app)
+			return app.deliverTxWithBlockGas(txMsgCDCV0.GasLimit, func() types.ResponseDeliverTx { return txMsgCDCV0.DeliverTx(app) })
 		}
 
 		app.logger.Info("AnkrChainApplication DeliverTx new tx cdcv0 serialize error, switch to V0 tx", "logStr", logStr)
 	}
 
-	return v0.MsgRouterInstance().DeliverTx(tx, app.AppStore())
+	// the V0 txs carry no gas limit and use no gas, so they are exempt from the block gas and only counted
+	respDeliverTx := v0.MsgRouterInstance().DeliverTx(tx, app.AppStore())
+	app.blockGasMeter.consume(respDeliverTx.GasUsed)
+
+	return respDeliverTx
 }
 
 func (app *AnkrChainApplication) CheckTx(tx []byte) types.ResponseCheckTx {
+	if serializer.IsProtoTx(tx) {
+		txMsg, codeVal, logStr := app.dispossTxWithProto(tx)
+		if codeVal != code.CodeTypeOK {
+			return types.ResponseCheckTx{Code: codeVal, Log: logStr}
+		}
+
+		return app.checkTxMsg(tx, txMsg, &checkTxContext{&protoTxContext{app}, app})
+	}
+
 	txMsg, codeVal, logStr := app.dispossTxWithCDCV1(tx)
 	if codeVal == code.CodeTypeOK {
-		return txMsg.CheckTx(app)
+		return app.checkTxMsg(tx, txMsg, &checkTxContext{app, app})
 	}
 
 	return types.ResponseCheckTx{ Code: codeVal, Log: logStr}
@@ -252,10 +468,19 @@ func (app *AnkrChainApplication) Commit() types.ResponseCommit {
 	}
 
 	if  appHashH != nil && app.latestAPPHash != nil && !bytes.Equal(appHashH, app.latestAPPHash) {
-		panic(fmt.Errorf("AnkrChainApplication Commit appHash check error, height=%d. Got %X, expected %X", app.latestHeight, appHashH, app.latestAPPHash))
+		app.appHashMismatch(appHashH)
 	}
 
-	return app.app.Commit()
+	// the mempool rechecks the remaining txs after commit, which rebuilds the pending nonces and fees
+	app.noncePool.Reset()
+	app.checkedTxs.commit()
+
+	respCommit := app.app.Commit()
+	app.resetCheckState()
+
+	app.snapshot(app.app.Height())
+
+	return respCommit
 }
 
 func (app *AnkrChainApplication) Query(reqQuery types.RequestQuery) (resQuery types.ResponseQuery) {
@@ -281,6 +506,24 @@ func (app *AnkrChainApplication) Query(reqQuery types.RequestQuery) (resQuery ty
 
 // Save the validators in the merkle tree
 func (app *AnkrChainApplication) InitChain(req types.RequestInitChain) types.ResponseInitChain {
+	// a chain started from an invalid genesis app state would diverge from the one expected, so it is refused
+	appState, err := ankrcmm.ParseGenesisAppState(req.AppStateBytes)
+	if err != nil {
+		panic(fmt.Errorf("AnkrChainApplication InitChain, can't parse the genesis app state: %s", err.Error()))
+	}
+
+	// the exported state is imported first, so the genesis validators and the other parts of the app state are applied on it
+	if appState != nil && appState.Snapshot != nil {
+		if err := app.app.ImportSnapshot(appState.Snapshot); err != nil {
+			panic(fmt.Errorf("AnkrChainApplication InitChain, can't import the genesis snapshot: %s", err.Error()))
+		}
+
+		account.AccountManagerInstance().Load(app.app)
+		app.upgradeManager.Load(app.app)
+
+		app.logger.Info("AnkrChainApplication InitChain, genesis snapshot imported", "height", appState.Snapshot.Height, "chainID", appState.Snapshot.ChainID)
+	}
+
 	var initTotalPowers int64
 	for _, v := range req.Validators {
 		initTotalPowers += v.Power
@@ -296,41 +539,68 @@ func (app *AnkrChainApplication) InitChain(req types.RequestInitChain) types.Res
 		}
 	}
 
-	sbytes := string(req.AppStateBytes)
-	if len(sbytes) > 0 {
-		sbytes = sbytes[1 : len(sbytes)-1]
-		addressAndBalance := strings.Split(sbytes, ":")
-		if len(addressAndBalance) != 2 {
-			app.logger.Error("Error read app states", "appstate", sbytes)
-			return types.ResponseInitChain{}
-		}
-		addressS, balanceS := addressAndBalance[0], addressAndBalance[1]
-		fmt.Println(addressS)
-		fmt.Println(balanceS)
-		//app.app.state.db.Set(prefixBalanceKey([]byte(addressS)), []byte(balanceS+":1"))
-		//app.app.state.Size += 1
-		//app.app.Commit()
-	}
-
 	app.ChainId = ankrcmm.ChainID(req.ChainId)
 
     app.app.SetChainID(req.ChainId)
 
+	if err := initGenesisAppState(app.app, appState); err != nil {
+		panic(fmt.Errorf("AnkrChainApplication InitChain, can't load the genesis app state: %s", err.Error()))
+	}
+
 	account.AccountManagerInstance().Init(app.app)
 
-	return types.ResponseInitChain{}
+	maxBlockGas := app.defaultMaxBlockGas
+	if req.ConsensusParams != nil && req.ConsensusParams.Block != nil && req.ConsensusParams.Block.MaxGas > 0 {
+		maxBlockGas = req.ConsensusParams.Block.MaxGas
+	}
+	app.app.SetMaxBlockGas(maxBlockGas)
+	app.blockGasMeter.setMaxGas(maxBlockGas)
+
+	if req.ConsensusParams == nil || req.ConsensusParams.Block == nil {
+		return types.ResponseInitChain{}
+	}
+
+	// report the max block gas, so tendermint reaps no more txs than a block can hold from mempool
+	return types.ResponseInitChain{
+		ConsensusParams: &types.ConsensusParams{
+			Block: &types.BlockParams{
+				MaxBytes: req.ConsensusParams.Block.MaxBytes,
+				MaxGas:   maxBlockGasOfConsensusParams(maxBlockGas),
+			},
+		},
+	}
 }
 
 // Track the block hash and header information
 func (app *AnkrChainApplication) BeginBlock(req types.RequestBeginBlock) types.ResponseBeginBlock {
 	app.logger.Info(fmt.Sprintf("AnkrChainApplication BeginBlock appHash=%X, height=%d", req.Header.AppHash, req.Header.Height))
 
+	app.beginBlockUpgrade(req.Header.Height)
+
 	val.ValidatorManagerInstance().ValBeginBlock(req, app.app)
 	app.latestHeight = req.Header.Height
 	app.latestAPPHash = req.Header.AppHash
+	app.blockGasMeter.reset(req.Header.Height)
 	return types.ResponseBeginBlock{}
 }
 
+// beginBlockUpgrade applies the upgrade scheduled at the height. The node halts if the binary doesn't support the
+// upgrade or its migration fails, so it never goes on with the app state diverging from the upgraded nodes.
+func (app *AnkrChainApplication) beginBlockUpgrade(height int64) {
+	plan, err := app.upgradeManager.BeginBlock(height, app.app)
+	if err != nil {
+		app.logger.Error("AnkrChainApplication BeginBlock upgrade failed, halt", "height", height, "err", err)
+		panic(err)
+	}
+
+	if plan != nil {
+		account.AccountManagerInstance().Load(app.app)
+		app.blockGasMeter.setMaxGas(app.app.MaxBlockGas())
+
+		app.logger.Info("AnkrChainApplication BeginBlock upgrade applied", "name", plan.Name, "height", height)
+	}
+}
+
 // Update the validator set
 func (app *AnkrChainApplication) EndBlock(req types.RequestEndBlock) types.ResponseEndBlock {
 	app.latestHeight = req.Height
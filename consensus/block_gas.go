@@ -0,0 +1,107 @@
+package ankrchain
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+// blockGasMeter accumulates the gas used by the txs delivered in the current block. A tx is rejected if its gas
+// limit can't fit in the gas left, so the block never exceeds the max block gas whatever the txs really use.
+// The V0 txs have no gas limit and use no gas, they are only counted.
+type blockGasMeter struct {
+	maxGas      int64
+	height      int64
+	gasUsed     int64
+	txCount     int64
+	meterLocker sync.RWMutex
+}
+
+func newBlockGasMeter(maxGas int64) *blockGasMeter {
+	return &blockGasMeter{maxGas: maxGas}
+}
+
+func (bm *blockGasMeter) setMaxGas(maxGas int64) {
+	bm.meterLocker.Lock()
+	defer bm.meterLocker.Unlock()
+
+	bm.maxGas = maxGas
+}
+
+func (bm *blockGasMeter) reset(height int64) {
+	bm.meterLocker.Lock()
+	defer bm.meterLocker.Unlock()
+
+	bm.height  = height
+	bm.gasUsed = 0
+	bm.txCount = 0
+}
+
+func (bm *blockGasMeter) verify(gasLimit []byte) (uint32, string) {
+	bm.meterLocker.RLock()
+	defer bm.meterLocker.RUnlock()
+
+	if bm.maxGas <= 0 {
+		return code.CodeTypeOK, ""
+	}
+
+	gasLeft  := new(big.Int).SetInt64(bm.maxGas - bm.gasUsed)
+	gasLimitInt := new(big.Int).SetBytes(gasLimit)
+	if gasLimitInt.Cmp(gasLeft) == 1 {
+		return code.CodeTypeBlockGasExhausted, fmt.Sprintf("block gas exhausted: height=%d, maxBlockGas=%d, blockGasUsed=%d, txGasLimit=%s", bm.height, bm.maxGas, bm.gasUsed, gasLimitInt.String())
+	}
+
+	return code.CodeTypeOK, ""
+}
+
+func (bm *blockGasMeter) consume(gasUsed int64) {
+	bm.meterLocker.Lock()
+	defer bm.meterLocker.Unlock()
+
+	bm.gasUsed += gasUsed
+	bm.txCount++
+}
+
+func (bm *blockGasMeter) info() *ankrcmm.BlockGasQueryResp {
+	bm.meterLocker.RLock()
+	defer bm.meterLocker.RUnlock()
+
+	return &ankrcmm.BlockGasQueryResp{bm.height, bm.maxGas, bm.gasUsed, bm.txCount}
+}
+
+// maxBlockGasOfConsensusParams returns -1 for the unlimited max block gas, since tendermint reaps no tx from mempool
+// if it is 0
+func maxBlockGasOfConsensusParams(maxBlockGas int64) int64 {
+	if maxBlockGas <= 0 {
+		return -1
+	}
+
+	return maxBlockGas
+}
+
+// BlockGasQueryHandler returns the gas used by the txs delivered in the current block, or the last block
+// after it is committed
+type BlockGasQueryHandler struct {
+	app *AnkrChainApplication
+	cdc *amino.Codec
+}
+
+func NewBlockGasQueryHandler(app *AnkrChainApplication) *BlockGasQueryHandler {
+	return &BlockGasQueryHandler{app, amino.NewCodec()}
+}
+
+func (bqh *BlockGasQueryHandler) Query(reqQuery types.RequestQuery) (resQuery types.ResponseQuery) {
+	respBytes, _ := bqh.cdc.MarshalJSON(bqh.app.blockGasMeter.info())
+	resQDataBytes, _ := bqh.cdc.MarshalJSON(&ankrcmm.QueryResp{respBytes, nil})
+
+	resQuery.Code   = code.CodeTypeOK
+	resQuery.Value  = resQDataBytes
+	resQuery.Height = bqh.app.app.Height()
+
+	return
+}
@@ -0,0 +1,148 @@
+package ankrchain
+
+import (
+	"math/big"
+	"sync"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+)
+
+// checkedTxPool keeps the fees of the txs accepted by CheckTx and not delivered yet. The mempool caches the txs it has
+// seen and only checks them again for the recheck after each commit, so a tx found in the pool is being rechecked. The
+// txs not rechecked after a commit have left the mempool, and they are dropped on the next commit.
+type checkedTxPool struct {
+	txMap      map[string]*big.Int
+	prevTxMap  map[string]*big.Int
+	poolLocker sync.Mutex
+}
+
+func newCheckedTxPool() *checkedTxPool {
+	return &checkedTxPool{txMap: make(map[string]*big.Int), prevTxMap: make(map[string]*big.Int)}
+}
+
+func (cp *checkedTxPool) fee(txHash string) (*big.Int, bool) {
+	cp.poolLocker.Lock()
+	defer cp.poolLocker.Unlock()
+
+	if fee, ok := cp.txMap[txHash]; ok {
+		return fee, true
+	}
+
+	fee, ok := cp.prevTxMap[txHash]
+
+	return fee, ok
+}
+
+func (cp *checkedTxPool) accept(txHash string, fee *big.Int) {
+	cp.poolLocker.Lock()
+	defer cp.poolLocker.Unlock()
+
+	delete(cp.prevTxMap, txHash)
+	cp.txMap[txHash] = fee
+}
+
+func (cp *checkedTxPool) remove(txHash string) {
+	cp.poolLocker.Lock()
+	defer cp.poolLocker.Unlock()
+
+	delete(cp.txMap, txHash)
+	delete(cp.prevTxMap, txHash)
+}
+
+// size returns the number of the txs accepted since the last commit. The mempool rechecks its remaining txs before it
+// checks any new one after a commit, so they are the txs pending in the mempool when a new tx is checked.
+func (cp *checkedTxPool) size() int {
+	cp.poolLocker.Lock()
+	defer cp.poolLocker.Unlock()
+
+	return len(cp.txMap)
+}
+
+func (cp *checkedTxPool) commit() {
+	cp.poolLocker.Lock()
+	defer cp.poolLocker.Unlock()
+
+	cp.prevTxMap = cp.txMap
+	cp.txMap     = make(map[string]*big.Int)
+}
+
+// checkTxContext checks the tx on the check state, which is a branch of the last committed state reset on each commit,
+// so the mempool never reads or writes the state of the block being executed
+type checkTxContext struct {
+	tx.ContextTx
+	app *AnkrChainApplication
+}
+
+func (cc *checkTxContext) AppStore() appstore.AppStore {
+	return cc.app.checkState
+}
+
+func (cc *checkTxContext) MinGasPrice(symbol string) (ankrcmm.Amount, bool) {
+	return cc.app.minGasPriceOf(cc.app.checkState, symbol)
+}
+
+// congestedTxContext raises the min gas price by the multiple, so only the new txs paying more are accepted while the
+// mempool is congested. The txs accepted before are rechecked against the min gas price as usual.
+type congestedTxContext struct {
+	tx.ContextTx
+	gasPriceMultiple uint64
+}
+
+func (cc *congestedTxContext) MinGasPrice(symbol string) (ankrcmm.Amount, bool) {
+	minGasPrice, ok := cc.ContextTx.MinGasPrice(symbol)
+	if !ok {
+		return minGasPrice, false
+	}
+
+	priceVal := new(big.Int).Mul(new(big.Int).SetBytes(minGasPrice.Value), new(big.Int).SetUint64(cc.gasPriceMultiple))
+
+	return ankrcmm.Amount{minGasPrice.Cur, priceVal.Bytes()}, true
+}
+
+// congested reports whether the pending txs reach the congestion threshold, 0 means never congested
+func (app *AnkrChainApplication) congested() bool {
+	return app.congestionPendingTxs > 0 && app.checkedTxs.size() >= app.congestionPendingTxs
+}
+
+// resetCheckState drops the check state and branches a new one from the last committed state
+func (app *AnkrChainApplication) resetCheckState() {
+	app.checkStateLocker.Lock()
+	defer app.checkStateLocker.Unlock()
+
+	app.checkState = app.app.CommittedBranch()
+}
+
+func checkedTxHash(txBytes []byte) string {
+	return string(tmhash.Sum(txBytes))
+}
+
+// checkTxMsg rechecks the tx accepted before, otherwise fully checks it against the min gas price raised while the mempool
+// is congested
+func (app *AnkrChainApplication) checkTxMsg(txBytes []byte, txMsg *tx.TxMsg, context tx.ContextTx) types.ResponseCheckTx {
+	app.checkStateLocker.Lock()
+	defer app.checkStateLocker.Unlock()
+
+	txHash := checkedTxHash(txBytes)
+
+	checkType := tx.CheckTxTypeNew
+	checkedFee, ok := app.checkedTxs.fee(txHash)
+	if ok {
+		checkType = tx.CheckTxTypeRecheck
+	} else if app.congested() {
+		context = &congestedTxContext{context, app.congestionGasPriceMultiple}
+	}
+
+	respCheckTx, fee := txMsg.CheckTxWithType(context, checkType, checkedFee)
+	if respCheckTx.Code == code.CodeTypeOK {
+		app.checkedTxs.accept(txHash, fee)
+	} else if ok {
+		app.checkedTxs.remove(txHash)
+	}
+
+	return respCheckTx
+}
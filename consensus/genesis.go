@@ -0,0 +1,120 @@
+package ankrchain
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/Ankr-network/ankr-chain/account"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+)
+
+// initGenesisAppState loads the genesis app state into the store. The ANKR allocated to the accounts is taken from the
+// genesis account which holds the whole supply, and the supply of the other currencies not allocated goes to it.
+func initGenesisAppState(store appstore.AppStore, appState *ankrcmm.GenesisAppState) error {
+	if appState == nil {
+		return nil
+	}
+
+	for _, gAdmin := range appState.Admins {
+		accType, _ := ankrcmm.GenesisAdminAccountType(gAdmin.Type)
+		if err := account.AccountManagerInstance().SetAdminOpAccount(store, accType, gAdmin.PubKey); err != nil {
+			return err
+		}
+	}
+
+	for _, gContract := range appState.Contracts {
+		cInfo, _, _, _, err := store.LoadContract(gContract.Addr, 0, false)
+		if err != nil {
+			return fmt.Errorf("genesis contract, load contract err: address=%s, err=%s", gContract.Addr, err.Error())
+		}
+		if cInfo != nil {
+			return fmt.Errorf("genesis contract, the contract address has been taken up: address=%s", gContract.Addr)
+		}
+
+		cInfo = &ankrcmm.ContractInfo{gContract.Addr, gContract.Name, gContract.Owner, gContract.Codes, gContract.CodesDesc, ankrcmm.ContractNormal, make(map[string]string)}
+		if err := store.SaveContract(gContract.Addr, cInfo); err != nil {
+			return fmt.Errorf("genesis contract, save contract err: address=%s, err=%s", gContract.Addr, err.Error())
+		}
+	}
+
+	genesisAddr := account.AccountManagerInstance().GenesisAccountAddress()
+	ankrLeft, _, _, _, err := store.Balance(genesisAddr, "ANKR", 0, false)
+	if err != nil {
+		return fmt.Errorf("genesis account, get ANKR balance err: address=%s, err=%s", genesisAddr, err.Error())
+	}
+
+	curDecimalMap := map[string]int64{"ANKR": 18}
+	curLeftMap    := map[string]*big.Int{"ANKR": new(big.Int).Set(ankrLeft)}
+	for _, gCur := range appState.Currencies {
+		if curInfo, _, _, _, _ := store.CurrencyInfo(gCur.Symbol, 0, false); curInfo != nil {
+			return fmt.Errorf("genesis currency, the currency has existed: symbol=%s", gCur.Symbol)
+		}
+
+		if err := store.CreateCurrency(gCur.Symbol, &ankrcmm.CurrencyInfo{gCur.Symbol, gCur.Decimal, gCur.TotalSupply}); err != nil {
+			return err
+		}
+
+		if gCur.ContractAddr != "" {
+			if err := store.BuildCurrencyCAddrMap(gCur.Symbol, gCur.ContractAddr); err != nil {
+				return fmt.Errorf("genesis currency, bind contract err: symbol=%s, err=%s", gCur.Symbol, err.Error())
+			}
+		}
+
+		curDecimalMap[gCur.Symbol] = gCur.Decimal
+		curLeftMap[gCur.Symbol], _ = ankrcmm.ParseGenesisValue(gCur.TotalSupply, false)
+	}
+
+	for _, gAcc := range appState.Accounts {
+		if gAcc.Address == genesisAddr {
+			return fmt.Errorf("genesis account, the genesis account can't be allocated: address=%s", gAcc.Address)
+		}
+
+		store.AddAccount(gAcc.Address, ankrcmm.AccountGeneral)
+
+		for _, gBal := range gAcc.Balances {
+			value, _ := ankrcmm.ParseGenesisValue(gBal.Value, true)
+			curLeft := curLeftMap[gBal.Symbol]
+			if value.Cmp(curLeft) == 1 {
+				return fmt.Errorf("genesis account, the allocated %s exceeds the supply: address=%s, value=%s, left=%s", gBal.Symbol, gAcc.Address, gBal.Value, curLeft.String())
+			}
+			curLeft.Sub(curLeft, value)
+
+			store.SetBalance(gAcc.Address, ankrcmm.Amount{ankrcmm.Currency{gBal.Symbol, curDecimalMap[gBal.Symbol]}, value.Bytes()})
+		}
+
+		if gAcc.Nonce > 0 {
+			if err := store.SetNonce(gAcc.Address, gAcc.Nonce); err != nil {
+				return err
+			}
+		}
+	}
+
+	store.SetBalance(genesisAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, curLeftMap["ANKR"].Bytes()})
+	for _, gCur := range appState.Currencies {
+		if curLeft := curLeftMap[gCur.Symbol]; curLeft.Sign() > 0 {
+			store.SetBalance(genesisAddr, ankrcmm.Amount{ankrcmm.Currency{gCur.Symbol, gCur.Decimal}, curLeft.Bytes()})
+		}
+	}
+
+	for _, gRole := range appState.Roles {
+		if rInfo, _, _, _, _ := store.LoadRole(gRole.Name, 0, false); rInfo != nil {
+			return fmt.Errorf("genesis role, the role has existed: name=%s", gRole.Name)
+		}
+
+		pubKeyBytes, _ := base64.StdEncoding.DecodeString(gRole.PubKey)
+		store.AddRole(gRole.RoleType, gRole.Name, string(pubKeyBytes), gRole.ContractAddr)
+
+		for _, addr := range gRole.Addresses {
+			store.AddBoundRole(addr, gRole.Name)
+		}
+	}
+
+	if appState.MinGasPrice != nil {
+		value, _ := ankrcmm.ParseGenesisValue(appState.MinGasPrice.Value, false)
+		store.SetMinGasPrice(&ankrcmm.Amount{ankrcmm.Currency{appState.MinGasPrice.Symbol, curDecimalMap[appState.MinGasPrice.Symbol]}, value.Bytes()})
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package ankrchain
+
+import (
+	"github.com/Ankr-network/ankr-chain/tx"
+)
+
+// protoTxContext executes the proto tx, whose signatures are verified against the proto sign bytes
+type protoTxContext struct {
+	*AnkrChainApplication
+}
+
+func (pc *protoTxContext) TxSerializer() tx.TxSerializer {
+	return pc.txSerializerProto
+}
@@ -0,0 +1,96 @@
+package ankrchain
+
+import (
+	"fmt"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+// SetAppHashMismatchRollback makes the app roll its store back to the last agreed height before halting on the app
+// hash mismatch, so the blocks after it are replayed when the node is restarted
+func (app *AnkrChainApplication) SetAppHashMismatchRollback(rollback bool) {
+	app.appHashMismatchRollback = rollback
+}
+
+// SetHaltHandler sets the handler stopping the node when the app can't go on, the app panics if no handler is set or
+// the handler returns
+func (app *AnkrChainApplication) SetHaltHandler(haltHandler func(err error)) {
+	app.haltHandler = haltHandler
+}
+
+// halt never returns, so the caller can't go on reporting the state it failed on
+func (app *AnkrChainApplication) halt(err error) {
+	if app.haltHandler != nil {
+		app.haltHandler(err)
+	}
+
+	panic(err)
+}
+
+// appHashMismatch records the height whose app hash doesn't match the one in the header with the diff of the store
+// root hashes, rolls the app store back if required and halts the app
+func (app *AnkrChainApplication) appHashMismatch(appHash []byte) {
+	mismatch := app.app.RecordAppHashMismatch(app.latestHeight-1, app.latestAPPHash)
+
+	app.logger.Error("AnkrChainApplication Commit appHash mismatch", "height", mismatch.Height, "got", fmt.Sprintf("%X", appHash), "expected", fmt.Sprintf("%X", app.latestAPPHash))
+	for _, storeDiff := range mismatch.Stores {
+		app.logger.Error("AnkrChainApplication Commit appHash mismatch store", "store", storeDiff.Name, "changed", storeDiff.Changed,
+			"agreedHeight", mismatch.AgreedHeight, "agreedHash", fmt.Sprintf("%X", storeDiff.AgreedHash), "hash", fmt.Sprintf("%X", storeDiff.Hash))
+	}
+
+	if app.appHashMismatchRollback {
+		if err := app.app.LoadVersion(mismatch.AgreedHeight); err != nil {
+			app.logger.Error("AnkrChainApplication Commit rollback failed", "height", mismatch.AgreedHeight, "err", err)
+		} else {
+			app.resetCheckState()
+			app.logger.Info("AnkrChainApplication Commit rolled back", "height", mismatch.AgreedHeight, "appHash", fmt.Sprintf("%X", app.app.APPHash()))
+		}
+	}
+
+	app.halt(fmt.Errorf("AnkrChainApplication Commit appHash check error, height=%d. Got %X, expected %X", app.latestHeight, appHash, app.latestAPPHash))
+}
+
+// CommitInfoQueryHandler returns the root hashes of the stores committed at the height, which can be compared with the
+// ones of the other nodes to find the store diverged
+type CommitInfoQueryHandler struct {
+	app *AnkrChainApplication
+	cdc *amino.Codec
+}
+
+func NewCommitInfoQueryHandler(app *AnkrChainApplication) *CommitInfoQueryHandler {
+	return &CommitInfoQueryHandler{app, amino.NewCodec()}
+}
+
+func (ciqh *CommitInfoQueryHandler) Query(reqQuery types.RequestQuery) (resQuery types.ResponseQuery) {
+	var cmmInfoReq ankrcmm.CommitInfoQueryReq
+	err := ciqh.cdc.UnmarshalJSON(reqQuery.Data, &cmmInfoReq)
+	if err != nil {
+		resQuery.Code = code.CodeTypeQueryInvalidQueryReqData
+		resQuery.Log  = fmt.Sprintf("invalid commitinfo query req data, err=%s", err.Error())
+		return
+	}
+
+	height := cmmInfoReq.Height
+	if height <= 0 {
+		height = ciqh.app.app.Height()
+	}
+
+	appHash, storeHashes, err := ciqh.app.app.StoreHashes(height)
+	if err != nil {
+		resQuery.Code = code.CodeTypeCommitInfoNotFound
+		resQuery.Log  = err.Error()
+		return
+	}
+
+	respBytes, _ := ciqh.cdc.MarshalJSON(&ankrcmm.CommitInfoQueryResp{height, appHash, storeHashes})
+	resQDataBytes, _ := ciqh.cdc.MarshalJSON(&ankrcmm.QueryResp{respBytes, nil})
+
+	resQuery.Code   = code.CodeTypeOK
+	resQuery.Value  = resQDataBytes
+	resQuery.Height = ciqh.app.app.Height()
+
+	return
+}
@@ -0,0 +1,116 @@
+package ankrchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+// simulateContext runs the tx on a branch of the last committed state, so neither the working state nor the event
+// subscribers are affected by the simulation. The branch reads the committed versions of the trees, nothing is loaded
+// for each simulation.
+type simulateContext struct {
+	*AnkrChainApplication
+	store        appstore.AppStore
+	txSerializer tx.TxSerializer
+}
+
+func (sc *simulateContext) AppStore() appstore.AppStore {
+	return sc.store
+}
+
+func (sc *simulateContext) TxSerializer() tx.TxSerializer {
+	return sc.txSerializer
+}
+
+func (sc *simulateContext) Publisher() tx.Publisher {
+	return &nopPublisher{}
+}
+
+// NoncePool returns nil, so the signed tx is simulated with the strict nonce as in DeliverTx
+func (sc *simulateContext) NoncePool() *tx.NoncePool {
+	return nil
+}
+
+type nopPublisher struct {
+}
+
+func (np *nopPublisher) Publish(ctx context.Context, msg interface{}) error {
+	return nil
+}
+
+func (np *nopPublisher) PublishWithTags(ctx context.Context, msg interface{}, tags map[string]string) error {
+	return nil
+}
+
+type SimulateQueryHandler struct {
+	app *AnkrChainApplication
+	cdc *amino.Codec
+}
+
+func NewSimulateQueryHandler(app *AnkrChainApplication) *SimulateQueryHandler {
+	return &SimulateQueryHandler{app, amino.NewCodec()}
+}
+
+func (sqh *SimulateQueryHandler) Query(reqQuery types.RequestQuery) (resQuery types.ResponseQuery) {
+	var simReq ankrcmm.SimulateTxReq
+	err := sqh.cdc.UnmarshalJSON(reqQuery.Data, &simReq)
+	if err != nil {
+		resQuery.Code = code.CodeTypeQueryInvalidQueryReqData
+		resQuery.Log  = fmt.Sprintf("invalid simulate query req data, err=%s", err.Error())
+		return
+	}
+
+	var txSerializer tx.TxSerializer = sqh.app.txSerializer
+	dispossTx := sqh.app.dispossTxWithCDCV1
+	if serializer.IsProtoTx(simReq.TxBytes) {
+		txSerializer = sqh.app.txSerializerProto
+		dispossTx = sqh.app.dispossTxWithProto
+	}
+
+	txMsg, codeVal, logStr := dispossTx(simReq.TxBytes)
+	if codeVal != code.CodeTypeOK {
+		resQuery.Code = codeVal
+		resQuery.Log  = logStr
+		return
+	}
+
+	simContext := &simulateContext{sqh.app, sqh.app.app.CommittedBranch(), txSerializer}
+	respSim := txMsg.Simulate(simContext)
+
+	simResp := &ankrcmm.SimulateTxResp{
+		Code:      respSim.Code,
+		Log:       respSim.Log,
+		GasWanted: respSim.GasWanted,
+		GasUsed:   respSim.GasUsed,
+	}
+
+	fee := new(big.Int).Mul(big.NewInt(respSim.GasUsed), new(big.Int).SetBytes(txMsg.GasPrice.Value))
+	simResp.Fee = ankrcmm.Amount{Cur: txMsg.GasPrice.Cur, Value: fee.Bytes()}
+
+	for _, tag := range respSim.Tags {
+		simResp.Tags = append(simResp.Tags, ankrcmm.SimulateTxTag{Key: string(tag.Key), Value: string(tag.Value)})
+	}
+
+	if respSim.Code == code.CodeTypeOK && txMsg.Type() == txcmm.TxMsgTypeContractInvokeMsg {
+		simResp.ContractResult = respSim.Log
+	}
+
+	respBytes, _ := sqh.cdc.MarshalJSON(simResp)
+	resQDataBytes, _ := sqh.cdc.MarshalJSON(&ankrcmm.QueryResp{respBytes, nil})
+
+	resQuery.Code   = code.CodeTypeOK
+	resQuery.Value  = resQDataBytes
+	resQuery.Height = sqh.app.app.Height()
+
+	return
+}
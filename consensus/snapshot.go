@@ -0,0 +1,187 @@
+package ankrchain
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Ankr-network/ankr-chain/account"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/store/appstore/iavl"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+// The results follow the state sync methods of the later ABCI, so the snapshot methods can be bound to them directly
+// once the tendermint supporting state sync is used. Until then the snapshots are served through the queries.
+type SnapshotOfferResult int
+
+const (
+	SnapshotOfferAccept SnapshotOfferResult = iota
+	SnapshotOfferAbort
+	SnapshotOfferReject
+	SnapshotOfferRejectFormat
+)
+
+type SnapshotApplyResult int
+
+const (
+	SnapshotApplyAccept SnapshotApplyResult = iota
+	SnapshotApplyAbort
+	SnapshotApplyRetry
+	SnapshotApplyRejectSnapshot
+)
+
+// SetSnapshotOptions makes the app snapshot its state every interval blocks and keep the latest keepRecent snapshots,
+// the interval 0 disables the snapshots
+func (app *AnkrChainApplication) SetSnapshotOptions(interval int64, keepRecent int) {
+	app.snapshotInterval   = interval
+	app.snapshotKeepRecent = keepRecent
+}
+
+// SetPruningOptions sets which versions of the app store are deleted on each commit
+func (app *AnkrChainApplication) SetPruningOptions(opts ankrcmm.PruningOptions) {
+	app.app.SetPruningOptions(opts)
+}
+
+// snapshot runs in background, and the height is skipped if the last snapshot isn't finished yet
+func (app *AnkrChainApplication) snapshot(height int64) {
+	if app.snapshotInterval <= 0 || height <= 0 || height%app.snapshotInterval != 0 {
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&app.snapshotting, 0, 1) {
+		app.logger.Info("AnkrChainApplication snapshot skipped, the last one is in progress", "height", height)
+		return
+	}
+
+	go func() {
+		defer atomic.StoreInt32(&app.snapshotting, 0)
+
+		info, err := app.app.CreateSnapshot(height)
+		if err != nil {
+			app.logger.Error("AnkrChainApplication create snapshot failed", "height", height, "err", err)
+			return
+		}
+
+		app.app.PruneSnapshots(app.snapshotKeepRecent)
+
+		app.logger.Info("AnkrChainApplication snapshot created", "height", info.Height, "chunks", info.Chunks, "hash", fmt.Sprintf("%X", info.Hash))
+	}()
+}
+
+func (app *AnkrChainApplication) ListSnapshots() []*ankrcmm.SnapshotInfo {
+	return app.app.Snapshots()
+}
+
+func (app *AnkrChainApplication) LoadSnapshotChunk(height int64, format uint32, chunk uint32) []byte {
+	return app.app.SnapshotChunk(height, format, chunk)
+}
+
+// OfferSnapshot starts restoring the snapshot, whose app hash must be the one trusted at the snapshot height
+func (app *AnkrChainApplication) OfferSnapshot(info *ankrcmm.SnapshotInfo, appHash []byte) SnapshotOfferResult {
+	if info == nil {
+		return SnapshotOfferReject
+	}
+
+	if !bytes.Equal(info.AppHash, appHash) {
+		app.logger.Error("AnkrChainApplication OfferSnapshot, app hash mismatch", "height", info.Height, "expected", fmt.Sprintf("%X", appHash), "got", fmt.Sprintf("%X", info.AppHash))
+		return SnapshotOfferReject
+	}
+
+	err := app.app.OfferSnapshot(info)
+	if err == iavl.ErrSnapshotFormatUnknown {
+		return SnapshotOfferRejectFormat
+	}
+	if err != nil {
+		app.logger.Error("AnkrChainApplication OfferSnapshot rejected", "height", info.Height, "err", err)
+		return SnapshotOfferReject
+	}
+
+	return SnapshotOfferAccept
+}
+
+// ApplySnapshotChunk applies the chunks in order, the app is ready to go on from the snapshot height after the last one
+func (app *AnkrChainApplication) ApplySnapshotChunk(index uint32, chunk []byte) SnapshotApplyResult {
+	done, err := app.app.ApplySnapshotChunk(index, chunk)
+	if err == iavl.ErrSnapshotChunkInvalid {
+		return SnapshotApplyRetry
+	}
+	if err != nil {
+		app.logger.Error("AnkrChainApplication ApplySnapshotChunk failed", "index", index, "err", err)
+		return SnapshotApplyRejectSnapshot
+	}
+
+	if done {
+		account.AccountManagerInstance().Load(app.app)
+		app.upgradeManager.Load(app.app)
+
+		app.ChainId       = ankrcmm.ChainID(app.app.ChainID())
+		app.latestHeight  = app.app.Height()
+		app.latestAPPHash = app.app.APPHash()
+		app.blockGasMeter.setMaxGas(app.app.MaxBlockGas())
+		app.resetCheckState()
+
+		app.logger.Info("AnkrChainApplication snapshot restored", "height", app.latestHeight, "appHash", fmt.Sprintf("%X", app.latestAPPHash))
+	}
+
+	return SnapshotApplyAccept
+}
+
+// SnapshotsQueryHandler lists the snapshots the node can serve
+type SnapshotsQueryHandler struct {
+	app *AnkrChainApplication
+	cdc *amino.Codec
+}
+
+func NewSnapshotsQueryHandler(app *AnkrChainApplication) *SnapshotsQueryHandler {
+	return &SnapshotsQueryHandler{app, amino.NewCodec()}
+}
+
+func (sqh *SnapshotsQueryHandler) Query(reqQuery types.RequestQuery) (resQuery types.ResponseQuery) {
+	respBytes, _ := sqh.cdc.MarshalJSON(&ankrcmm.SnapshotsQueryResp{sqh.app.ListSnapshots()})
+	resQDataBytes, _ := sqh.cdc.MarshalJSON(&ankrcmm.QueryResp{respBytes, nil})
+
+	resQuery.Code   = code.CodeTypeOK
+	resQuery.Value  = resQDataBytes
+	resQuery.Height = sqh.app.app.Height()
+
+	return
+}
+
+// SnapshotChunkQueryHandler returns the chunk of the snapshot, which is checked against the snapshot metadata when applied
+type SnapshotChunkQueryHandler struct {
+	app *AnkrChainApplication
+	cdc *amino.Codec
+}
+
+func NewSnapshotChunkQueryHandler(app *AnkrChainApplication) *SnapshotChunkQueryHandler {
+	return &SnapshotChunkQueryHandler{app, amino.NewCodec()}
+}
+
+func (scqh *SnapshotChunkQueryHandler) Query(reqQuery types.RequestQuery) (resQuery types.ResponseQuery) {
+	var chunkReq ankrcmm.SnapshotChunkQueryReq
+	err := scqh.cdc.UnmarshalJSON(reqQuery.Data, &chunkReq)
+	if err != nil {
+		resQuery.Code = code.CodeTypeQueryInvalidQueryReqData
+		resQuery.Log  = fmt.Sprintf("invalid snapshotchunk query req data, err=%s", err.Error())
+		return
+	}
+
+	chunk := scqh.app.LoadSnapshotChunk(chunkReq.Height, chunkReq.Format, chunkReq.Chunk)
+	if chunk == nil {
+		resQuery.Code = code.CodeTypeSnapshotChunkNotFound
+		resQuery.Log  = fmt.Sprintf("snapshot chunk not found: height=%d, format=%d, chunk=%d", chunkReq.Height, chunkReq.Format, chunkReq.Chunk)
+		return
+	}
+
+	respBytes, _ := scqh.cdc.MarshalJSON(&ankrcmm.SnapshotChunkQueryResp{chunk})
+	resQDataBytes, _ := scqh.cdc.MarshalJSON(&ankrcmm.QueryResp{respBytes, nil})
+
+	resQuery.Code   = code.CodeTypeOK
+	resQuery.Value  = resQDataBytes
+	resQuery.Height = scqh.app.app.Height()
+
+	return
+}
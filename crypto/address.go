@@ -1,6 +1,8 @@
 package crypto
 
 import (
+	"sort"
+
 	"github.com/Ankr-network/ankr-chain/common"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/tmhash"
@@ -52,4 +54,21 @@ func CreateCertAddress(pubBS64 string, dcName string, addrType CertAddrType) str
 	bytesSum :=  hasher.Sum(nil)
 
 	return  crypto.Address(bytesSum).String()
-}
\ No newline at end of file
+}
+
+// CreateMultiSigAddress derives the multisig account address from its member public keys(base64) and threshold,
+// the keys order doesn't affect the result.
+func CreateMultiSigAddress(pubKeys []string, threshold uint32) string {
+	sortedKeys := make([]string, len(pubKeys))
+	copy(sortedKeys, pubKeys)
+	sort.Strings(sortedKeys)
+
+	hasher := tmhash.NewTruncated()
+	for _, pubKey := range sortedKeys {
+		hasher.Write([]byte(pubKey))
+	}
+	hasher.Write(common.UInt64ToBytes(uint64(threshold)))
+	bytesSum :=  hasher.Sum(nil)
+
+	return  crypto.Address(bytesSum).String()
+}
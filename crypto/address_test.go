@@ -17,3 +17,13 @@ func TestCreateContractAddress(t *testing.T) {
 	t.Logf("contAddr3=%v, contAddr4=%v", contAddr1, contAddr2)
 	assert.Equal(t, contAddr3, contAddr4)
 }
+
+func TestCreateMultiSigAddress(t *testing.T) {
+	msAddr1 := CreateMultiSigAddress([]string{"sxhP4F6OLZKNPQ2lG13WcHzitNX9++h56cppBDhwMlI=", "trwr09Y8sqIdg2H7vhJFsf4aBowBzqkMOjzAGu2ZF6E="}, 2)
+	msAddr2 := CreateMultiSigAddress([]string{"trwr09Y8sqIdg2H7vhJFsf4aBowBzqkMOjzAGu2ZF6E=", "sxhP4F6OLZKNPQ2lG13WcHzitNX9++h56cppBDhwMlI="}, 2)
+	assert.Equal(t, msAddr1, msAddr2)
+	assert.Equal(t, len(msAddr1), 46)
+
+	msAddr3 := CreateMultiSigAddress([]string{"sxhP4F6OLZKNPQ2lG13WcHzitNX9++h56cppBDhwMlI=", "trwr09Y8sqIdg2H7vhJFsf4aBowBzqkMOjzAGu2ZF6E="}, 1)
+	assert.NotEqual(t, msAddr1, msAddr3)
+}
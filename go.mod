@@ -8,9 +8,11 @@ require (
 	github.com/agiledragon/gomonkey v0.0.0-20191023170119-08026f2977b6
 	github.com/btcsuite/btcd v0.20.1-beta // indirect
 	github.com/cheggaaa/pb v0.0.0-20191021095926-c966da046635
+	github.com/dgraph-io/badger v1.6.2
 	github.com/fortytw2/leaktest v1.3.0 // indirect
 	github.com/go-sql-driver/mysql v1.4.1
 	github.com/golang/mock v1.1.1
+	github.com/golang/protobuf v1.3.1
 	github.com/gorilla/mux v1.7.3
 	github.com/jmhodges/levigo v1.0.0 // indirect
 	github.com/mattn/go-colorable v0.1.4 // indirect
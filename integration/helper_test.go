@@ -0,0 +1,41 @@
+package integration
+
+import (
+	"encoding/base64"
+	"math/big"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/Ankr-network/ankr-chain/tx/token"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+// newTestKey returns a new ed25519 key and its base64 public key
+func newTestKey() (*crypto.SecretKeyEd25519, string) {
+	privKey := ed25519.GenPrivKey()
+	pubKey  := privKey.PubKey().(ed25519.PubKeyEd25519)
+
+	return crypto.NewSecretKeyEd25519(base64.StdEncoding.EncodeToString(privKey[:])), base64.StdEncoding.EncodeToString(pubKey[:])
+}
+
+// newTestTransferTx returns the unsigned cdcv1 transfer tx of the ANKR amount
+func newTestTransferTx(fromAddr string, toAddr string, nonce uint64, amount uint64) *tx.TxMsg {
+	tfMsg := &token.TransferMsg{FromAddr: fromAddr, ToAddr: toAddr, Amounts: []ankrcmm.Amount{{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(amount).Bytes()}}}
+
+	return &tx.TxMsg{ChID: "ankr-chain", Nonce: nonce, GasLimit: new(big.Int).SetUint64(1000000).Bytes(), GasPrice: ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()}, Version: "1.0.2", ImplTxMsg: tfMsg}
+}
+
+// newTestTxMsgRegistry returns the built-in registry whose msgs of the types charge the base gas given
+func newTestTxMsgRegistry(baseGas map[string]uint64) *tx.TxMsgRegistry {
+	registry := tx.NewTxMsgRegistry()
+	for _, info := range serializer.NewBuiltinTxMsgRegistry().Infos() {
+		info.BaseGas = baseGas[info.Type]
+		if err := registry.Register(*info); err != nil {
+			panic(err)
+		}
+	}
+
+	return registry
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/Ankr-network/ankr-chain/account"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/gasprice"
+	"github.com/Ankr-network/ankr-chain/tx/key"
+	"github.com/Ankr-network/ankr-chain/tx/metering"
+	upgradetx "github.com/Ankr-network/ankr-chain/tx/upgrade"
+	"github.com/Ankr-network/ankr-chain/tx/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestAdminMsgPermitKey(t *testing.T) {
+	// signKeyOf returns the base64 public key in the encoded form of the signature key the msgs are permitted with
+	signKeyOf := func(pubKeyBase64 string) []byte {
+		pubKeyBytes, err := base64.StdEncoding.DecodeString(pubKeyBase64)
+		assert.Equal(t, nil, err)
+		var pubKey ed25519.PubKeyEd25519
+		copy(pubKey[:], pubKeyBytes)
+		return pubKey.Bytes()
+	}
+
+	adminMsgs := []struct {
+		msg     tx.ImplTxMsg
+		accType ankrcmm.AccountType
+		keyName string
+	}{
+		{&gasprice.MinGasPriceMsg{}, ankrcmm.AccountAdminOP, ankrcmm.ADMIN_OP_PUBKEY_NAME},
+		{&gasprice.FeeCurrencyMsg{}, ankrcmm.AccountAdminOP, ankrcmm.ADMIN_OP_PUBKEY_NAME},
+		{&upgradetx.UpgradeMsg{}, ankrcmm.AccountAdminOP, ankrcmm.ADMIN_OP_PUBKEY_NAME},
+		{&key.KeyMsg{}, ankrcmm.AccountAdminOP, ankrcmm.ADMIN_OP_PUBKEY_NAME},
+		{&validator.ValidatorMsg{}, ankrcmm.AccountAdminValidator, ankrcmm.ADMIN_OP_VAL_PUBKEY_NAME},
+		{&metering.SetCertMsg{}, ankrcmm.AccountAdminMetering, ankrcmm.ADMIN_OP_METERING_PUBKEY_NAME},
+		{&metering.RemoveCertMsg{}, ankrcmm.AccountAdminMetering, ankrcmm.ADMIN_OP_METERING_PUBKEY_NAME},
+	}
+
+	for _, adminMsg := range adminMsgs {
+		appStore := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger()).AppStore()
+		genesisAdmin := account.AccountManagerInstance().AdminOpAccount(adminMsg.accType)
+		_, newAdmin  := newTestKey()
+
+		assert.Equal(t, true, adminMsg.msg.PermitKey(appStore, signKeyOf(genesisAdmin)), adminMsg.msg.Type())
+		assert.Equal(t, false, adminMsg.msg.PermitKey(appStore, signKeyOf(newAdmin)), adminMsg.msg.Type())
+
+		// the admin key set in the store overrides the genesis one
+		appStore.Set([]byte(adminMsg.keyName), []byte(newAdmin))
+		assert.Equal(t, false, adminMsg.msg.PermitKey(appStore, signKeyOf(genesisAdmin)), adminMsg.msg.Type())
+		assert.Equal(t, true, adminMsg.msg.PermitKey(appStore, signKeyOf(newAdmin)), adminMsg.msg.Type())
+
+		// the raw public key isn't the signature key
+		rawKey, _ := base64.StdEncoding.DecodeString(newAdmin)
+		assert.Equal(t, false, adminMsg.msg.PermitKey(appStore, rawKey), adminMsg.msg.Type())
+	}
+}
@@ -0,0 +1,179 @@
+package integration
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/batch"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/Ankr-network/ankr-chain/tx/token"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestBatchMsgDeliverTx(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	key, _ := newTestKey()
+	keyAddr, _ := key.Address()
+	fromAddr   := fmt.Sprintf("%X", keyAddr)
+	toAddr1    := "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB"
+	toKey, _   := newTestKey()
+	toKeyAddr, _ := toKey.Address()
+	toAddr2    := fmt.Sprintf("%X", toKeyAddr)
+
+	balanceOf := func(addr string) *big.Int {
+		bal, _, _, _, _ := txContext.AppStore().Balance(addr, "ANKR", 0, false)
+		if bal == nil {
+			return big.NewInt(0)
+		}
+		return bal
+	}
+	nonceOf := func(addr string) uint64 {
+		nonce, _, _, _, _ := txContext.AppStore().Nonce(addr, 0, false)
+		return nonce
+	}
+	transferOf := func(toAddr string, amount uint64) *token.TransferMsg {
+		return newTestTransferTx(fromAddr, toAddr, 0, amount).ImplTxMsg.(*token.TransferMsg)
+	}
+	deliverTx := func(txMsg *tx.TxMsg) types.ResponseDeliverTx {
+		txBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), key)
+		assert.Equal(t, nil, err)
+		return txContext.DeliverTx(txBytes)
+	}
+	batchTxOf := func(nonce uint64, msgs ...tx.ImplTxMsg) *tx.TxMsg {
+		txMsg := newTestTransferTx(fromAddr, toAddr1, nonce, 0)
+		txMsg.ImplTxMsg = &batch.BatchMsg{FromAddr: fromAddr, Msgs: msgs}
+		return txMsg
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+
+	// the gas of a single transfer is the unit the batch's gas is compared with
+	respDeliverTx := deliverTx(newTestTransferTx(fromAddr, toAddr1, 1, 100))
+	assert.Equal(t, code.CodeTypeOK, respDeliverTx.Code)
+	transferGas := respDeliverTx.GasUsed
+	assert.Equal(t, uint64(2), nonceOf(fromAddr))
+	txContext.Commit()
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+
+	// one failed msg rolls back all the msgs before it, and the nonce isn't consumed
+	balFrom := balanceOf(fromAddr)
+	overTransfer := transferOf(toAddr2, 0)
+	overTransfer.Amounts[0].Value = new(big.Int).Mul(balFrom, big.NewInt(2)).Bytes()
+	respDeliverTx = deliverTx(batchTxOf(2, transferOf(toAddr1, 100), transferOf(toAddr2, 100), overTransfer))
+	assert.Equal(t, code.CodeTypeCallContractErr, respDeliverTx.Code)
+	assert.Equal(t, big.NewInt(100), balanceOf(toAddr1))
+	assert.Equal(t, big.NewInt(0), balanceOf(toAddr2))
+	assert.Equal(t, balFrom, balanceOf(fromAddr))
+	assert.Equal(t, uint64(2), nonceOf(fromAddr))
+	txContext.Commit()
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 3}})
+
+	// the batch consumes one nonce however many msgs it has, the gas is the sum of the msgs', and the tags are merged
+	respDeliverTx = deliverTx(batchTxOf(2, transferOf(toAddr1, 100), transferOf(toAddr2, 200)))
+	assert.Equal(t, code.CodeTypeOK, respDeliverTx.Code)
+	assert.Equal(t, big.NewInt(200), balanceOf(toAddr1))
+	assert.Equal(t, big.NewInt(200), balanceOf(toAddr2))
+	assert.Equal(t, uint64(3), nonceOf(fromAddr))
+	assert.Equal(t, 2*transferGas, respDeliverTx.GasUsed)
+
+	tagCount := make(map[string]int)
+	for _, tag := range respDeliverTx.Tags {
+		tagCount[string(tag.Key)+"="+string(tag.Value)]++
+	}
+	assert.Equal(t, 1, tagCount["app.fromaddress="+fromAddr])
+	assert.Equal(t, 1, tagCount["app.toaddress="+toAddr1])
+	assert.Equal(t, 1, tagCount["app.toaddress="+toAddr2])
+	assert.Equal(t, 1, tagCount["app."+fromAddr+"=1"])
+	assert.Equal(t, 1, tagCount["app.type="+txcmm.TxMsgTypeBatchMsg])
+	assert.Equal(t, 0, tagCount["app.type="+txcmm.TxMsgTypeTransfer])
+
+
+	// the nonce consumed by the batch can't be used again
+	respDeliverTx = deliverTx(batchTxOf(2, transferOf(toAddr1, 100)))
+	assert.Equal(t, code.CodeTypeBadNonce, respDeliverTx.Code)
+	txContext.Commit()
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 4}})
+
+	// the msgs of the other signers are refused
+	respDeliverTx = deliverTx(batchTxOf(3, transferOf(toAddr1, 100), &token.TransferMsg{FromAddr: toAddr2, ToAddr: toAddr1, Amounts: transferOf(toAddr1, 100).Amounts}))
+	assert.Equal(t, code.CodeTypeBatchMsgInvalid, respDeliverTx.Code)
+	assert.Equal(t, uint64(3), nonceOf(fromAddr))
+	txContext.Commit()
+}
+
+func TestBatchMsgInnerMsgsByRegistry(t *testing.T) {
+	key, _ := newTestKey()
+	keyAddr, _ := key.Address()
+	fromAddr   := fmt.Sprintf("%X", keyAddr)
+	toAddr     := "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB"
+	blockedAddr := "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67"
+
+	// the transfers in the batch are charged and permitted by the registry as if they were sent alone
+	registry := tx.NewTxMsgRegistry()
+	for _, info := range newTestTxMsgRegistry(map[string]uint64{txcmm.TxMsgTypeTransfer: 1000, txcmm.TxMsgTypeBatchMsg: 500}).Infos() {
+		if info.Type == txcmm.TxMsgTypeTransfer {
+			info.Permission = func(store appstore.AppStore, msg tx.ImplTxMsg, pubKey []byte) bool {
+				return msg.(*token.TransferMsg).ToAddr != blockedAddr
+			}
+		}
+		assert.Equal(t, nil, registry.Register(*info))
+	}
+
+	txContext := ankrchain.NewMockAnkrChainApplicationWithRegistry("testApp", registry, log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	txSerializer := serializer.NewTxSerializerCDCWithRegistry(registry)
+	signTx := func(txMsg *tx.TxMsg) []byte {
+		txBytes, err := txMsg.SignAndMarshal(txSerializer, key)
+		assert.Equal(t, nil, err)
+		return txBytes
+	}
+	transferOf := func(toAddr string, amount uint64) *token.TransferMsg {
+		return newTestTransferTx(fromAddr, toAddr, 0, amount).ImplTxMsg.(*token.TransferMsg)
+	}
+	batchTxOf := func(nonce uint64, msgs ...tx.ImplTxMsg) *tx.TxMsg {
+		txMsg := newTestTransferTx(fromAddr, toAddr, nonce, 0)
+		txMsg.ImplTxMsg = &batch.BatchMsg{FromAddr: fromAddr, Msgs: msgs}
+		return txMsg
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+
+	respDeliverTx := txContext.DeliverTx(signTx(newTestTransferTx(fromAddr, toAddr, 1, 100)))
+	assert.Equal(t, code.CodeTypeOK, respDeliverTx.Code)
+	transferGas := respDeliverTx.GasUsed
+	txContext.Commit()
+
+	// every inner transfer pays its base gas besides the batch's
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	respDeliverTx = txContext.DeliverTx(signTx(batchTxOf(2, transferOf(toAddr, 100), transferOf(toAddr, 200))))
+	assert.Equal(t, code.CodeTypeOK, respDeliverTx.Code)
+	assert.Equal(t, 2*transferGas+500, respDeliverTx.GasUsed)
+	txContext.Commit()
+
+	// the inner transfer the permission refuses can't be hidden in the batch
+	respCheckTx := txContext.CheckTx(signTx(newTestTransferTx(fromAddr, blockedAddr, 3, 100)))
+	assert.Equal(t, code.CodeTypeNotPermitPubKey, respCheckTx.Code)
+
+	respCheckTx = txContext.CheckTx(signTx(batchTxOf(3, transferOf(toAddr, 100), transferOf(blockedAddr, 100))))
+	assert.Equal(t, code.CodeTypeNotPermitPubKey, respCheckTx.Code)
+
+	respCheckTx = txContext.CheckTx(signTx(batchTxOf(3, transferOf(toAddr, 100))))
+	assert.Equal(t, code.CodeTypeOK, respCheckTx.Code)
+}
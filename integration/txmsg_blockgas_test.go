@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestBlockGasLegacyTx(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain", ConsensusParams: &types.ConsensusParams{Block: &types.BlockParams{MaxBytes: 22020096, MaxGas: 5000}}})
+	cdc := amino.NewCodec()
+
+	blockGasOf := func() *ankrcmm.BlockGasQueryResp {
+		respQuery := txContext.Query(types.RequestQuery{Path: "/blockgas"})
+		assert.Equal(t, code.CodeTypeOK, respQuery.Code)
+		var queryResp ankrcmm.QueryResp
+		assert.Equal(t, nil, cdc.UnmarshalJSON(respQuery.Value, &queryResp))
+		blockGas := new(ankrcmm.BlockGasQueryResp)
+		assert.Equal(t, nil, cdc.UnmarshalJSON(queryResp.RespData, blockGas))
+		return blockGas
+	}
+
+	key, _ := newTestKey()
+	keyAddr, _ := key.Address()
+	fromAddr   := fmt.Sprintf("%X", keyAddr)
+
+	// the tx of the version 1.0 isn't accepted as cdcv1, so it is delivered as the cdcv0 tx
+	cdcv0TxOf := func(nonce uint64, gasLimit uint64) []byte {
+		txMsg := newTestTransferTx(fromAddr, "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", nonce, 100)
+		txMsg.Version  = "1.0"
+		txMsg.GasLimit = new(big.Int).SetUint64(gasLimit).Bytes()
+		txBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), key)
+		assert.Equal(t, nil, err)
+		return txBytes
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.Commit()
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+
+	// the cdcv0 tx is limited by the block gas as the others
+	assert.Equal(t, code.CodeTypeBlockGasExhausted, txContext.DeliverTx(cdcv0TxOf(1, 6000)).Code)
+
+	respDeliverTx := txContext.DeliverTx(cdcv0TxOf(1, 4000))
+	assert.Equal(t, code.CodeTypeOK, respDeliverTx.Code)
+	assert.Equal(t, true, respDeliverTx.GasUsed > 1000)
+	blockGas := blockGasOf()
+	assert.Equal(t, respDeliverTx.GasUsed, blockGas.GasUsed)
+	assert.Equal(t, int64(1), blockGas.TxCount)
+
+	assert.Equal(t, code.CodeTypeBlockGasExhausted, txContext.DeliverTx(cdcv0TxOf(2, 4000)).Code)
+
+	// the V0 tx has no gas limit, it is exempt from the block gas and only counted
+	respDeliverTx = txContext.DeliverTx([]byte(ankrcmm.TrxSendPrefix + "invalid"))
+	assert.NotEqual(t, code.CodeTypeBlockGasExhausted, respDeliverTx.Code)
+	assert.Equal(t, int64(0), respDeliverTx.GasUsed)
+	gasUsed := blockGas.GasUsed
+	blockGas = blockGasOf()
+	assert.Equal(t, int64(2), blockGas.TxCount)
+	assert.Equal(t, gasUsed, blockGas.GasUsed)
+	txContext.Commit()
+}
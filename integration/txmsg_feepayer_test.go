@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/abci/types"
+	cryptoamino "github.com/tendermint/tendermint/crypto/encoding/amino"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestFeePayer(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+	txSerializer := serializer.NewTxSerializerCDC()
+
+	addrOf := func(key crypto.SecretKey) string {
+		keyAddr, _ := key.Address()
+		return fmt.Sprintf("%X", keyAddr)
+	}
+	balanceOf := func(addr string) *big.Int {
+		bal, _, _, _, _ := txContext.AppStore().Balance(addr, "ANKR", 0, false)
+		if bal == nil {
+			return big.NewInt(0)
+		}
+		return bal
+	}
+
+	sender, _  := newTestKey()
+	payer, _   := newTestKey()
+	other, _   := newTestKey()
+	senderAddr := addrOf(sender)
+	payerAddr  := addrOf(payer)
+	toAddr     := "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB"
+
+	sponsoredTx := func(nonce uint64, signers ...crypto.SecretKey) *tx.TxMsg {
+		txMsg := newTestTransferTx(senderAddr, toAddr, nonce, 100)
+		txMsg.FeePayer = payerAddr
+		for _, signer := range signers {
+			assert.Equal(t, nil, txMsg.AppendSign(txSerializer, signer))
+		}
+		return txMsg
+	}
+	txBytesOf := func(txMsg *tx.TxMsg) []byte {
+		txBytes, err := txSerializer.Serialize(txMsg)
+		assert.Equal(t, nil, err)
+		return txBytes
+	}
+
+	// the sender only holds the amount transferred, the fee is paid by the payer
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance(senderAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(1000).Bytes()})
+	txContext.AppStore().SetBalance(payerAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.Commit()
+
+	txBytes := txBytesOf(sponsoredTx(1, sender, payer))
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txBytes).Code)
+
+	payerBal := balanceOf(payerAddr)
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	respDeliverTx := txContext.DeliverTx(txBytes)
+	assert.Equal(t, code.CodeTypeOK, respDeliverTx.Code)
+	txContext.Commit()
+
+	fee := new(big.Int).Mul(big.NewInt(respDeliverTx.GasUsed), new(big.Int).SetUint64(10000000000000))
+	assert.Equal(t, true, fee.Sign() > 0)
+	assert.Equal(t, big.NewInt(900), balanceOf(senderAddr))
+	assert.Equal(t, new(big.Int).Sub(payerBal, fee), balanceOf(payerAddr))
+
+	// the fee payer must sign the tx
+	assert.Equal(t, code.CodeTypeVerifySignaError, txContext.CheckTx(txBytesOf(sponsoredTx(2, sender))).Code)
+	assert.Equal(t, code.CodeTypeVerifySignaError, txContext.CheckTx(txBytesOf(sponsoredTx(2, sender, other))).Code)
+
+	// the fee payer's signature of the other content is refused
+	wrongTx := sponsoredTx(2, sender)
+	otherTx := sponsoredTx(2)
+	otherTx.Memo = "changed"
+	assert.Equal(t, nil, otherTx.AppendSign(txSerializer, payer))
+	wrongTx.Signs = append(wrongTx.Signs, otherTx.Signs[0])
+	assert.Equal(t, code.CodeTypeVerifySignaError, txContext.CheckTx(txBytesOf(wrongTx)).Code)
+
+	// the balance of the fee payer is checked rather than the sender's
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 3}})
+	txContext.AppStore().SetBalance(senderAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.AppStore().SetBalance(payerAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(1).Bytes()})
+	txContext.Commit()
+
+	poorTxBytes := txBytesOf(sponsoredTx(2, sender, payer))
+	assert.Equal(t, code.CodeTypeFeeNotEnough, txContext.CheckTx(poorTxBytes).Code)
+
+	senderBal := balanceOf(senderAddr)
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 4}})
+	assert.Equal(t, code.CodeTypeFeeNotEnough, txContext.DeliverTx(poorTxBytes).Code)
+	txContext.Commit()
+	assert.Equal(t, senderBal, balanceOf(senderAddr))
+}
+
+func TestFeePayerVerifiedAsSigner(t *testing.T) {
+	key1, pubKey1 := newTestKey()
+	key2, pubKey2 := newTestKey()
+	sender, _     := newTestKey()
+	outsider, _   := newTestKey()
+
+	addrOf := func(key crypto.SecretKey) string {
+		keyAddr, _ := key.Address()
+		return fmt.Sprintf("%X", keyAddr)
+	}
+	senderAddr   := addrOf(sender)
+	outsiderAddr := addrOf(outsider)
+	toAddr       := "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB"
+
+	// the permission of the msg applies to the fee payer's key as it does to the signers'
+	registry := tx.NewTxMsgRegistry()
+	for _, info := range newTestTxMsgRegistry(nil).Infos() {
+		if info.Type == txcmm.TxMsgTypeTransfer {
+			info.Permission = func(store appstore.AppStore, msg tx.ImplTxMsg, pubKey []byte) bool {
+				pk, err := cryptoamino.PubKeyFromBytes(pubKey)
+				return err == nil && pk.Address().String() != outsiderAddr
+			}
+		}
+		assert.Equal(t, nil, registry.Register(*info))
+	}
+
+	txContext := ankrchain.NewMockAnkrChainApplicationWithRegistry("testApp", registry, log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+	txSerializer := txContext.TxSerializer()
+
+	pubKeys := []string{pubKey1, pubKey2}
+	msAddr  := crypto.CreateMultiSigAddress(pubKeys, 2)
+
+	sponsoredTxBytes := func(payerAddr string, signers ...crypto.SecretKey) []byte {
+		txMsg := newTestTransferTx(senderAddr, toAddr, 1, 100)
+		txMsg.FeePayer = payerAddr
+		for _, signer := range signers {
+			assert.Equal(t, nil, txMsg.AppendSign(txSerializer, signer))
+		}
+		txBytes, err := txSerializer.Serialize(txMsg)
+		assert.Equal(t, nil, err)
+		return txBytes
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetMultiSigAccount(&ankrcmm.MultiSigAccountInfo{Address: msAddr, Threshold: 2, PubKeys: pubKeys})
+	txContext.AppStore().SetBalance(senderAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(1000).Bytes()})
+	txContext.AppStore().SetBalance(msAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.AppStore().SetBalance(outsiderAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.Commit()
+
+	assert.Equal(t, code.CodeTypeNotPermitPubKey, txContext.CheckTx(sponsoredTxBytes(outsiderAddr, sender, outsider)).Code)
+
+	// the multisig fee payer is verified by the threshold signatures of its members
+	assert.Equal(t, code.CodeTypeMultiSigNotEnoughSigns, txContext.CheckTx(sponsoredTxBytes(msAddr, sender, key1)).Code)
+
+	txBytes := sponsoredTxBytes(msAddr, sender, key1, key2)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txBytes).Code)
+
+	msBal, _, _, _, _ := txContext.AppStore().Balance(msAddr, "ANKR", 0, false)
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	respDeliverTx := txContext.DeliverTx(txBytes)
+	assert.Equal(t, code.CodeTypeOK, respDeliverTx.Code)
+	txContext.Commit()
+
+	fee := new(big.Int).Mul(big.NewInt(respDeliverTx.GasUsed), new(big.Int).SetUint64(10000000000000))
+	msBalAfter, _, _, _, _ := txContext.AppStore().Balance(msAddr, "ANKR", 0, false)
+	assert.Equal(t, true, fee.Sign() > 0)
+	assert.Equal(t, new(big.Int).Sub(msBal, fee), msBalAfter)
+}
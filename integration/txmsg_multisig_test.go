@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestMultiSigSignatures(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+
+	key1, pubKey1 := newTestKey()
+	key2, pubKey2 := newTestKey()
+	key3, pubKey3 := newTestKey()
+	outsider, _   := newTestKey()
+
+	pubKeys := []string{pubKey1, pubKey2, pubKey3}
+	msAddr  := crypto.CreateMultiSigAddress(pubKeys, 2)
+	txContext.AppStore().SetMultiSigAccount(&ankrcmm.MultiSigAccountInfo{Address: msAddr, Threshold: 2, PubKeys: pubKeys})
+
+	// SignAndMarshal keeps the last signature only, so the signatures are collected one by one
+	signedTx := func(keys ...crypto.SecretKey) *tx.TxMsg {
+		txMsg := newTestTransferTx(msAddr, "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", 1, 100)
+		var signs []crypto.Signature
+		for _, key := range keys {
+			_, err := txMsg.SignAndMarshal(txContext.TxSerializer(), key)
+			assert.Equal(t, nil, err)
+			signs = append(signs, txMsg.Signs[0])
+		}
+		txMsg.Signs = signs
+		return txMsg
+	}
+	verify := func(txMsg *tx.TxMsg) uint32 {
+		codeV, _ := txMsg.BasicVerify(txContext)
+		return codeV
+	}
+
+	// the threshold is reached by any members
+	assert.Equal(t, code.CodeTypeOK, verify(signedTx(key1, key2)))
+	assert.Equal(t, code.CodeTypeOK, verify(signedTx(key3, key1)))
+	assert.Equal(t, code.CodeTypeOK, verify(signedTx(key1, key2, key3)))
+
+	// below the threshold
+	assert.Equal(t, code.CodeTypeMultiSigNotEnoughSigns, verify(signedTx()))
+	assert.Equal(t, code.CodeTypeMultiSigNotEnoughSigns, verify(signedTx(key1)))
+
+	// the same member signing twice is counted once
+	dupTx := signedTx(key1)
+	dupTx.Signs = append(dupTx.Signs, dupTx.Signs[0])
+	assert.Equal(t, code.CodeTypeMultiSigNotEnoughSigns, verify(dupTx))
+
+	// the signatures of the keys out of the members are ignored
+	assert.Equal(t, code.CodeTypeMultiSigNotEnoughSigns, verify(signedTx(key1, outsider)))
+	assert.Equal(t, code.CodeTypeOK, verify(signedTx(key1, outsider, key2)))
+
+	// a member's signature of the other content is refused
+	otherTx := signedTx()
+	otherTx.Memo = "changed"
+	_, err := otherTx.SignAndMarshal(txContext.TxSerializer(), key2)
+	assert.Equal(t, nil, err)
+	wrongTx := signedTx(key1)
+	wrongTx.Signs = append(wrongTx.Signs, otherTx.Signs[0])
+	assert.Equal(t, code.CodeTypeVerifySignaError, verify(wrongTx))
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestPendingNonceWindow(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+	txContext.SetPendingNonceWindow(2)
+
+	key, _ := newTestKey()
+	keyAddr, _ := key.Address()
+	fromAddr   := fmt.Sprintf("%X", keyAddr)
+
+	txs := make(map[uint64][]byte)
+	txOf := func(nonce uint64) []byte {
+		if _, ok := txs[nonce]; !ok {
+			txBytes, err := newTestTransferTx(fromAddr, "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", nonce, 100).SignAndMarshal(serializer.NewTxSerializerCDC(), key)
+			assert.Equal(t, nil, err)
+			txs[nonce] = txBytes
+		}
+		return txs[nonce]
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.Commit()
+
+	// the store nonce is 1, so the nonces 1~3 are in the window
+	assert.Equal(t, code.CodeTypeBadNonce, txContext.CheckTx(txOf(2)).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txOf(1)).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txOf(2)).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txOf(3)).Code)
+	assert.Equal(t, code.CodeTypeBadNonce, txContext.CheckTx(txOf(4)).Code)
+
+	// DeliverTx accepts the store nonce only, whatever is pending in the mempool
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	assert.Equal(t, code.CodeTypeBadNonce, txContext.DeliverTx(txOf(2)).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.DeliverTx(txOf(1)).Code)
+	assert.Equal(t, code.CodeTypeBadNonce, txContext.DeliverTx(txOf(1)).Code)
+	assert.Equal(t, code.CodeTypeBadNonce, txContext.DeliverTx(txOf(3)).Code)
+	txContext.Commit()
+
+	// the commit resets the window, the rechecks rebuild it from the new store nonce 2
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txOf(2)).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txOf(3)).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txOf(4)).Code)
+	assert.Equal(t, code.CodeTypeBadNonce, txContext.CheckTx(txOf(5)).Code)
+
+	// the txs dropped from the mempool don't hold the window after the next commit
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 3}})
+	txContext.Commit()
+	assert.Equal(t, code.CodeTypeBadNonce, txContext.CheckTx(txOf(3)).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(txOf(2)).Code)
+}
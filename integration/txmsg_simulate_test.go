@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestSimulateTx(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	cdc := amino.NewCodec()
+	txSerializer := serializer.NewTxSerializerCDC()
+
+	key, _ := newTestKey()
+	keyAddr, _ := key.Address()
+	fromAddr   := fmt.Sprintf("%X", keyAddr)
+	toAddr     := "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB"
+
+	simulate := func(txBytes []byte) *ankrcmm.SimulateTxResp {
+		reqBytes, _ := cdc.MarshalJSON(&ankrcmm.SimulateTxReq{TxBytes: txBytes})
+		respQuery := txContext.Query(types.RequestQuery{Path: "/simulate", Data: reqBytes})
+		assert.Equal(t, code.CodeTypeOK, respQuery.Code)
+
+		var queryResp ankrcmm.QueryResp
+		assert.Equal(t, nil, cdc.UnmarshalJSON(respQuery.Value, &queryResp))
+		simResp := new(ankrcmm.SimulateTxResp)
+		assert.Equal(t, nil, cdc.UnmarshalJSON(queryResp.RespData, simResp))
+
+		return simResp
+	}
+	stateOf := func(addr string) string {
+		bal, _, _, _, _ := txContext.AppStore().Balance(addr, "ANKR", 0, false)
+		nonce, _, _, _, _ := txContext.AppStore().Nonce(addr, 0, false)
+		return fmt.Sprintf("bal=%v, nonce=%d", bal, nonce)
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.Commit()
+
+	txMsg := newTestTransferTx(fromAddr, toAddr, 1, 100)
+	txBytes, err := txMsg.SignAndMarshal(txSerializer, key)
+	assert.Equal(t, nil, err)
+
+	fromState := stateOf(fromAddr)
+	toBal, _, _, _, _ := txContext.AppStore().Balance(toAddr, "ANKR", 0, false)
+
+	// the gas used and the fee are reported without touching the state, so the same tx can be simulated again
+	simResp := simulate(txBytes)
+	assert.Equal(t, code.CodeTypeOK, simResp.Code)
+	assert.Equal(t, true, simResp.GasUsed > 0)
+	assert.Equal(t, new(big.Int).Mul(big.NewInt(simResp.GasUsed), new(big.Int).SetBytes(txMsg.GasPrice.Value)), new(big.Int).SetBytes(simResp.Fee.Value))
+	assert.Equal(t, fromState, stateOf(fromAddr))
+	toBalSim, _, _, _, _ := txContext.AppStore().Balance(toAddr, "ANKR", 0, false)
+	assert.Equal(t, toBal, toBalSim)
+
+	assert.Equal(t, simResp.GasUsed, simulate(txBytes).GasUsed)
+	assert.Equal(t, fromState, stateOf(fromAddr))
+
+	// the simulation runs on the committed state, the working state of the block being executed isn't seen
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(1).Bytes()})
+	assert.Equal(t, code.CodeTypeOK, simulate(txBytes).Code)
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+
+	// the gas used is the same as the delivered tx's
+	respDeliverTx := txContext.DeliverTx(txBytes)
+	assert.Equal(t, code.CodeTypeOK, respDeliverTx.Code)
+	assert.Equal(t, simResp.GasUsed, respDeliverTx.GasUsed)
+	txContext.Commit()
+
+	// the nonce used is refused as DeliverTx does
+	nonceResp := simulate(txBytes)
+	assert.Equal(t, code.CodeTypeBadNonce, nonceResp.Code)
+
+	// the signature of the other content is refused
+	badTx := newTestTransferTx(fromAddr, toAddr, 2, 100)
+	_, err = badTx.SignAndMarshal(txSerializer, key)
+	assert.Equal(t, nil, err)
+	badTx.Memo = "changed"
+	badTxBytes, err := txSerializer.Serialize(badTx)
+	assert.Equal(t, nil, err)
+
+	fromState = stateOf(fromAddr)
+	badResp := simulate(badTxBytes)
+	assert.Equal(t, code.CodeTypeVerifySignaError, badResp.Code)
+	assert.Equal(t, fromState, stateOf(fromAddr))
+}
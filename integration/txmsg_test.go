@@ -1,20 +1,30 @@
 package integration
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"math/big"
 	"strings"
 	"testing"
 
+	"github.com/Ankr-network/ankr-chain/account"
 	ankrcmm "github.com/Ankr-network/ankr-chain/common"
 	"github.com/Ankr-network/ankr-chain/common/code"
 	"github.com/Ankr-network/ankr-chain/consensus"
 	"github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/store/appstore/iavl"
 	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/ankr-chain/tx/key"
+	"github.com/Ankr-network/ankr-chain/tx/metering"
 	"github.com/Ankr-network/ankr-chain/tx/serializer"
 	"github.com/Ankr-network/ankr-chain/tx/token"
+	upgradetx "github.com/Ankr-network/ankr-chain/tx/upgrade"
+	"github.com/Ankr-network/ankr-chain/upgrade"
 	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/libs/log"
 )
 
@@ -58,6 +68,393 @@ func TestTxTransfer(t *testing.T) {
 	assert.Equal(t, respDeliverTx.Code, code.CodeTypeOK)
 }
 
+func TestTxTransferProto(t *testing.T) {
+	tfMsg := &token.TransferMsg{FromAddr: "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67",
+		ToAddr:  "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB",
+		Amounts: []ankrcmm.Amount{ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(6000000000000000000).Bytes()}},
+	}
+
+	txGasLimit := new(big.Int).SetUint64(1000).Bytes()
+	txMsg := &tx.TxMsg{ChID: "ankr-chain", Nonce: 3, GasLimit: txGasLimit, GasPrice: ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()}, Memo: "transfermsg testing", Version: "1.0.2", ImplTxMsg: tfMsg}
+
+	txSerializer := serializer.NewTxSerializerProto()
+
+	key := crypto.NewSecretKeyEd25519("wmyZZoMedWlsPUDVCOy+TiVcrIBPcn3WJN8k5cPQgIvC8cbcR10FtdAdzIlqXQJL9hBw1i0RsVjF6Oep/06Ezg==")
+
+	sendBytes, err := txMsg.SignAndMarshal(txSerializer, key)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, serializer.IsProtoTx(sendBytes), true)
+
+	signBytes := txMsg.SignBytes(txSerializer)
+	assert.Equal(t, sendBytes[:len(signBytes)], signBytes)
+
+	txM, err := txSerializer.DeserializeProto(sendBytes)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, txM.Nonce, txMsg.Nonce)
+	assert.Equal(t, txM.ImplTxMsg, txMsg.ImplTxMsg)
+	assert.Equal(t, txM.VerifySigns(txSerializer), nil)
+
+	_, err = txSerializer.DeserializeProto(append(sendBytes, 0x00))
+	assert.NotEqual(t, err, nil)
+}
+
+func TestTxMsgRegistry(t *testing.T) {
+	registry := serializer.NewBuiltinTxMsgRegistry()
+
+	err := registry.Register(tx.TxMsgInfo{Type: txcmm.TxMsgTypeTransfer, CodecName: "ankr-chain/tx/token/tranferTxMsg", Msg: &token.TransferMsg{}})
+	assert.NotEqual(t, err, nil)
+
+	err = registry.Register(tx.TxMsgInfo{Type: txcmm.TxMsgTypeKeyMsg, CodecName: "ankr-chain/tx/key/KeyMsg", Msg: &key.KeyMsg{}, BaseGas: 10})
+	assert.Equal(t, err, nil)
+
+	info, ok := registry.Info(txcmm.TxMsgTypeKeyMsg)
+	assert.Equal(t, ok, true)
+	assert.Equal(t, info.BaseGas, uint64(10))
+
+	// the infos returned are copies, the registered ones are fixed
+	info.BaseGas = 20
+	info, _ = registry.Info(txcmm.TxMsgTypeKeyMsg)
+	assert.Equal(t, info.BaseGas, uint64(10))
+
+	registry.Infos()[len(registry.Infos())-1].BaseGas = 30
+	assert.Equal(t, registry.Infos()[len(registry.Infos())-1].BaseGas, uint64(10))
+
+	keyMsg := &key.KeyMsg{FromAddr: "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", KeyName: "testKey", KeyValue: "testValue"}
+	txMsg := &tx.TxMsg{ChID: "ankr-chain", Nonce: 1, GasLimit: new(big.Int).SetUint64(1000).Bytes(), GasPrice: ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()}, Version: "1.0", ImplTxMsg: keyMsg}
+
+	secretKey := crypto.NewSecretKeyEd25519("wmyZZoMedWlsPUDVCOy+TiVcrIBPcn3WJN8k5cPQgIvC8cbcR10FtdAdzIlqXQJL9hBw1i0RsVjF6Oep/06Ezg==")
+	sendBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDCWithRegistry(registry), secretKey)
+	assert.Equal(t, err, nil)
+
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+
+	respCheckTx := txContext.CheckTx(sendBytes)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeUnknownTxMsgType)
+
+	respDeliverTx := txContext.DeliverTx(sendBytes)
+	assert.Equal(t, respDeliverTx.Code, code.CodeTypeUnknownTxMsgType)
+}
+
+func TestBlockGasLimit(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+
+	respInit := txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain", ConsensusParams: &types.ConsensusParams{Block: &types.BlockParams{MaxBytes: 22020096, MaxGas: 500}}})
+	assert.Equal(t, respInit.ConsensusParams.Block.MaxGas, int64(500))
+	assert.Equal(t, respInit.ConsensusParams.Block.MaxBytes, int64(22020096))
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+
+	tfMsg := &token.TransferMsg{FromAddr: "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67",
+		ToAddr:  "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB",
+		Amounts: []ankrcmm.Amount{ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(6000000000000000000).Bytes()}},
+	}
+	txMsg := &tx.TxMsg{ChID: "ankr-chain", Nonce: 1, GasLimit: new(big.Int).SetUint64(1000).Bytes(), GasPrice: ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()}, Version: "1.0.2", ImplTxMsg: tfMsg}
+
+	secretKey := crypto.NewSecretKeyEd25519("wmyZZoMedWlsPUDVCOy+TiVcrIBPcn3WJN8k5cPQgIvC8cbcR10FtdAdzIlqXQJL9hBw1i0RsVjF6Oep/06Ezg==")
+	sendBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), secretKey)
+	assert.Equal(t, err, nil)
+
+	respDeliverTx := txContext.DeliverTx(sendBytes)
+	assert.Equal(t, respDeliverTx.Code, code.CodeTypeBlockGasExhausted)
+
+	respQuery := txContext.Query(types.RequestQuery{Path: "/blockgas"})
+	assert.Equal(t, respQuery.Code, code.CodeTypeOK)
+}
+
+func TestGenesisAppState(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+
+	appStore := txContext.AppStore()
+	genesisAddr := account.AccountManagerInstance().GenesisAccountAddress()
+	ankrSupply, _, _, _, err := appStore.Balance(genesisAddr, "ANKR", 0, false)
+	assert.Equal(t, err, nil)
+
+	adminMetering := account.AccountManagerInstance().AdminOpAccount(ankrcmm.AccountAdminMetering)
+	defer account.AccountManagerInstance().SetAdminOpAccount(appStore, ankrcmm.AccountAdminMetering, adminMetering)
+
+	appState := `{
+		"accounts": [{"address": "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", "nonce": 5, "balances": [{"symbol": "ANKR", "value": "1000"}, {"symbol": "TEST", "value": "10"}]}],
+		"admins": [{"type": "adminmetering", "pubkey": "sxhP4F6OLZKNPQ2lG13WcHzitNX9++h56cppBDhwMlI="}],
+		"currencies": [{"symbol": "TEST", "decimal": 6, "totalsupply": "1000000"}],
+		"roles": [{"name": "testrole", "roletype": 1, "pubkey": "sxhP4F6OLZKNPQ2lG13WcHzitNX9++h56cppBDhwMlI=", "addresses": ["454D92DC842F532683E820DF6C3784473AD9CCF222D8FB"]}],
+		"mingasprice": {"symbol": "ANKR", "value": "20000000000000"}
+	}`
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain", AppStateBytes: []byte(appState)})
+
+	bal, _, _, _, err := appStore.Balance("454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", "ANKR", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, bal.String(), "1000")
+
+	bal, _, _, _, err = appStore.Balance(genesisAddr, "ANKR", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, bal, new(big.Int).Sub(ankrSupply, big.NewInt(1000)))
+
+	bal, _, _, _, err = appStore.Balance(genesisAddr, "TEST", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, bal.String(), "999990")
+
+	nonce, _, _, _, err := appStore.Nonce("454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, nonce, uint64(5))
+
+	assert.Equal(t, account.AccountManagerInstance().AdminOpAccount(ankrcmm.AccountAdminMetering), "sxhP4F6OLZKNPQ2lG13WcHzitNX9++h56cppBDhwMlI=")
+
+	rInfo, _, _, _, err := appStore.LoadRole("testrole", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, rInfo.Type, ankrcmm.RoleGeneral)
+
+	minGasPrice, _, _, _, err := appStore.MinGasPrice("ANKR", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, new(big.Int).SetBytes(minGasPrice.Value).String(), "20000000000000")
+
+	assert.Panics(t, func() {
+		ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger()).InitChain(types.RequestInitChain{ChainId: "ankr-chain", AppStateBytes: []byte(`{"acounts": []}`)})
+	})
+}
+
+func TestGenesisSnapshot(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	appStore := txContext.AppStore()
+	appStore.SetBalance("454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(1000).Bytes()})
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.Commit()
+
+	snapshot, err := appStore.(*iavl.IavlStoreApp).Export(0)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, snapshot.ChainID, "ankr-chain")
+
+	appStateBytes, err := json.Marshal(&ankrcmm.GenesisAppState{Snapshot: snapshot})
+	assert.Equal(t, err, nil)
+
+	txContextNew := ankrchain.NewMockAnkrChainApplication("testAppNew", log.NewNopLogger())
+	txContextNew.InitChain(types.RequestInitChain{ChainId: "ankr-chain-new", AppStateBytes: appStateBytes})
+
+	bal, _, _, _, err := txContextNew.AppStore().Balance("454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", "ANKR", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, bal.String(), "1000")
+
+	genesisAddr := account.AccountManagerInstance().GenesisAccountAddress()
+	balOld, _, _, _, _ := appStore.Balance(genesisAddr, "ANKR", 0, false)
+	balNew, _, _, _, _ := txContextNew.AppStore().Balance(genesisAddr, "ANKR", 0, false)
+	assert.Equal(t, balNew, balOld)
+
+	assert.Equal(t, txContextNew.AppStore().ChainID(), "ankr-chain-new")
+}
+
+func TestUpgradePlan(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	appStore := txContext.AppStore()
+	adminOP := account.AccountManagerInstance().AdminOpAccount(ankrcmm.AccountAdminOP)
+	account.AccountManagerInstance().SetAdminOpAccount(appStore, ankrcmm.AccountAdminOP, "wvHG3EddBbXQHcyJal0CS/YQcNYtEbFYxejnqf9OhM4=")
+	defer account.AccountManagerInstance().SetAdminOpAccount(appStore, ankrcmm.AccountAdminOP, adminOP)
+
+	err := txContext.UpgradeManager().Register(&upgrade.Upgrade{
+		Name: "testupgrade",
+		Migrate: func(store appstore.AppStore) error {
+			store.SetBalance("454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(100).Bytes()})
+			return nil
+		},
+		TxVersions: []upgrade.TxVersionRule{{Format: upgrade.TxFormatCDCV1, MsgType: txcmm.TxMsgTypeTransfer, Versions: []string{"1.0.3"}}},
+	})
+	assert.Equal(t, err, nil)
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+
+	upMsg := &upgradetx.UpgradeMsg{FromAddr: "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", Name: "testupgrade", Height: 3, Info: "test upgrade"}
+	txMsg := &tx.TxMsg{ChID: "ankr-chain", Nonce: 1, GasLimit: new(big.Int).SetUint64(100000).Bytes(), GasPrice: ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()}, Version: "1.0", ImplTxMsg: upMsg}
+
+	secretKey := crypto.NewSecretKeyEd25519("wmyZZoMedWlsPUDVCOy+TiVcrIBPcn3WJN8k5cPQgIvC8cbcR10FtdAdzIlqXQJL9hBw1i0RsVjF6Oep/06Ezg==")
+	sendBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), secretKey)
+	assert.Equal(t, err, nil)
+
+	respDeliverTx := txContext.DeliverTx(sendBytes)
+	assert.Equal(t, respDeliverTx.Code, code.CodeTypeOK)
+	txContext.Commit()
+
+	plan, _, _, _, err := appStore.UpgradePlan(0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, plan.Name, "testupgrade")
+	assert.Equal(t, plan.Height, int64(3))
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	txContext.Commit()
+	assert.Equal(t, appStore.UpgradeDoneHeight("testupgrade"), int64(0))
+	assert.Equal(t, txContext.UpgradeManager().AcceptTxVersion(upgrade.TxFormatCDCV1, txcmm.TxMsgTypeTransfer, "1.0.2"), true)
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 3}})
+	txContext.Commit()
+	assert.Equal(t, appStore.UpgradeDoneHeight("testupgrade"), int64(3))
+	assert.Equal(t, txContext.UpgradeManager().AcceptTxVersion(upgrade.TxFormatCDCV1, txcmm.TxMsgTypeTransfer, "1.0.2"), false)
+	assert.Equal(t, txContext.UpgradeManager().AcceptTxVersion(upgrade.TxFormatCDCV1, txcmm.TxMsgTypeTransfer, "1.0.3"), true)
+
+	plan, _, _, _, _ = appStore.UpgradePlan(0, false)
+	assert.Equal(t, plan == nil, true)
+
+	bal, _, _, _, err := appStore.Balance("454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", "ANKR", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, bal.String(), "100")
+
+	appStore.SetUpgradePlan(&ankrcmm.UpgradePlan{Name: "unknownupgrade", Height: 4})
+	assert.Panics(t, func() {
+		txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 4}})
+	})
+
+	// the upgrades and their tx version rules are owned by the app applying them
+	otherContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	assert.Equal(t, otherContext.UpgradeManager().AcceptTxVersion(upgrade.TxFormatCDCV1, txcmm.TxMsgTypeTransfer, "1.0.2"), true)
+	assert.Equal(t, otherContext.UpgradeManager().Register(&upgrade.Upgrade{Name: "testupgrade"}), nil)
+
+	txContext.UpgradeManager().Load(ankrchain.NewMockAppStore())
+	assert.Equal(t, txContext.UpgradeManager().AcceptTxVersion(upgrade.TxFormatCDCV1, txcmm.TxMsgTypeTransfer, "1.0.2"), true)
+}
+
+func TestDeliverTxSignatureUpgrade(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	key, _       := newTestKey()
+	payer, _     := newTestKey()
+	keyAddr, _   := key.Address()
+	payerAddr, _ := payer.Address()
+	fromAddr     := fmt.Sprintf("%X", keyAddr)
+	toAddr     := "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB"
+	txSerializer := serializer.NewTxSerializerCDC()
+
+	// the signature of the other content is attached
+	badSignedTxBytes := func(txMsg *tx.TxMsg) []byte {
+		signedTx := *txMsg
+		signedTx.Memo = "changed"
+		_, err := signedTx.SignAndMarshal(txSerializer, key)
+		assert.Equal(t, err, nil)
+		txMsg.Signs = signedTx.Signs
+		txBytes, err := txSerializer.Serialize(txMsg)
+		assert.Equal(t, err, nil)
+		return txBytes
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.AppStore().SetUpgradePlan(&ankrcmm.UpgradePlan{Name: upgrade.UpgradeDeliverTxSignature, Height: 3})
+
+	// the signatures aren't verified by DeliverTx before the upgrade, as the blocks replayed were delivered
+	assert.Equal(t, txContext.DeliverTx(badSignedTxBytes(newTestTransferTx(fromAddr, toAddr, 1, 100))).Code, code.CodeTypeOK)
+	txContext.Commit()
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	txContext.Commit()
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 3}})
+	assert.Equal(t, txContext.AppStore().UpgradeDoneHeight(upgrade.UpgradeDeliverTxSignature), int64(3))
+
+	assert.Equal(t, txContext.DeliverTx(badSignedTxBytes(newTestTransferTx(fromAddr, toAddr, 2, 100))).Code, code.CodeTypeVerifySignaError)
+
+	// the fee payer must sign the tx delivered too
+	sponsoredTx := newTestTransferTx(fromAddr, toAddr, 2, 100)
+	sponsoredTx.FeePayer = fmt.Sprintf("%X", payerAddr)
+	assert.Equal(t, sponsoredTx.AppendSign(txSerializer, key), nil)
+	sponsoredTxBytes, err := txSerializer.Serialize(sponsoredTx)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, txContext.DeliverTx(sponsoredTxBytes).Code, code.CodeTypeVerifySignaError)
+
+	txBytes, err := newTestTransferTx(fromAddr, toAddr, 2, 100).SignAndMarshal(txSerializer, key)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, txContext.DeliverTx(txBytes).Code, code.CodeTypeOK)
+	txContext.Commit()
+}
+
+func TestAppHashMismatchRollback(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	var haltErr error
+	txContext.SetHaltHandler(func(err error) { haltErr = err })
+	txContext.SetAppHashMismatchRollback(true)
+
+	appStore := txContext.AppStore()
+	for height := int64(1); height <= 2; height++ {
+		txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: height, AppHash: appStore.APPHashByHeight(height-1)}})
+		appStore.IncTotalTx()
+		txContext.Commit()
+	}
+	assert.Equal(t, int64(2), appStore.Height())
+
+	// Commit never goes on to report the app hash after the halt handler returns
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 3, AppHash: []byte{0x01}}})
+	assert.Panics(t, func() {
+		txContext.Commit()
+	})
+	assert.NotEqual(t, nil, haltErr)
+
+	mismatch := appStore.AppHashMismatch()
+	assert.Equal(t, int64(2), mismatch.Height)
+	assert.Equal(t, int64(1), mismatch.AgreedHeight)
+	assert.Equal(t, []byte{0x01}, mismatch.ExpectedAppHash)
+
+	assert.Equal(t, int64(1), appStore.Height())
+	assert.Equal(t, appStore.APPHashByHeight(1), appStore.APPHash())
+}
+
+func TestCheckTxRecheck(t *testing.T) {
+	// the base gas makes the cert msg pay the fee
+	txContext := ankrchain.NewMockAnkrChainApplicationWithRegistry("testApp", newTestTxMsgRegistry(map[string]uint64{txcmm.TxMsgTypeSetCertMsg: 100}), log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	secretKey := crypto.NewSecretKeyEd25519("wmyZZoMedWlsPUDVCOy+TiVcrIBPcn3WJN8k5cPQgIvC8cbcR10FtdAdzIlqXQJL9hBw1i0RsVjF6Oep/06Ezg==")
+	signTx := func(nonce uint64, gasPrice uint64) []byte {
+		certMsg := &metering.SetCertMsg{FromAddr: "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", DCName: "dc1", PemBase64: "pem"}
+		txMsg := &tx.TxMsg{ChID: "ankr-chain", Nonce: nonce, GasLimit: new(big.Int).SetUint64(1000).Bytes(), GasPrice: ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(gasPrice).Bytes()}, Version: "1.0", ImplTxMsg: certMsg}
+		txBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), secretKey)
+		assert.Equal(t, err, nil)
+		return txBytes
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(1000000000000000000).Bytes()})
+	txContext.Commit()
+
+	tx1 := signTx(1, 10000000000000)
+	tx2 := signTx(2, 20000000000000)
+
+	respCheckTx := txContext.CheckTx(tx1)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+
+	respCheckTx = txContext.CheckTx(tx2)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	respDeliverTx := txContext.DeliverTx(tx1)
+	assert.Equal(t, respDeliverTx.Code, code.CodeTypeOK)
+	txContext.Commit()
+
+	// the remaining tx is rechecked after the commit
+	respCheckTx = txContext.CheckTx(tx2)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+
+	// the balance only covers the fee of the pending tx and half of another one
+	fee := new(big.Int).Mul(big.NewInt(respDeliverTx.GasUsed), big.NewInt(20000000000000))
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 3}})
+	txContext.AppStore().SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Div(new(big.Int).Mul(fee, big.NewInt(3)), big.NewInt(2)).Bytes()})
+	txContext.Commit()
+
+	respCheckTx = txContext.CheckTx(tx2)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+
+	respCheckTx = txContext.CheckTx(signTx(3, 20000000000000))
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeFeeNotEnough)
+
+	// the recheck drops the tx whose fee can't be paid anymore
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 4}})
+	txContext.AppStore().SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(1).Bytes()})
+	txContext.Commit()
+
+	respCheckTx = txContext.CheckTx(tx2)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeFeeNotEnough)
+}
+
 func TestBigCmp(t *testing.T) {
 	bigOne := new(big.Int).SetUint64(10000000000000)
 	bigTwo, _ := new(big.Int).SetString("100000000000000", 10)
@@ -75,3 +472,88 @@ func TestReadContract(t *testing.T) {
 
 	fmt.Printf("%s\n", strings.Replace(strings.Trim(fmt.Sprint(rawBytes), "[]"), " ", ",", -1))
 }
+
+func TestCheckTxOnCheckState(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplicationWithRegistry("testApp", newTestTxMsgRegistry(map[string]uint64{txcmm.TxMsgTypeSetCertMsg: 100}), log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	secretKey := crypto.NewSecretKeyEd25519("wmyZZoMedWlsPUDVCOy+TiVcrIBPcn3WJN8k5cPQgIvC8cbcR10FtdAdzIlqXQJL9hBw1i0RsVjF6Oep/06Ezg==")
+	certMsg := &metering.SetCertMsg{FromAddr: "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", DCName: "dc1", PemBase64: "pem"}
+	txMsg := &tx.TxMsg{ChID: "ankr-chain", Nonce: 1, GasLimit: new(big.Int).SetUint64(1000).Bytes(), GasPrice: ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(10000000000000).Bytes()}, Version: "1.0", ImplTxMsg: certMsg}
+	txBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), secretKey)
+	assert.Equal(t, err, nil)
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(1000000000000000000).Bytes()})
+	txContext.Commit()
+
+	// the balance changed by the block being executed isn't seen until it is committed
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	txContext.AppStore().SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(1).Bytes()})
+
+	respCheckTx := txContext.CheckTx(txBytes)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+
+	// the pre-run of the check never touches the state of the block
+	pem, _, _, _ := txContext.AppStore().CertKey("dc1", 0, false)
+	assert.Equal(t, pem, "")
+
+	txContext.Commit()
+
+	respCheckTx = txContext.CheckTx(txBytes)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeFeeNotEnough)
+}
+
+func TestCheckTxCongestion(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplicationWithRegistry("testApp", newTestTxMsgRegistry(map[string]uint64{txcmm.TxMsgTypeSetCertMsg: 100}), log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+	txContext.SetCongestionOptions(1, 2)
+
+	secretKey := crypto.NewSecretKeyEd25519("wmyZZoMedWlsPUDVCOy+TiVcrIBPcn3WJN8k5cPQgIvC8cbcR10FtdAdzIlqXQJL9hBw1i0RsVjF6Oep/06Ezg==")
+	signTx := func(nonce uint64, gasPrice uint64) []byte {
+		certMsg := &metering.SetCertMsg{FromAddr: "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", DCName: "dc1", PemBase64: "pem"}
+		txMsg := &tx.TxMsg{ChID: "ankr-chain", Nonce: nonce, GasLimit: new(big.Int).SetUint64(1000).Bytes(), GasPrice: ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(gasPrice).Bytes()}, Version: "1.0", ImplTxMsg: certMsg}
+		txBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), secretKey)
+		assert.Equal(t, err, nil)
+		return txBytes
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(1000000000000000000).Bytes()})
+	txContext.Commit()
+
+	tx1 := signTx(1, 10000000000000)
+	respCheckTx := txContext.CheckTx(tx1)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+
+	// the mempool is congested by the pending tx, so the new tx must pay twice the min gas price
+	respCheckTx = txContext.CheckTx(signTx(2, 10000000000000))
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeGasPriceIrregular)
+
+	tx2 := signTx(2, 20000000000000)
+	respCheckTx = txContext.CheckTx(tx2)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+
+	// the pending txs are rechecked against the min gas price as usual
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 2}})
+	txContext.Commit()
+
+	respCheckTx = txContext.CheckTx(tx1)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+	respCheckTx = txContext.CheckTx(tx2)
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+
+	respCheckTx = txContext.CheckTx(signTx(3, 10000000000000))
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeGasPriceIrregular)
+
+	// the min gas price is enough again once the pending txs are delivered
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 3}})
+	respDeliverTx := txContext.DeliverTx(tx1)
+	assert.Equal(t, respDeliverTx.Code, code.CodeTypeOK)
+	respDeliverTx = txContext.DeliverTx(tx2)
+	assert.Equal(t, respDeliverTx.Code, code.CodeTypeOK)
+	txContext.Commit()
+
+	respCheckTx = txContext.CheckTx(signTx(3, 10000000000000))
+	assert.Equal(t, respCheckTx.Code, code.CodeTypeOK)
+}
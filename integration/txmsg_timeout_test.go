@@ -0,0 +1,82 @@
+package integration
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestTxTimeoutHeight(t *testing.T) {
+	txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+	txContext.InitChain(types.RequestInitChain{ChainId: "ankr-chain"})
+
+	key, _ := newTestKey()
+	keyAddr, _ := key.Address()
+	fromAddr   := fmt.Sprintf("%X", keyAddr)
+	toAddr     := "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB"
+
+	signTx := func(nonce uint64, timeoutHeight int64) []byte {
+		txMsg := newTestTransferTx(fromAddr, toAddr, nonce, 100)
+		txMsg.TimeoutHeight = timeoutHeight
+		txBytes, err := txMsg.SignAndMarshal(serializer.NewTxSerializerCDC(), key)
+		assert.Equal(t, nil, err)
+		return txBytes
+	}
+	nonceOf := func() uint64 {
+		nonce, _, _, _, _ := txContext.AppStore().Nonce(fromAddr, 0, false)
+		return nonce
+	}
+	commitBlock := func(height int64, txs ...[]byte) []types.ResponseDeliverTx {
+		txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: height}})
+		var resps []types.ResponseDeliverTx
+		for _, txBytes := range txs {
+			resps = append(resps, txContext.DeliverTx(txBytes))
+		}
+		txContext.Commit()
+		return resps
+	}
+
+	txContext.BeginBlock(types.RequestBeginBlock{Header: types.Header{Height: 1}})
+	txContext.AppStore().SetBalance(fromAddr, ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).Mul(big.NewInt(100), big.NewInt(1000000000000000000)).Bytes()})
+	txContext.Commit()
+
+	// both txs can be included in the blocks up to the height 3
+	tx1 := signTx(1, 3)
+	tx2 := signTx(2, 3)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(tx1).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(tx2).Code)
+
+	// the tx checked after the height 1 is included at the height 2 at the earliest
+	assert.Equal(t, code.CodeTypeTxExpired, txContext.CheckTx(signTx(3, 1)).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(signTx(3, 0)).Code)
+
+	// the recheck after the height 2 still accepts them, the next block is at their timeout height
+	commitBlock(2)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(tx1).Code)
+	assert.Equal(t, code.CodeTypeOK, txContext.CheckTx(tx2).Code)
+
+	// the tx is delivered at its timeout height
+	resps := commitBlock(3, tx1)
+	assert.Equal(t, code.CodeTypeOK, resps[0].Code)
+	assert.Equal(t, uint64(2), nonceOf())
+
+	// the recheck after the timeout height drops the remaining one
+	assert.Equal(t, code.CodeTypeTxExpired, txContext.CheckTx(tx2).Code)
+
+	// and it isn't delivered after the timeout height either, the nonce isn't consumed
+	resps = commitBlock(4, tx2)
+	assert.Equal(t, code.CodeTypeTxExpired, resps[0].Code)
+	assert.Equal(t, uint64(2), nonceOf())
+
+	resps = commitBlock(5, signTx(2, 5))
+	assert.Equal(t, code.CodeTypeOK, resps[0].Code)
+	assert.Equal(t, uint64(3), nonceOf())
+}
@@ -17,6 +17,10 @@ func main() {
 	commands.AddTendermintCoreCommands(rootCmd)
 
 	rootCmd.AddCommand(commands.InitFilesCmd)
+	rootCmd.AddCommand(commands.ExportCmd)
+	rootCmd.AddCommand(commands.RollbackCmd)
+	rootCmd.AddCommand(commands.RestoreCmd)
+	rootCmd.AddCommand(commands.MigrateDBCmd)
 
 	nodeFunc := ankrnode.NewAnkrNode
 
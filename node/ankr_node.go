@@ -9,6 +9,9 @@ import (
 	"github.com/Ankr-network/ankr-chain/consensus"
 	ankrp2p "github.com/Ankr-network/ankr-chain/p2p"
 	"github.com/Ankr-network/ankr-chain/store/historystore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/Ankr-network/ankr-chain/upgrade"
 	tmcorelog "github.com/tendermint/tendermint/libs/log"
 	tmcorenode "github.com/tendermint/tendermint/node"
 	"github.com/tendermint/tendermint/p2p"
@@ -25,6 +28,19 @@ type AnkrNode struct {
 type AnkrNodeProvider func(*ankrconfig.AnkrConfig, tmcorelog.Logger) (*AnkrNode, error)
 
 func NewAnkrNode(config *ankrconfig.AnkrConfig, logger tmcorelog.Logger) (*AnkrNode, error) {
+	return newAnkrNode(config, serializer.NewBuiltinTxMsgRegistry(), upgrade.NewUpgradeManager(), logger)
+}
+
+// NewAnkrNodeProvider returns the provider whose node accepts the tx msgs in the registry and applies the upgrades
+// registered into the upgrade manager, so the private msgs can be added by registering them into the registry from
+// serializer.NewBuiltinTxMsgRegistry in main, and the upgrades supported by the binary into upgrade.NewUpgradeManager
+func NewAnkrNodeProvider(registry *tx.TxMsgRegistry, upgradeManager *upgrade.UpgradeManager) AnkrNodeProvider {
+	return func(config *ankrconfig.AnkrConfig, logger tmcorelog.Logger) (*AnkrNode, error) {
+		return newAnkrNode(config, registry, upgradeManager, logger)
+	}
+}
+
+func newAnkrNode(config *ankrconfig.AnkrConfig, registry *tx.TxMsgRegistry, upgradeManager *upgrade.UpgradeManager, logger tmcorelog.Logger) (*AnkrNode, error) {
 	// Generate node PrivKey
 	nodeKey, err := p2p.LoadOrGenNodeKey(config.NodeKeyFile())
 	if err != nil {
@@ -48,7 +64,22 @@ func NewAnkrNode(config *ankrconfig.AnkrConfig, logger tmcorelog.Logger) (*AnkrN
 		oldPV.Upgrade(newPrivValKey, newPrivValState)
 	}
 
-	ankrChainApp := ankrchain.NewAnkrChainApplication(config.DBDir(), ankrcmm.APPName, logger.With("module", "AnkrChainApp"))
+	pruningOpts, err := ankrcmm.NewPruningOptions(config.Pruning, config.PruningKeepRecent, config.PruningKeepEvery)
+	if err != nil {
+		return nil, err
+	}
+
+	ankrChainApp := ankrchain.NewAnkrChainApplicationWithBackend(config.DBDir(), config.AppDBBackend, ankrcmm.APPName, registry, upgradeManager, logger.With("module", "AnkrChainApp"))
+	ankrChainApp.SetPendingNonceWindow(config.PendingNonceWindow)
+	ankrChainApp.SetCongestionOptions(config.CongestionPendingTxs, config.CongestionGasPriceMultiple)
+	ankrChainApp.SetDefaultMaxBlockGas(config.MaxBlockGas)
+	ankrChainApp.SetSnapshotOptions(config.SnapshotInterval, config.SnapshotKeepRecent)
+	ankrChainApp.SetAppHashMismatchRollback(config.AppHashMismatchRollback)
+	ankrChainApp.SetPruningOptions(pruningOpts)
+	ankrChainApp.SetHaltHandler(func(err error) {
+		logger.Error("AnkrChainApp halted, run the rollback command to check the app hash mismatch recorded", "err", err)
+		os.Exit(1)
+	})
 
 	config.FilterPeers = config.AllowedPeers != ""
 
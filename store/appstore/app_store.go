@@ -19,6 +19,8 @@ type AccountStore interface {
 	Balance(address string, symbol string, height int64, prove bool) (*big.Int, string, *iavl.RangeProof, []byte, error)
 	SetAllowance(addrSender string, addrSpender string, amount ankrcmm.Amount)
 	Allowance(addrSender string, addrSpender string, symbol string) (*big.Int, error)
+	SetMultiSigAccount(msInfo *ankrcmm.MultiSigAccountInfo)
+	MultiSigAccount(address string, height int64, prove bool) (*ankrcmm.MultiSigAccountInfo, string, *iavl.RangeProof, []byte, error)
 }
 
 type BCStore interface {
@@ -43,6 +45,13 @@ type TxStore interface {
 	TotalTx(height int64, prove bool) (int64, string, *iavl.RangeProof, []byte, error)
 	SetTotalTx(totalTx int64)
 	IncTotalTx() int64
+	SetMinGasPrice(minGasPrice *ankrcmm.Amount)
+	MinGasPrice(symbol string, height int64, prove bool) (*ankrcmm.Amount, string, *iavl.RangeProof, []byte, error)
+	SetFeeCurrency(feeCurInfo *ankrcmm.FeeCurrencyInfo)
+	FeeCurrency(symbol string, height int64, prove bool) (*ankrcmm.FeeCurrencyInfo, string, *iavl.RangeProof, []byte, error)
+	DeleteFeeCurrency(symbol string)
+	SetMaxBlockGas(maxBlockGas int64)
+	MaxBlockGas() int64
 }
 
 type ContractStore interface {
@@ -69,6 +78,46 @@ type PermissionStore interface {
 	LoadBoundRoles(address string) ([]string, error)
 }
 
+// UpgradeStore keeps the upgrade plan scheduled and the heights the upgrades were applied at
+type UpgradeStore interface {
+	SetUpgradePlan(plan *ankrcmm.UpgradePlan)
+	UpgradePlan(height int64, prove bool) (*ankrcmm.UpgradePlan, string, *iavl.RangeProof, []byte, error)
+	DeleteUpgradePlan()
+	SetUpgradeDone(name string, height int64)
+	UpgradeDoneHeight(name string) int64
+}
+
+// SnapshotStore creates the snapshots of the committed app state and restores them into the stores never committed
+type SnapshotStore interface {
+	CreateSnapshot(height int64) (*ankrcmm.SnapshotInfo, error)
+	Snapshots() []*ankrcmm.SnapshotInfo
+	SnapshotChunk(height int64, format uint32, chunk uint32) []byte
+	PruneSnapshots(keepRecent int)
+	OfferSnapshot(info *ankrcmm.SnapshotInfo) error
+	ApplySnapshotChunk(index uint32, chunk []byte) (bool, error)
+}
+
+// RecoveryStore diagnoses the app hash mismatch and rolls the app store back to the height agreed by the chain
+type RecoveryStore interface {
+	RecordAppHashMismatch(height int64, expectedAppHash []byte) *ankrcmm.AppHashMismatch
+	AppHashMismatch() *ankrcmm.AppHashMismatch
+	StoreHashes(height int64) ([]byte, []ankrcmm.StoreHash, error)
+	LoadVersion(height int64) error
+}
+
+// RangeStore lists the kv pairs of any store by the prefix or the key range page by page
+type RangeStore interface {
+	Range(req *ankrcmm.RangeQueryReq, height int64, prove bool) (*ankrcmm.RangeQueryResp, *iavl.RangeProof, error)
+}
+
+// BranchStore isolates the writes, a branch is written into the store it is branched from only if it succeeds
+type BranchStore interface {
+	Branch() AppStore
+	CommittedBranch() AppStore
+	Write()
+	Discard()
+}
+
 type QueryHandler interface {
 	Query(reqQuery types.RequestQuery) (resQuery types.ResponseQuery)
 }
@@ -80,12 +129,20 @@ type AppStore interface {
 	ContractStore
 	BCStore
 	PermissionStore
+	SnapshotStore
+	UpgradeStore
+	RecoveryStore
+	RangeStore
+	BranchStore
 	SetChainID(chainID string)
 	ChainID() string
 	APPHash() []byte
 	APPHashByHeight(height int64) []byte
+	LastCommitState() ankrcmm.CommitState
 	KVState() ankrapscmm.State
 	ResetKVState()
 	Rollback()
+	ImportSnapshot(snapshot *ankrcmm.GenesisSnapshot) error
+	SetPruningOptions(opts ankrcmm.PruningOptions)
     DB() dbm.DB
 }
\ No newline at end of file
@@ -13,13 +13,16 @@ import (
 	"github.com/tendermint/tendermint/libs/log"
 )
 
+// IavlStore keeps the versions of the tree shifted by versionOffset, which is non-zero only if the store is restored
+// from a snapshot, since the tree can't save its first version at the snapshot height
 type IavlStore struct {
-	tree           *iavl.MutableTree
-	keepVersionNum int64
-	log            log.Logger
+	db            dbm.DB
+	tree          *iavl.MutableTree
+	versionOffset int64
+	log           log.Logger
 }
 
-func NewIavlStore(db dbm.DB, cacheSize int, keepVersionNum int64, logStore log.Logger) *IavlStore {
+func NewIavlStore(db dbm.DB, cacheSize int, logStore log.Logger) *IavlStore {
 	if db == nil {
 		panic("can't create IvalStore, db nil")
 	}
@@ -33,7 +36,7 @@ func NewIavlStore(db dbm.DB, cacheSize int, keepVersionNum int64, logStore log.L
 		panic("create MutableTree failed")
 	}
 
-	return &IavlStore{tree:tree, keepVersionNum: keepVersionNum, log: logStore}
+	return &IavlStore{db: db, tree:tree, log: logStore}
 }
 
 func (s *IavlStore) Set(key []byte, value []byte) bool {
@@ -57,7 +60,7 @@ func (s *IavlStore) GetWithVersionProve(key []byte, ver int64, prove bool) ([]by
  			value, err := s.Get(key)
 			return value, nil, err
 		} else {
-			ver = s.tree.Version()
+			ver = s.Version()
 		}
 	}
 
@@ -66,15 +69,15 @@ func (s *IavlStore) GetWithVersionProve(key []byte, ver int64, prove bool) ([]by
 		return nil, nil, errors.New("key is nil")
 	}
 
-	if !s.tree.VersionExists(ver) {
+	if !s.VersionExists(ver) {
 		return  nil, nil, iavl.ErrVersionDoesNotExist
 	}
 
 	var val []byte
 	if prove {
-		return s.tree.GetVersionedWithProof(key, ver)
+		return s.tree.GetVersionedWithProof(key, ver-s.versionOffset)
 	}else {
-		_, val = s.tree.GetVersioned(key, ver)
+		_, val = s.tree.GetVersioned(key, ver-s.versionOffset)
 	}
 
 	return val, nil, nil
@@ -88,36 +91,62 @@ func (s *IavlStore) Remove(key []byte) ([]byte, bool) {
 	return s.tree.Remove(key)
 }
 
+// IterateRange iterates the keys in [start, end) of the working tree
+func (s *IavlStore) IterateRange(start, end []byte, ascending bool, fn func(key []byte, value []byte) bool) bool {
+	return s.tree.IterateRange(start, end, ascending, fn)
+}
+
 func (s *IavlStore) Commit() (ankrcmm.CommitID, error) {
 	rHash, ver, err := s.tree.SaveVersion()
 	if err != nil {
 		panic(err)
 	}
 
-	if ver > s.keepVersionNum  && s.keepVersionNum > 0{
-		err = s.tree.DeleteVersion(ver-s.keepVersionNum)
-		if err != nil {
-			panic(err)
-		}
-	}
-
-	return ankrcmm.CommitID{ver, rHash}, nil
+	return ankrcmm.CommitID{ver+s.versionOffset, rHash}, nil
 }
 
 func (s *IavlStore) LatestVersion() ankrcmm.CommitID {
-	ver   :=  s.tree.Version()
+	ver   :=  s.Version()
 	rHash := s.tree.Hash()
 
 	return ankrcmm.CommitID{ver, rHash}
 }
 
+func (s *IavlStore) Version() int64 {
+	return s.tree.Version() + s.versionOffset
+}
+
+func (s *IavlStore) VersionExists(ver int64) bool {
+	return s.tree.VersionExists(ver - s.versionOffset)
+}
+
+func (s *IavlStore) DeleteVersion(ver int64) error {
+	return s.tree.DeleteVersion(ver - s.versionOffset)
+}
+
+func (s *IavlStore) GetImmutable(ver int64) (*iavl.ImmutableTree, error) {
+	return s.tree.GetImmutable(ver - s.versionOffset)
+}
+
+func (s *IavlStore) setVersionOffset(versionOffset int64) {
+	s.versionOffset = versionOffset
+}
+
 func (s *IavlStore) LoadVersion(ver int64) (int64, error) {
-	return s.tree.LoadVersion(ver)
+	loadedVer, err := s.tree.LoadVersion(ver - s.versionOffset)
+	return loadedVer + s.versionOffset, err
+}
+
+// LoadVersionForOverwriting loads the version and deletes the versions after it
+func (s *IavlStore) LoadVersionForOverwriting(ver int64) (int64, error) {
+	loadedVer, err := s.tree.LoadVersionForOverwriting(ver - s.versionOffset)
+	return loadedVer + s.versionOffset, err
 }
 
 // Load the latest versioned tree from disk.
 func (s *IavlStore) Load() (int64, error) {
-	return s.tree.Load()
+	ver, err := s.tree.Load()
+	return ver + s.versionOffset, err
 }
 
 func (s *IavlStore) Rollback() {
@@ -128,8 +157,8 @@ func (s *IavlStore) Rollback() {
 func (s *IavlStore) getHeight(reqHeight int64) int64 {
 	height := reqHeight
 	if height == 0 {
-		latest := s.tree.Version()
-		if s.tree.VersionExists(latest - 1) {
+		latest := s.Version()
+		if s.VersionExists(latest - 1) {
 			height = latest - 1
 		} else {
 			height = latest
@@ -156,13 +185,13 @@ func (s *IavlStore) Query(reqQuery types.RequestQuery) (resQuery types.ResponseQ
 		key := reqQuery.Data // data holds the key bytes
 
 		resQuery.Key = key
-		if !s.tree.VersionExists(resQuery.Height) {
+		if !s.VersionExists(resQuery.Height) {
 			resQuery.Log = iavl.ErrVersionDoesNotExist.Error()
 			break
 		}
 
 		if reqQuery.Prove {
-			value, proof, err := tree.GetVersionedWithProof(key, resQuery.Height)
+			value, proof, err := tree.GetVersionedWithProof(key, resQuery.Height-s.versionOffset)
 			if err != nil {
 				resQuery.Log = err.Error()
 				break
@@ -183,7 +212,7 @@ func (s *IavlStore) Query(reqQuery types.RequestQuery) (resQuery types.ResponseQ
 				resQuery.Proof = &merkle.Proof{Ops: []merkle.ProofOp{iavl.NewIAVLAbsenceOp(key, proof).ProofOp()}}
 			}
 		} else {
-			_, resQuery.Value = tree.GetVersioned(key, resQuery.Height)
+			_, resQuery.Value = tree.GetVersioned(key, resQuery.Height-s.versionOffset)
 		}
 	default:
 		resQuery.Code = code.CodeTypeUnknownError
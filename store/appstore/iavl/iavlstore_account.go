@@ -14,6 +14,7 @@ const (
 	StoreAccountPrefix      = "accstore:"
 	StoreAccountAllowPrefix = "accallowstore:"
 	StoreValidatorPrefix    = "valstore:"
+	StoreMultiSigPrefix     = "msigstore:"
 )
 
 func containAccountPrefix(address string) string {
@@ -28,6 +29,10 @@ func containValidatorPrefix(address string) string {
 	return containPrefix(address, StoreValidatorPrefix)
 }
 
+func containMultiSigPrefix(address string) string {
+	return containPrefix(address, StoreMultiSigPrefix)
+}
+
 func stripAccountKeyPrefix(key string) (string, error) {
 	return stripKeyPrefix(key, StoreAccountPrefix)
 }
@@ -65,28 +70,28 @@ func (sp *IavlStoreApp) InitFoundAccount() {
 }
 
 func (sp *IavlStoreApp) addAccountInfo(accInfo *ankrcmm.AccountInfo) {
-	if sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(accInfo.Address))) {
+	if sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(accInfo.Address))) {
 		return
 	}
 
 	bytes := account.EncodeAccount(sp.cdc, accInfo)
 
-	sp.iavlSM.storeMap[IavlStoreAccountKey].Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
+	sp.kvStore(IavlStoreAccountKey).Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
 }
 
 func (sp *IavlStoreApp) updateOnce(address string, nonce uint64) error {
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		return fmt.Errorf("can't find the respond account from store: address=%s", address)
 	}
 
-	accBytes, _ := sp.iavlSM.storeMap[IavlStoreAccountKey].Get([]byte(containAccountPrefix(address)))
+	accBytes, _ := sp.kvStore(IavlStoreAccountKey).Get([]byte(containAccountPrefix(address)))
 	accInfo := account.DecodeAccount(sp.cdc, accBytes)
 
 	accInfo.Nonce = nonce
 
 	bytes := account.EncodeAccount(sp.cdc, &accInfo)
 
-	updated := sp.iavlSM.storeMap[IavlStoreAccountKey].Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
+	updated := sp.kvStore(IavlStoreAccountKey).Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
 	if !updated {
 		return fmt.Errorf("update account's nonce fail: address=%s", address)
 	}
@@ -95,18 +100,18 @@ func (sp *IavlStoreApp) updateOnce(address string, nonce uint64) error {
 }
 
 func (sp *IavlStoreApp) updatePubKey(address string, pubKey string) error {
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(address)) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(address)) {
 		return fmt.Errorf("can't find the respond account from store: address=%s", address)
 	}
 
-	accBytes, _ := sp.iavlSM.storeMap[IavlStoreAccountKey].Get([]byte(containAccountPrefix(address)))
+	accBytes, _ := sp.kvStore(IavlStoreAccountKey).Get([]byte(containAccountPrefix(address)))
 	accInfo := account.DecodeAccount(sp.cdc, accBytes)
 
 	accInfo.PubKey = pubKey
 
 	bytes := account.EncodeAccount(sp.cdc, &accInfo)
 
-	updated := sp.iavlSM.storeMap[IavlStoreAccountKey].Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
+	updated := sp.kvStore(IavlStoreAccountKey).Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
 	if !updated {
 		return fmt.Errorf("update account's nonce fail: address=%s", address)
 	}
@@ -115,11 +120,11 @@ func (sp *IavlStoreApp) updatePubKey(address string, pubKey string) error {
 }
 
 func (sp *IavlStoreApp) updateBalance(address string, assert ankrcmm.Amount) error {
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		return fmt.Errorf("can't find the respond account from store: address=%s", address)
 	}
 
-	accBytes, _ := sp.iavlSM.storeMap[IavlStoreAccountKey].Get([]byte(containAccountPrefix(address)))
+	accBytes, _ := sp.kvStore(IavlStoreAccountKey).Get([]byte(containAccountPrefix(address)))
 	accInfo := account.DecodeAccount(sp.cdc, accBytes)
 
 	findAcc := false
@@ -138,7 +143,7 @@ func (sp *IavlStoreApp) updateBalance(address string, assert ankrcmm.Amount) err
 
 	bytes := account.EncodeAccount(sp.cdc, &accInfo)
 
-	updated := sp.iavlSM.storeMap[IavlStoreAccountKey].Set([]byte(containAccountPrefix(address)), bytes)
+	updated := sp.kvStore(IavlStoreAccountKey).Set([]byte(containAccountPrefix(address)), bytes)
 	if !updated {
 		return fmt.Errorf("update account's nonce fail: address=%s", address)
 	}
@@ -151,11 +156,11 @@ func (sp *IavlStoreApp) GetAssert(address string, symbol string, height int64, p
 		return nil, "", nil, nil, errors.New("GetAssert, blank address")
 	}
 
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		return nil, containAccountPrefix(address), nil, nil, fmt.Errorf("can't find the respond account from store: address=%s", address)
 	}
 
-	accBytes, proof, err := sp.iavlSM.storeMap[IavlStoreAccountKey].GetWithVersionProve([]byte(containAccountPrefix(address)), height, prove)
+	accBytes, proof, err := sp.kvStore(IavlStoreAccountKey).GetWithVersionProve([]byte(containAccountPrefix(address)), height, prove)
 	if err != nil {
 		return nil,containAccountPrefix(address), nil, nil, err
 	}
@@ -184,12 +189,12 @@ func (sp *IavlStoreApp) Nonce(address string, height int64, prove bool) (uint64,
 		return 0, "", nil, nil, errors.New("Nonce, blank address")
 	}
 
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		sp.AddAccount(address, ankrcmm.AccountGenesis)
 		return 0, containAccountPrefix(address), nil, nil, nil
 	}
 
-	accBytes, proof, err := sp.iavlSM.storeMap[IavlStoreAccountKey].GetWithVersionProve([]byte(containAccountPrefix(address)), height, prove)
+	accBytes, proof, err := sp.kvStore(IavlStoreAccountKey).GetWithVersionProve([]byte(containAccountPrefix(address)), height, prove)
 	if err != nil {
 		return 0, containAccountPrefix(address), nil, nil, err
 	}
@@ -199,39 +204,39 @@ func (sp *IavlStoreApp) Nonce(address string, height int64, prove bool) (uint64,
 }
 
 func (sp *IavlStoreApp) SetNonce(address string, nonce uint64) error {
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		return fmt.Errorf("can't find the respond account from store: address=%s", address)
 	}
 
-	accBytes, _ := sp.iavlSM.storeMap[IavlStoreAccountKey].Get([]byte(containAccountPrefix(address)))
+	accBytes, _ := sp.kvStore(IavlStoreAccountKey).Get([]byte(containAccountPrefix(address)))
 	accInfo := account.DecodeAccount(sp.cdc, accBytes)
 	accInfo.Nonce = nonce
 
 	bytes := account.EncodeAccount(sp.cdc, &accInfo)
 
-	sp.iavlSM.storeMap[IavlStoreAccountKey].Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
+	sp.kvStore(IavlStoreAccountKey).Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
 
 	return nil
 }
 
 func (sp *IavlStoreApp) IncNonce(address string) (uint64, error) {
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		return 0, fmt.Errorf("can't find the respond account from store: address=%s", address)
 	}
 
-	accBytes, _ := sp.iavlSM.storeMap[IavlStoreAccountKey].Get([]byte(containAccountPrefix(address)))
+	accBytes, _ := sp.kvStore(IavlStoreAccountKey).Get([]byte(containAccountPrefix(address)))
 	accInfo := account.DecodeAccount(sp.cdc, accBytes)
 	accInfo.Nonce++
 
 	bytes := account.EncodeAccount(sp.cdc, &accInfo)
 
-	sp.iavlSM.storeMap[IavlStoreAccountKey].Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
+	sp.kvStore(IavlStoreAccountKey).Set([]byte(containAccountPrefix(accInfo.Address)), bytes)
 
 	return accInfo.Nonce, nil
 }
 
 func (sp *IavlStoreApp) AddAccount(address string, accType ankrcmm.AccountType) {
-	if !sp.iavlSM.IavlStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		var accInfo ankrcmm.AccountInfo
 		accInfo.AccType = accType
 		accInfo.Nonce   = 1
@@ -248,8 +253,8 @@ func (sp *IavlStoreApp) AccountQuery(address string, height int64, prove bool) (
 		return nil, "", nil, errors.New("AccountQuery, blank address")
 	}
 
-	if sp.iavlSM.IavlStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
-		accBytes, proof, err := sp.iavlSM.storeMap[IavlStoreAccountKey].GetWithVersionProve([]byte(containAccountPrefix(address)), height, prove)
+	if sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
+		accBytes, proof, err := sp.kvStore(IavlStoreAccountKey).GetWithVersionProve([]byte(containAccountPrefix(address)), height, prove)
 		if err != nil {
 			return nil, containAccountPrefix(address), nil, err
 		}
@@ -275,7 +280,7 @@ func (sp *IavlStoreApp) AccountQuery(address string, height int64, prove bool) (
 }
 
 func (sp *IavlStoreApp) SetBalance(address string, amount ankrcmm.Amount) {
-	if !sp.iavlSM.IavlStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		var accInfo ankrcmm.AccountInfo
 		accInfo.AccType = ankrcmm.AccountGeneral
 		accInfo.Nonce   = 1
@@ -314,18 +319,18 @@ func (sp *IavlStoreApp) BalanceQuery(address string, symbol string, height int64
 
 func (sp *IavlStoreApp) SetAllowance(addrSender string, addrSpender string, amount ankrcmm.Amount) {
 	key := containAccountAllowPrefix(addrSender, addrSpender, amount.Cur.Symbol)
-	if !sp.iavlSM.IavlStore(IavlStoreAccountKey).Has([]byte(key)) {
-		sp.iavlSM.IavlStore(IavlStoreAccountKey).Set([]byte(key), amount.Value)
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(key)) {
+		sp.kvStore(IavlStoreAccountKey).Set([]byte(key), amount.Value)
 	}
 }
 
 func (sp *IavlStoreApp) Allowance(addrSender string, addrSpender string, symbol string) (*big.Int, error){
 	key := containAccountAllowPrefix(addrSender, addrSpender, symbol)
-	if !sp.iavlSM.IavlStore(IavlStoreAccountKey).Has([]byte(key)) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(key)) {
 		return nil, fmt.Errorf("IavlStoreApp Allowance not exist key: key=%s", key)
 	}
 
-	val, err := sp.iavlSM.IavlStore(IavlStoreAccountKey).Get([]byte(key))
+	val, err := sp.kvStore(IavlStoreAccountKey).Get([]byte(key))
 	if err != nil {
 		return nil, fmt.Errorf("IavlStoreApp Allowance get key err: key=%s, err=%v", key, err)
 	}
@@ -341,7 +346,7 @@ func (sp *IavlStoreApp) AccountList(height int64) ([]string, uint64) {
 
 	tree := sp.iavlSM.storeMap[IavlStoreAccountKey].tree.ImmutableTree
 	if height > 0 {
-		tree, _ = sp.iavlSM.storeMap[IavlStoreAccountKey].tree.GetImmutable(height)
+		tree, _ = sp.iavlSM.storeMap[IavlStoreAccountKey].GetImmutable(height)
 	}
 
 	endBytes := prefixEndBytes([]byte(StoreAccountPrefix))
@@ -373,11 +378,11 @@ func (sp *IavlStoreApp) AddBoundRole(address string, roleName string) {
 		return
 	}
 
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		return
 	}
 
-	accBytes, err := sp.iavlSM.storeMap[IavlStoreAccountKey].Get([]byte(containAccountPrefix(address)))
+	accBytes, err := sp.kvStore(IavlStoreAccountKey).Get([]byte(containAccountPrefix(address)))
 	if err != nil || accBytes == nil{
 		return
 	}
@@ -397,11 +402,11 @@ func (sp *IavlStoreApp) LoadBoundRoles(address string) ([]string, error){
 		return nil, errors.New("can't load bound roles, blank address")
 	}
 
-	if !sp.iavlSM.storeMap[IavlStoreAccountKey].Has([]byte(containAccountPrefix(address))) {
+	if !sp.kvStore(IavlStoreAccountKey).Has([]byte(containAccountPrefix(address))) {
 		return nil, fmt.Errorf("can't load bound roles, address=%s", address)
 	}
 
-	accBytes, err := sp.iavlSM.storeMap[IavlStoreAccountKey].Get([]byte(containAccountPrefix(address)))
+	accBytes, err := sp.kvStore(IavlStoreAccountKey).Get([]byte(containAccountPrefix(address)))
 	if err != nil || accBytes == nil{
 		errStr := ""
 		if err != nil {
@@ -414,3 +419,56 @@ func (sp *IavlStoreApp) LoadBoundRoles(address string) ([]string, error){
 
 	return accInfo.Roles, nil
 }
+
+func (sp *IavlStoreApp) SetMultiSigAccount(msInfo *ankrcmm.MultiSigAccountInfo) {
+	if msInfo == nil || msInfo.Address == "" {
+		return
+	}
+
+	bytes := account.EncodeMultiSigAccount(sp.cdc, msInfo)
+
+	sp.kvStore(IavlStoreAccountKey).Set([]byte(containMultiSigPrefix(msInfo.Address)), bytes)
+}
+
+func (sp *IavlStoreApp) MultiSigAccount(address string, height int64, prove bool) (*ankrcmm.MultiSigAccountInfo, string, *iavl.RangeProof, []byte, error) {
+	if address == "" {
+		return nil, "", nil, nil, errors.New("MultiSigAccount, blank address")
+	}
+
+	msBytes, proof, err := sp.kvStore(IavlStoreAccountKey).GetWithVersionProve([]byte(containMultiSigPrefix(address)), height, prove)
+	if err != nil {
+		return nil, containMultiSigPrefix(address), nil, nil, err
+	}
+
+	if len(msBytes) == 0 {
+		return nil, containMultiSigPrefix(address), proof, nil, nil
+	}
+
+	msInfo := account.DecodeMultiSigAccount(sp.cdc, msBytes)
+
+	return &msInfo, containMultiSigPrefix(address), proof, msBytes, nil
+}
+
+func (sp *IavlStoreApp) MultiSigAccountQuery(address string, height int64, prove bool) (*ankrcmm.QueryResp, string, *iavl.RangeProof, error) {
+	msInfo, storeKey, proof, proofVal, err := sp.MultiSigAccount(address, height, prove)
+	if err != nil {
+		return nil, storeKey, proof, err
+	}
+
+	if msInfo == nil {
+		return nil, storeKey, proof, fmt.Errorf("there is no responding multisig account info: addr=%s", address)
+	}
+
+	msRespInfo := &ankrcmm.MultiSigAccountQueryResp{
+		Address:   msInfo.Address,
+		Threshold: msInfo.Threshold,
+		PubKeys:   msInfo.PubKeys,
+	}
+
+	respData, err := sp.cdc.MarshalJSON(msRespInfo)
+	if err != nil {
+		return nil, storeKey, proof, err
+	}
+
+	return &ankrcmm.QueryResp{RespData: respData, ProofValue: proofVal}, storeKey, proof, nil
+}
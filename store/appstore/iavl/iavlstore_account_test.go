@@ -48,3 +48,22 @@ func TestBalance(t *testing.T) {
 	assert.Equal(t, err, nil)
 	assert.Equal(t, bal.String(), "1000")
 }
+
+func TestMultiSigAccount(t *testing.T) {
+	storeApp := NewMockIavlStoreApp()
+
+	msInfo, _, _, _, err := storeApp.MultiSigAccount("5AEBA6EB8BC51DA277CCF1EF229F0C05D9535FA36CC872", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Nil(t, msInfo)
+
+	storeApp.SetMultiSigAccount(&ankrcmm.MultiSigAccountInfo{
+		Address:   "5AEBA6EB8BC51DA277CCF1EF229F0C05D9535FA36CC872",
+		Threshold: 2,
+		PubKeys:   []string{"sxhP4F6OLZKNPQ2lG13WcHzitNX9++h56cppBDhwMlI=", "trwr09Y8sqIdg2H7vhJFsf4aBowBzqkMOjzAGu2ZF6E=", "dBCzB+l/WYxqk+i54a4addy1XhiIK5t0IAZ5OKtegWY="},
+	})
+
+	msInfo, _, _, _, err = storeApp.MultiSigAccount("5AEBA6EB8BC51DA277CCF1EF229F0C05D9535FA36CC872", 0, false)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, msInfo.Threshold, uint32(2))
+	assert.Equal(t, len(msInfo.PubKeys), 3)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -13,6 +14,7 @@ import (
 	ankrcmm "github.com/Ankr-network/ankr-chain/common"
 	"github.com/Ankr-network/ankr-chain/common/code"
 	ankrapscmm "github.com/Ankr-network/ankr-chain/store/appstore/common"
+	"github.com/Ankr-network/ankr-chain/upgrade"
 	"github.com/tendermint/go-amino"
 	"github.com/tendermint/iavl"
 	"github.com/tendermint/tendermint/abci/types"
@@ -24,6 +26,7 @@ import (
 const (
 	ChainIDKey = "chainidkey"
 	TotalTxKey = "totaltxkey"
+	MaxBlockGasKey = "maxblockgaskey"
 )
 
 const (
@@ -32,6 +35,8 @@ const (
 	StoreContractInfoPrefix = "continfo:"
 	StoreContractCurrencyPrefix = "contcur:"
 	StoreCurrencyPrefix = "cur:"
+	StoreMinGasPricePrefix = "mgasprice:"
+	StoreFeeCurrencyPrefix = "feecur:"
 )
 
 //type storeQueryHandler func(store *IavlStoreApp, reqData []byte) (resQuery types.ResponseQuery)
@@ -46,6 +51,8 @@ type IavlStoreApp struct {
 	queryHandleMap  map[string]*storeQueryHandler
 	accStoreLocker  sync.RWMutex
 	certStoreLocker sync.RWMutex
+	parent          *IavlStoreApp
+	cacheStores     map[string]*cacheKVStore
 }
 
 func containCertKeyPrefix(dcnsName string) string {
@@ -68,6 +75,14 @@ func containCurrencyPrefix(symbol string) string {
 	return containPrefix(symbol, StoreCurrencyPrefix)
 }
 
+func containMinGasPricePrefix(symbol string) string {
+	return containPrefix(symbol, StoreMinGasPricePrefix)
+}
+
+func containFeeCurrencyPrefix(symbol string) string {
+	return containPrefix(symbol, StoreFeeCurrencyPrefix)
+}
+
 func stripCertKeyPrefix(key string) (string, error) {
 	return stripKeyPrefix(key, StoreCertKeyPrefix)
 }
@@ -79,21 +94,28 @@ type storeQueryHandler struct {
 }
 
 func NewIavlStoreApp(dbDir string, storeLog log.Logger) *IavlStoreApp {
-	kvPath := filepath.Join(dbDir, "kvstore.db")
-	isKVPathExist, err := ankrcmm.PathExists(kvPath)
-	if err != nil {
-		panic(err)
-	}
+	return NewIavlStoreAppWithBackend(dbDir, DefaultDBBackend, storeLog)
+}
 
+// NewIavlStoreAppWithBackend creates the app store on the db backend, see NewAppDB for the backends supported
+func NewIavlStoreAppWithBackend(dbDir string, dbBackend string, storeLog log.Logger) *IavlStoreApp {
 	var kvState ankrapscmm.State
-	if isKVPathExist {
-		kvDB, err := dbm.NewGoLevelDB("kvstore", dbDir)
+	if dbBackend != string(dbm.MemDBBackend) {
+		kvPath := filepath.Join(dbDir, "kvstore.db")
+		isKVPathExist, err := ankrcmm.PathExists(kvPath)
 		if err != nil {
 			panic(err)
 		}
-		kvState = ankrapscmm.LoadState(kvDB)
 
-		os.RemoveAll(kvPath)
+		if isKVPathExist {
+			kvDB, err := dbm.NewGoLevelDB("kvstore", dbDir)
+			if err != nil {
+				panic(err)
+			}
+			kvState = ankrapscmm.LoadState(kvDB)
+
+			os.RemoveAll(kvPath)
+		}
 	}
 
 	//appStorePath := filepath.Join(dbDir, "appstore.db")
@@ -101,7 +123,7 @@ func NewIavlStoreApp(dbDir string, storeLog log.Logger) *IavlStoreApp {
 
 	var lcmmID ankrcmm.CommitID
 
-	db, err := dbm.NewGoLevelDB("appstore", dbDir)
+	db, err := NewAppDB(dbBackend, dbDir)
 	if err != nil {
 		panic(err)
 	}
@@ -116,7 +138,8 @@ func NewIavlStoreApp(dbDir string, storeLog log.Logger) *IavlStoreApp {
 		fmt.Printf("theLastVersion'hash=%X\n", iavlSM.commitInfo(lcmmID.Version - 1).AppHash)
 	}
 
-	iavlSM.Load()
+	// the versions saved by the commit interrupted last time are discarded, so the block is replayed on the committed state
+	iavlSM.LoadForWriting()
 
 	iavlSApp := &IavlStoreApp{iavlSM: iavlSM, lastCommitID: lcmmID, storeLog: storeLog, cdc: amino.NewCodec(), kvState: kvState}
 
@@ -155,32 +178,17 @@ func NewIavlStoreApp(dbDir string, storeLog log.Logger) *IavlStoreApp {
 	iavlSApp.queryHandleMap["account"]          = &storeQueryHandler{IavlStoreAccountKey, &ankrcmm.AccountQueryReq{}, iavlSApp.AccountQuery}
 	iavlSApp.queryHandleMap["currency"]         = &storeQueryHandler{IAvlStoreContractKey, &ankrcmm.CurrencyQueryReq{}, iavlSApp.CurrencyInfoQuery}
 	iavlSApp.queryHandleMap["statisticalinfo"] = &storeQueryHandler{IAvlStoreMainKey, &ankrcmm.StatisticalInfoReq{}, iavlSApp.StatisticalInfoQuery}
+	iavlSApp.queryHandleMap["multisigaccount"] = &storeQueryHandler{IavlStoreAccountKey, &ankrcmm.MultiSigAccountQueryReq{}, iavlSApp.MultiSigAccountQuery}
+	iavlSApp.queryHandleMap["mingasprice"]     = &storeQueryHandler{IAvlStoreMainKey, &ankrcmm.MinGasPriceQueryReq{}, iavlSApp.MinGasPriceQuery}
+	iavlSApp.queryHandleMap["feecurrency"]     = &storeQueryHandler{IAvlStoreMainKey, &ankrcmm.FeeCurrencyQueryReq{}, iavlSApp.FeeCurrencyQuery}
+	iavlSApp.queryHandleMap["upgradeplan"]     = &storeQueryHandler{IAvlStoreMainKey, &ankrcmm.UpgradePlanQueryReq{}, iavlSApp.UpgradePlanQuery}
 
 	return iavlSApp
 }
 
+// NewMockIavlStoreApp creates the app store on the memdb for the tests
 func NewMockIavlStoreApp() *IavlStoreApp {
-	db := dbm.NewMemDB()
-	storeLog := log.NewNopLogger()
-
-	iavlSM := NewIavlStoreMulti(db, storeLog)
-	lcmmID := iavlSM.lastCommit()
-
-	iavlSApp := &IavlStoreApp{iavlSM: iavlSM, lastCommitID: lcmmID, storeLog: storeLog, cdc: amino.NewCodec()}
-
-	iavlSApp.queryHandleMap = make(map[string]*storeQueryHandler)
-
-	iavlSApp.queryHandleMap["nonce"]            = &storeQueryHandler{IavlStoreAccountKey, &ankrcmm.NonceQueryReq{},    iavlSApp.NonceQuery}
-	iavlSApp.queryHandleMap["balance"]          = &storeQueryHandler{IavlStoreAccountKey, &ankrcmm.BalanceQueryReq{},  iavlSApp.BalanceQuery}
-	iavlSApp.queryHandleMap["certkey"]          = &storeQueryHandler{IAvlStoreMainKey, &ankrcmm.CertKeyQueryReq{},  iavlSApp.CertKeyQuery}
-	iavlSApp.queryHandleMap["metering"]         = &storeQueryHandler{IAvlStoreMainKey, &ankrcmm.MeteringQueryReq{}, iavlSApp.MeteringQuery}
-	iavlSApp.queryHandleMap["validator"]        = &storeQueryHandler{IAvlStoreMainKey, &ankrcmm.ValidatorQueryReq{},iavlSApp.ValidatorQuery}
-	iavlSApp.queryHandleMap["contract"]         = &storeQueryHandler{IAvlStoreContractKey, &ankrcmm.ContractQueryReq{}, iavlSApp.LoadContractQuery}
-	iavlSApp.queryHandleMap["account"]          = &storeQueryHandler{IavlStoreAccountKey, &ankrcmm.AccountQueryReq{}, iavlSApp.AccountQuery}
-	iavlSApp.queryHandleMap["currency"]         = &storeQueryHandler{IAvlStoreContractKey, &ankrcmm.CurrencyQueryReq{}, iavlSApp.CurrencyInfoQuery}
-	iavlSApp.queryHandleMap["statisticalinfo"] = &storeQueryHandler{IAvlStoreMainKey, &ankrcmm.StatisticalInfoReq{}, iavlSApp.StatisticalInfoQuery}
-
-	return  &IavlStoreApp{iavlSM: iavlSM, lastCommitID: lcmmID, storeLog: storeLog, cdc: amino.NewCodec()}
+	return NewIavlStoreAppWithBackend("", string(dbm.MemDBBackend), log.NewNopLogger())
 }
 
 func (sp* IavlStoreApp) queryHandlerWapper(queryKey string, reqData []byte, height int64, prove bool) (resQuery types.ResponseQuery, storeKey string, proof *iavl.RangeProof) {
@@ -239,11 +247,11 @@ func (sp* IavlStoreApp) queryHandlerWapper(queryKey string, reqData []byte, heig
 }
 
 func (sp *IavlStoreApp) SetChainID(chainID string) {
-	sp.iavlSM.IavlStore(IAvlStoreMainKey).Set([]byte(ChainIDKey), []byte(chainID))
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(ChainIDKey), []byte(chainID))
 }
 
 func (sp *IavlStoreApp) ChainID() string {
-	chainIDBytes, err := sp.iavlSM.IavlStore(IAvlStoreMainKey).Get([]byte(ChainIDKey))
+	chainIDBytes, err := sp.kvStore(IAvlStoreMainKey).Get([]byte(ChainIDKey))
 	if err != nil || chainIDBytes == nil{
 		return ""
 	}
@@ -251,12 +259,26 @@ func (sp *IavlStoreApp) ChainID() string {
 	return string(chainIDBytes)
 }
 
+// LastCommitState returns the state persisted atomically with the latest commit
+func (sp *IavlStoreApp) LastCommitState() ankrcmm.CommitState {
+	state := sp.iavlSM.lastCommitState()
+	if state.ChainID == "" {
+		state.ChainID = sp.ChainID()
+	}
+
+	return state
+}
+
 func (sp *IavlStoreApp) LastCommit() *ankrcmm.CommitID{
 	return &sp.lastCommitID
 }
 
 func (sp *IavlStoreApp) Commit() types.ResponseCommit {
-    commitID := sp.iavlSM.Commit(sp.lastCommitID.Version, sp.totalTx)
+	if sp.cacheStores != nil {
+		panic("can't commit a branch of the app store")
+	}
+
+    commitID := sp.iavlSM.Commit(sp.lastCommitID.Version, sp.totalTx, sp.UpgradeDoneHeight(upgrade.UpgradeStoreAppHash) > 0)
 
 	sp.lastCommitID.Hash = sp.lastCommitID.Hash[0:0]
 
@@ -291,6 +313,16 @@ func (sp *IavlStoreApp) Query(reqQuery types.RequestQuery) (resQuery types.Respo
 		return
 	}
 
+	if reqQuery.Height > 0 && sp.iavlSM.VersionPruned(reqQuery.Height) {
+		resQuery.Log  = fmt.Sprintf("height %d has been pruned", reqQuery.Height)
+		resQuery.Code = code.CodeTypeHeightPruned
+		return
+	}
+
+	if reqQuery.Path == RangeQueryPath {
+		return sp.rangeQuery(reqQuery)
+	}
+
 	resQuery, storeKey, proof := sp.queryHandlerWapper(reqQuery.Path, reqQuery.Data, reqQuery.Height, reqQuery.Prove)
 
 	if reqQuery.Height == 0 {
@@ -329,7 +361,7 @@ func (sp *IavlStoreApp) Query(reqQuery types.RequestQuery) (resQuery types.Respo
 
 func (sp *IavlStoreApp) SetCertKey(dcName string, pemBase64 string)  {
 	key := []byte(containCertKeyPrefix(dcName))
-	sp.iavlSM.IavlStore(IAvlStoreMainKey).Set(key, []byte(pemBase64))
+	sp.kvStore(IAvlStoreMainKey).Set(key, []byte(pemBase64))
 }
 
 func (sp *IavlStoreApp) CertKey(dcName string, height int64, prove bool)(string, string, *iavl.RangeProof, []byte) {
@@ -339,7 +371,7 @@ func (sp *IavlStoreApp) CertKey(dcName string, height int64, prove bool)(string,
 	}
 
 	key := []byte(containCertKeyPrefix(dcName))
-	valBytes, proof, err :=  sp.iavlSM.IavlStore(IAvlStoreMainKey).GetWithVersionProve(key, height, prove)
+	valBytes, proof, err :=  sp.kvStore(IAvlStoreMainKey).GetWithVersionProve(key, height, prove)
 	if err != nil {
 		sp.storeLog.Error("can't get the key's value", "dcName", dcName)
 		return "", containCertKeyPrefix(dcName), nil, nil
@@ -360,7 +392,7 @@ func (sp *IavlStoreApp) CertKeyQuery(dcName string, height int64, prove bool) (*
 
 func (sp *IavlStoreApp) DeleteCertKey(dcName string) {
 	key := []byte(containCertKeyPrefix(dcName))
-	sp.iavlSM.IavlStore(IAvlStoreMainKey).Remove(key)
+	sp.kvStore(IAvlStoreMainKey).Remove(key)
 }
 
 func (sp *IavlStoreApp) CertKeyList() ([]byte, uint64) {
@@ -369,7 +401,7 @@ func (sp *IavlStoreApp) CertKeyList() ([]byte, uint64) {
 
 	endBytes := prefixEndBytes([]byte(StoreCertKeyPrefix))
 
-	sp.iavlSM.storeMap[IAvlStoreMainKey].tree.IterateRange([]byte(StoreCertKeyPrefix), endBytes, true, func(key []byte, value []byte) bool{
+	sp.kvStore(IAvlStoreMainKey).IterateRange([]byte(StoreCertKeyPrefix), endBytes, true, func(key []byte, value []byte) bool{
 		if len(key) >= len(StoreAccountPrefix) && string(key[0:len(StoreCertKeyPrefix)]) == StoreCertKeyPrefix {
 			dcnsName, err := stripCertKeyPrefix(string(key))
 			if err != nil {
@@ -393,7 +425,7 @@ func (sp *IavlStoreApp) CertKeyList() ([]byte, uint64) {
 
 func (sp *IavlStoreApp) SetMetering(dcName string, nsName string, value string) {
 	key := []byte(containMeteringPrefix(dcName+"_"+nsName))
-	sp.iavlSM.IavlStore(IAvlStoreMainKey).Set(key, []byte(value))
+	sp.kvStore(IAvlStoreMainKey).Set(key, []byte(value))
 }
 
 func (sp *IavlStoreApp) Metering(dcName string, nsName string, height int64, prove bool) (string, string, *iavl.RangeProof, []byte) {
@@ -403,7 +435,7 @@ func (sp *IavlStoreApp) Metering(dcName string, nsName string, height int64, pro
 	}
 
 	key := []byte(containMeteringPrefix(dcName+"_"+nsName))
-	valueBytes, proof, err := sp.iavlSM.IavlStore(IAvlStoreMainKey).GetWithVersionProve(key, height, prove)
+	valueBytes, proof, err := sp.kvStore(IAvlStoreMainKey).GetWithVersionProve(key, height, prove)
 	if err != nil {
 		sp.storeLog.Error("can't get the responding metering value", "dcName", dcName, "nsName", nsName, "err", err)
 		return "", containMeteringPrefix(dcName+"_"+nsName), nil, nil
@@ -425,7 +457,7 @@ func (sp *IavlStoreApp) MeteringQuery(dcName string, nsName string, height int64
 func (sp *IavlStoreApp) SetValidator(valInfo *ankrcmm.ValidatorInfo) {
 	valBytes := ankrcmm.EncodeValidatorInfo(sp.cdc, valInfo)
 
-	sp.iavlSM.IavlStore(IAvlStoreMainKey).Set([]byte(containValidatorPrefix(valInfo.ValAddress)), valBytes)
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(containValidatorPrefix(valInfo.ValAddress)), valBytes)
 }
 
 func (sp *IavlStoreApp) Validator(valAddr string, height int64, prove bool) (*ankrcmm.ValidatorInfo, string, *iavl.RangeProof, []byte, error) {
@@ -433,7 +465,7 @@ func (sp *IavlStoreApp) Validator(valAddr string, height int64, prove bool) (*an
 		return nil, "", nil, nil, errors.New("Validator, blank valAddr")
 	}
 
-	valBytes, proof, err := sp.iavlSM.IavlStore(IAvlStoreMainKey).GetWithVersionProve([]byte(containValidatorPrefix(valAddr)), height, prove)
+	valBytes, proof, err := sp.kvStore(IAvlStoreMainKey).GetWithVersionProve([]byte(containValidatorPrefix(valAddr)), height, prove)
 	if err != nil {
 		return nil, containValidatorPrefix(valAddr), nil, nil, fmt.Errorf("can't get the responding validator info: valAddr=%s", valAddr)
 	}
@@ -468,7 +500,7 @@ func (sp *IavlStoreApp) ValidatorQuery(valAddr string, height int64, prove bool)
 }
 
 func (sp *IavlStoreApp) RemoveValidator(valAddr string) {
-	sp.iavlSM.IavlStore(IAvlStoreMainKey).Remove([]byte(containValidatorPrefix(valAddr)))
+	sp.kvStore(IAvlStoreMainKey).Remove([]byte(containValidatorPrefix(valAddr)))
 }
 
 func (sp *IavlStoreApp) TotalValidatorPowers() int64 {
@@ -476,7 +508,7 @@ func (sp *IavlStoreApp) TotalValidatorPowers() int64 {
 
 	endBytes := prefixEndBytes([]byte(StoreValidatorPrefix))
 
-	sp.iavlSM.storeMap[IAvlStoreMainKey].tree.IterateRange([]byte(StoreValidatorPrefix), endBytes, true, func(key []byte, value []byte) bool{
+	sp.kvStore(IAvlStoreMainKey).IterateRange([]byte(StoreValidatorPrefix), endBytes, true, func(key []byte, value []byte) bool{
 		if len(key) >= len(StoreValidatorPrefix) && string(key[0:len(StoreValidatorPrefix)]) == StoreValidatorPrefix {
 			valInfo := ankrcmm.DecodeValidatorInfo(sp.cdc, value)
 
@@ -490,7 +522,7 @@ func (sp *IavlStoreApp) TotalValidatorPowers() int64 {
 }
 
 func (sp *IavlStoreApp) Get(key []byte) []byte {
-	val, err := sp.iavlSM.IavlStore(IAvlStoreMainKey).Get(key)
+	val, err := sp.kvStore(IAvlStoreMainKey).Get(key)
 	if err != nil {
 		sp.storeLog.Error("can't get the key value", "key", string(key))
 		val = nil
@@ -500,15 +532,15 @@ func (sp *IavlStoreApp) Get(key []byte) []byte {
 }
 
 func (sp *IavlStoreApp) Set(key []byte, val []byte) {
-	sp.iavlSM.IavlStore(IAvlStoreMainKey).Set(key, val)
+	sp.kvStore(IAvlStoreMainKey).Set(key, val)
 }
 
 func (sp *IavlStoreApp) Delete(key []byte) {
-	sp.iavlSM.IavlStore(IAvlStoreMainKey).Remove(key)
+	sp.kvStore(IAvlStoreMainKey).Remove(key)
 }
 
 func (sp *IavlStoreApp) Has(key []byte) bool {
-	return sp.iavlSM.IavlStore(IAvlStoreMainKey).Has(key)
+	return sp.kvStore(IAvlStoreMainKey).Has(key)
 }
 
 func (sp *IavlStoreApp) Height() int64 {
@@ -520,8 +552,8 @@ func (sp *IavlStoreApp) TotalTx(height int64, prove bool) (int64, string, *iavl.
 		height = sp.Height()
 	}
 
-	if sp.iavlSM.storeMap[IAvlStoreMainKey].Has([]byte(TotalTxKey)) {
-		val, proof, err := sp.iavlSM.storeMap[IAvlStoreMainKey].GetWithVersionProve([]byte(TotalTxKey), height, prove)
+	if sp.kvStore(IAvlStoreMainKey).Has([]byte(TotalTxKey)) {
+		val, proof, err := sp.kvStore(IAvlStoreMainKey).GetWithVersionProve([]byte(TotalTxKey), height, prove)
 		if err != nil {
 			return 0, TotalTxKey, nil, nil, err
 		}
@@ -539,7 +571,7 @@ func (sp *IavlStoreApp) SetTotalTx(totalTx int64) {
 
 	buf := make([]byte, binary.MaxVarintLen64)
 	n := binary.PutVarint(buf, sp.totalTx)
-	sp.iavlSM.storeMap[IAvlStoreMainKey].Set([]byte(TotalTxKey), buf[:n])
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(TotalTxKey), buf[:n])
 }
 
 func (sp *IavlStoreApp) IncTotalTx() int64 {
@@ -547,11 +579,119 @@ func (sp *IavlStoreApp) IncTotalTx() int64 {
 
 	buf := make([]byte, binary.MaxVarintLen64)
 	n := binary.PutVarint(buf, sp.totalTx)
-	sp.iavlSM.storeMap[IAvlStoreMainKey].Set([]byte(TotalTxKey), buf[:n])
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(TotalTxKey), buf[:n])
 
 	return sp.totalTx
 }
 
+func (sp *IavlStoreApp) SetMinGasPrice(minGasPrice *ankrcmm.Amount) {
+	priceBytes, _ := sp.cdc.MarshalJSON(minGasPrice)
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(containMinGasPricePrefix(minGasPrice.Cur.Symbol)), priceBytes)
+}
+
+// MinGasPrice returns nil amount without error if the min gas price of the symbol hasn't been set
+func (sp *IavlStoreApp) MinGasPrice(symbol string, height int64, prove bool) (*ankrcmm.Amount, string, *iavl.RangeProof, []byte, error) {
+	if symbol == "" {
+		return nil, "", nil, nil, errors.New("MinGasPrice, blank symbol name")
+	}
+
+	priceBytes, proof, err := sp.kvStore(IAvlStoreMainKey).GetWithVersionProve([]byte(containMinGasPricePrefix(symbol)), height, prove)
+	if err != nil || len(priceBytes) == 0 {
+		return nil, containMinGasPricePrefix(symbol), proof, nil, err
+	}
+
+	var minGasPrice ankrcmm.Amount
+	err = sp.cdc.UnmarshalJSON(priceBytes, &minGasPrice)
+	if err != nil {
+		return nil, containMinGasPricePrefix(symbol), proof, priceBytes, err
+	}
+
+	return &minGasPrice, containMinGasPricePrefix(symbol), proof, priceBytes, nil
+}
+
+func (sp *IavlStoreApp) MinGasPriceQuery(symbol string, height int64, prove bool) (*ankrcmm.QueryResp, string, *iavl.RangeProof, error) {
+	minGasPrice, storeKey, proof, proofVal, err := sp.MinGasPrice(symbol, height, prove)
+	if err != nil {
+		return nil, storeKey, proof, err
+	}
+
+	if minGasPrice == nil {
+		return nil, storeKey, proof, fmt.Errorf("the min gas price of %s hasn't been set", symbol)
+	}
+
+	respData, err := sp.cdc.MarshalJSON(&ankrcmm.MinGasPriceQueryResp{minGasPrice.Cur.Symbol, new(big.Int).SetBytes(minGasPrice.Value).String()})
+	if err != nil {
+		return nil, storeKey, proof, err
+	}
+
+	return &ankrcmm.QueryResp{respData, proofVal}, storeKey, proof, nil
+}
+
+func (sp *IavlStoreApp) SetFeeCurrency(feeCurInfo *ankrcmm.FeeCurrencyInfo) {
+	feeCurBytes, _ := sp.cdc.MarshalJSON(feeCurInfo)
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(containFeeCurrencyPrefix(feeCurInfo.Symbol)), feeCurBytes)
+}
+
+// FeeCurrency returns nil info without error if the symbol isn't in the fee currency whitelist
+func (sp *IavlStoreApp) FeeCurrency(symbol string, height int64, prove bool) (*ankrcmm.FeeCurrencyInfo, string, *iavl.RangeProof, []byte, error) {
+	if symbol == "" {
+		return nil, "", nil, nil, errors.New("FeeCurrency, blank symbol name")
+	}
+
+	feeCurBytes, proof, err := sp.kvStore(IAvlStoreMainKey).GetWithVersionProve([]byte(containFeeCurrencyPrefix(symbol)), height, prove)
+	if err != nil || len(feeCurBytes) == 0 {
+		return nil, containFeeCurrencyPrefix(symbol), proof, nil, err
+	}
+
+	var feeCurInfo ankrcmm.FeeCurrencyInfo
+	err = sp.cdc.UnmarshalJSON(feeCurBytes, &feeCurInfo)
+	if err != nil {
+		return nil, containFeeCurrencyPrefix(symbol), proof, feeCurBytes, err
+	}
+
+	return &feeCurInfo, containFeeCurrencyPrefix(symbol), proof, feeCurBytes, nil
+}
+
+func (sp *IavlStoreApp) FeeCurrencyQuery(symbol string, height int64, prove bool) (*ankrcmm.QueryResp, string, *iavl.RangeProof, error) {
+	feeCurInfo, storeKey, proof, proofVal, err := sp.FeeCurrency(symbol, height, prove)
+	if err != nil {
+		return nil, storeKey, proof, err
+	}
+
+	if feeCurInfo == nil {
+		return nil, storeKey, proof, fmt.Errorf("%s isn't a fee currency", symbol)
+	}
+
+	respData, err := sp.cdc.MarshalJSON(&ankrcmm.FeeCurrencyQueryResp{feeCurInfo.Symbol, feeCurInfo.ExchangeRate})
+	if err != nil {
+		return nil, storeKey, proof, err
+	}
+
+	return &ankrcmm.QueryResp{respData, proofVal}, storeKey, proof, nil
+}
+
+func (sp *IavlStoreApp) DeleteFeeCurrency(symbol string) {
+	sp.kvStore(IAvlStoreMainKey).Remove([]byte(containFeeCurrencyPrefix(symbol)))
+}
+
+func (sp *IavlStoreApp) SetMaxBlockGas(maxBlockGas int64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(buf, maxBlockGas)
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(MaxBlockGasKey), buf[:n])
+}
+
+// MaxBlockGas returns -1 if the max block gas hasn't been set, which means unlimited
+func (sp *IavlStoreApp) MaxBlockGas() int64 {
+	maxBlockGasBytes, err := sp.kvStore(IAvlStoreMainKey).Get([]byte(MaxBlockGasKey))
+	if err != nil || len(maxBlockGasBytes) == 0 {
+		return -1
+	}
+
+	maxBlockGas, _ := binary.Varint(maxBlockGasBytes)
+
+	return maxBlockGas
+}
+
 func (sp *IavlStoreApp) APPHash() []byte {
 	return sp.lastCommitID.Hash
 }
@@ -568,7 +708,13 @@ func (sp *IavlStoreApp) ResetKVState() {
 	sp.kvState = ankrapscmm.State{}
 }
 
+// Rollback discards the working state of the stores except the total tx count. A branch only discards its own writes.
 func (sp *IavlStoreApp) Rollback() {
+	if sp.cacheStores != nil {
+		sp.Discard()
+		return
+	}
+
 	curTotalTx, _, _, _ , _  := sp.TotalTx(0, false)
 
 	for _, iavlS := range sp.iavlSM.storeMap {
@@ -604,21 +750,22 @@ func (sp *IavlStoreApp) StatisticalInfoQuery(height int64, prove bool)(*ankrcmm.
 }
 
 func (sp *IavlStoreApp) IsExist(cAddr string) bool {
-	return sp.iavlSM.IavlStore(IAvlStoreContractKey).Has([]byte(cAddr))
+	return sp.kvStore(IAvlStoreContractKey).Has([]byte(cAddr))
 }
 
 func (sp *IavlStoreApp) CreateCurrency(symbol string, currency *ankrcmm.CurrencyInfo) error {
-	if sp.iavlSM.IavlStore(IAvlStoreContractKey).Has([]byte(containCurrencyPrefix(symbol))) {
+	if sp.kvStore(IAvlStoreContractKey).Has([]byte(containCurrencyPrefix(symbol))) {
 		 sp.storeLog.Info("CreateCurrency, currency has existed and its info will be updated, symbol=%s", symbol)
 	}
 
-	curBytes, _ := sp.cdc.MarshalJSON(currency)
-
-	isSucess := sp.iavlSM.IavlStore(IAvlStoreContractKey).Set([]byte(containCurrencyPrefix(symbol)), curBytes)
-	if !isSucess {
-		return fmt.Errorf("create currency error, symbol=%s", symbol)
+	curBytes, err := sp.cdc.MarshalJSON(currency)
+	if err != nil {
+		return fmt.Errorf("create currency error, symbol=%s, err=%s", symbol, err.Error())
 	}
 
+	// the returned value of Set only tells whether the key existed before
+	sp.kvStore(IAvlStoreContractKey).Set([]byte(containCurrencyPrefix(symbol)), curBytes)
+
 	return nil
 }
 
@@ -627,7 +774,7 @@ func (sp *IavlStoreApp) CurrencyInfo(symbol string, height int64, prove bool) (*
 		return nil, "", nil, nil, errors.New("CurrencyInfo, blank symbol name")
 	}
 
-	curBytes, proof, err := sp.iavlSM.IavlStore(IAvlStoreContractKey).GetWithVersionProve([]byte(containCurrencyPrefix(symbol)), height, prove)
+	curBytes, proof, err := sp.kvStore(IAvlStoreContractKey).GetWithVersionProve([]byte(containCurrencyPrefix(symbol)), height, prove)
 	if err != nil || len(curBytes) == 0 {
 		sp.storeLog.Error("can't get the currency", "symbol", symbol)
 		return nil, containCurrencyPrefix(symbol), nil, nil, err
@@ -663,17 +810,17 @@ func (sp *IavlStoreApp) CurrencyInfoQuery(symbol string, height int64, prove boo
 }
 
 func (sp *IavlStoreApp) BuildCurrencyCAddrMap(symbol string, cAddr string) error {
-	if sp.iavlSM.IavlStore(IAvlStoreContractKey).Has([]byte(containContractCurrencyPrefix(symbol))) {
+	if sp.kvStore(IAvlStoreContractKey).Has([]byte(containContractCurrencyPrefix(symbol))) {
 		return errors.New("the contract name has existed")
 	}
 
-	sp.iavlSM.IavlStore(IAvlStoreContractKey).Set([]byte(containContractCurrencyPrefix(symbol)), []byte(cAddr))
+	sp.kvStore(IAvlStoreContractKey).Set([]byte(containContractCurrencyPrefix(symbol)), []byte(cAddr))
 
 	return nil
 }
 
 func (sp *IavlStoreApp) ContractAddrBySymbol(symbol string) (string, error) {
-	cAddrBytes, err := sp.iavlSM.IavlStore(IAvlStoreContractKey).Get([]byte(containContractCurrencyPrefix(symbol)))
+	cAddrBytes, err := sp.kvStore(IAvlStoreContractKey).Get([]byte(containContractCurrencyPrefix(symbol)))
 	if err != nil {
 		sp.storeLog.Error("can't get the contract addr", "symbol", symbol)
 		return "", err
@@ -687,13 +834,13 @@ func (sp *IavlStoreApp) ContractAddrBySymbol(symbol string) (string, error) {
 }
 
 func (sp *IavlStoreApp) SaveContract(cAddr string, cInfo *ankrcmm.ContractInfo) error{
-	if sp.iavlSM.IavlStore(IAvlStoreContractKey).Has([]byte(containContractInfoPrefix(cAddr))) {
+	if sp.kvStore(IAvlStoreContractKey).Has([]byte(containContractInfoPrefix(cAddr))) {
 		return errors.New("the contract name has existed")
 	}
 
 	cInfoBytes := ankrcmm.EncodeContractInfo(sp.cdc, cInfo)
 
-	sp.iavlSM.IavlStore(IAvlStoreContractKey).Set([]byte(containContractInfoPrefix(cAddr)), cInfoBytes)
+	sp.kvStore(IAvlStoreContractKey).Set([]byte(containContractInfoPrefix(cAddr)), cInfoBytes)
 
 	return nil
 }
@@ -703,7 +850,7 @@ func (sp *IavlStoreApp) LoadContract(cAddr string, height int64, prove bool) (*a
 		return nil, "", nil, nil, errors.New("LoadContract, blank cAddr")
 	}
 
-	cInfoBytes, proof, err := sp.iavlSM.IavlStore(IAvlStoreContractKey).GetWithVersionProve([]byte(containContractInfoPrefix(cAddr)), height, prove)
+	cInfoBytes, proof, err := sp.kvStore(IAvlStoreContractKey).GetWithVersionProve([]byte(containContractInfoPrefix(cAddr)), height, prove)
 	if err != nil || len(cInfoBytes) == 0 {
 		sp.storeLog.Error("can't get the contract", "addr", cAddr)
 		return nil, containContractInfoPrefix(cAddr), nil, nil, err
@@ -718,7 +865,7 @@ func (sp *IavlStoreApp) UpdateContractState(cAddr string, state ankrcmm.Contract
 	if cAddr == "" {
 		return errors.New("UpdateContractState, blank cAddr")
 	}
-	cInfoBytes, err := sp.iavlSM.IavlStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
+	cInfoBytes, err := sp.kvStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
 	if err != nil || len(cInfoBytes) == 0 {
 		sp.storeLog.Error("UpdateContractState can't get the contract", "addr", cAddr)
 		return err
@@ -729,7 +876,7 @@ func (sp *IavlStoreApp) UpdateContractState(cAddr string, state ankrcmm.Contract
 
 
 	cInfoBytes = ankrcmm.EncodeContractInfo(sp.cdc, &cInfo)
-	sp.iavlSM.IavlStore(IAvlStoreContractKey).Set([]byte(containContractInfoPrefix(cAddr)), cInfoBytes)
+	sp.kvStore(IAvlStoreContractKey).Set([]byte(containContractInfoPrefix(cAddr)), cInfoBytes)
 
 	return nil
 }
@@ -738,7 +885,7 @@ func (sp *IavlStoreApp) ChangeContractOwner(cAddr string, ownerAddr string) erro
 	if cAddr == "" {
 		return errors.New("ChangeContractOwner, blank cAddr")
 	}
-	cInfoBytes, err := sp.iavlSM.IavlStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
+	cInfoBytes, err := sp.kvStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
 	if err != nil || len(cInfoBytes) == 0 {
 		sp.storeLog.Error("ChangeContractOwner can't get the contract", "addr", cAddr)
 		return err
@@ -749,7 +896,7 @@ func (sp *IavlStoreApp) ChangeContractOwner(cAddr string, ownerAddr string) erro
 
 
 	cInfoBytes = ankrcmm.EncodeContractInfo(sp.cdc, &cInfo)
-	sp.iavlSM.IavlStore(IAvlStoreContractKey).Set([]byte(containContractInfoPrefix(cAddr)), cInfoBytes)
+	sp.kvStore(IAvlStoreContractKey).Set([]byte(containContractInfoPrefix(cAddr)), cInfoBytes)
 
 	return nil
 }
@@ -758,7 +905,7 @@ func (sp *IavlStoreApp) IsContractNormal(cAddr string) bool {
 	if cAddr == "" {
 		return false
 	}
-	cInfoBytes, err := sp.iavlSM.IavlStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
+	cInfoBytes, err := sp.kvStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
 	if err != nil || len(cInfoBytes) == 0 {
 		sp.storeLog.Error("IsContractNormal can't get the contract", "addr", cAddr)
 		return false
@@ -800,7 +947,7 @@ func (sp *IavlStoreApp) AddContractRelatedObject(cAddr string, key string, jsonO
 	if cAddr == "" {
 		return errors.New("AddContractRelatedObject, blank cAddr")
 	}
-	cInfoBytes, err := sp.iavlSM.IavlStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
+	cInfoBytes, err := sp.kvStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
 	if err != nil || len(cInfoBytes) == 0 {
 		sp.storeLog.Error("AddContractRelatedObject can't get the contract", "addr", cAddr)
 		return err
@@ -813,7 +960,7 @@ func (sp *IavlStoreApp) AddContractRelatedObject(cAddr string, key string, jsonO
 	}
 
 	cInfoBytes = ankrcmm.EncodeContractInfo(sp.cdc, &cInfo)
-	sp.iavlSM.IavlStore(IAvlStoreContractKey).Set([]byte(containContractInfoPrefix(cAddr)), cInfoBytes)
+	sp.kvStore(IAvlStoreContractKey).Set([]byte(containContractInfoPrefix(cAddr)), cInfoBytes)
 
 	return nil
 }
@@ -822,7 +969,7 @@ func (sp *IavlStoreApp) LoadContractRelatedObject(cAddr string, key string)(json
 	if cAddr == "" {
 		return "", errors.New("LoadContractRelatedObject, blank cAddr")
 	}
-	cInfoBytes, err := sp.iavlSM.IavlStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
+	cInfoBytes, err := sp.kvStore(IAvlStoreContractKey).Get([]byte(containContractInfoPrefix(cAddr)))
 	if err != nil || len(cInfoBytes) == 0 {
 		sp.storeLog.Error("LoadContractRelatedObject can't get the contract", "addr", cAddr)
 		return "", err
@@ -0,0 +1,288 @@
+package iavl
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/dgraph-io/badger"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+// BadgerDBBackend isn't one of the tendermint backends, the app store db is opened on it by NewAppDB directly
+const BadgerDBBackend = "badgerdb"
+
+// badgerDB adapts a badger db to dbm.DB. The writes aren't synced unless by SetSync, DeleteSync and WriteSync, like
+// goleveldb. As goleveldb, it panics on the errors of the db since dbm.DB returns none.
+type badgerDB struct {
+	db *badger.DB
+}
+
+var _ dbm.DB = (*badgerDB)(nil)
+
+func newBadgerDB(name string, dir string) (*badgerDB, error) {
+	dbPath := filepath.Join(dir, name+".db")
+	opts   := badger.DefaultOptions(dbPath).WithSyncWrites(false).WithLogger(nil)
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &badgerDB{db}, nil
+}
+
+func (bdb *badgerDB) Get(key []byte) []byte {
+	var value []byte
+	err := bdb.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(nonNilBytes(key))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		value, err = item.ValueCopy(nil)
+		if err == nil && value == nil {
+			value = []byte{}
+		}
+
+		return err
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+func (bdb *badgerDB) Has(key []byte) bool {
+	return bdb.Get(key) != nil
+}
+
+func (bdb *badgerDB) Set(key []byte, value []byte) {
+	err := bdb.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(nonNilBytes(key), nonNilBytes(value))
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (bdb *badgerDB) SetSync(key []byte, value []byte) {
+	bdb.Set(key, value)
+	bdb.sync()
+}
+
+func (bdb *badgerDB) Delete(key []byte) {
+	err := bdb.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete(nonNilBytes(key))
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (bdb *badgerDB) DeleteSync(key []byte) {
+	bdb.Delete(key)
+	bdb.sync()
+}
+
+func (bdb *badgerDB) sync() {
+	if err := bdb.db.Sync(); err != nil {
+		panic(err)
+	}
+}
+
+func (bdb *badgerDB) Iterator(start, end []byte) dbm.Iterator {
+	return newBadgerDBIterator(bdb.db, start, end, false)
+}
+
+func (bdb *badgerDB) ReverseIterator(start, end []byte) dbm.Iterator {
+	return newBadgerDBIterator(bdb.db, start, end, true)
+}
+
+func (bdb *badgerDB) Close() {
+	if err := bdb.db.Close(); err != nil {
+		panic(err)
+	}
+}
+
+func (bdb *badgerDB) NewBatch() dbm.Batch {
+	return &badgerDBBatch{db: bdb}
+}
+
+func (bdb *badgerDB) Print() {
+	itr := bdb.Iterator(nil, nil)
+	defer itr.Close()
+
+	for ; itr.Valid(); itr.Next() {
+		fmt.Printf("[%X]:\t[%X]\n", itr.Key(), itr.Value())
+	}
+}
+
+func (bdb *badgerDB) Stats() map[string]string {
+	lsmSize, vlogSize := bdb.db.Size()
+
+	return map[string]string{
+		"database.type": "badgerDB",
+		"lsm.size":      fmt.Sprintf("%d", lsmSize),
+		"vlog.size":     fmt.Sprintf("%d", vlogSize),
+	}
+}
+
+type badgerDBOp struct {
+	delete bool
+	key    []byte
+	value  []byte
+}
+
+// badgerDBBatch keeps the ops until Write as the tendermint batches, and writes them by a badger write batch, which
+// splits them into the transactions as big as badger permits
+type badgerDBBatch struct {
+	db  *badgerDB
+	ops []badgerDBOp
+}
+
+func (b *badgerDBBatch) Set(key, value []byte) {
+	b.ops = append(b.ops, badgerDBOp{false, append([]byte(nil), nonNilBytes(key)...), append([]byte{}, value...)})
+}
+
+func (b *badgerDBBatch) Delete(key []byte) {
+	b.ops = append(b.ops, badgerDBOp{true, append([]byte(nil), nonNilBytes(key)...), nil})
+}
+
+func (b *badgerDBBatch) Write() {
+	wb := b.db.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	for _, op := range b.ops {
+		var err error
+		if op.delete {
+			err = wb.Delete(op.key)
+		} else {
+			err = wb.Set(op.key, op.value)
+		}
+
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	if err := wb.Flush(); err != nil {
+		panic(err)
+	}
+
+	b.ops = nil
+}
+
+func (b *badgerDBBatch) WriteSync() {
+	b.Write()
+	b.db.sync()
+}
+
+func (b *badgerDBBatch) Close() {
+	b.ops = nil
+}
+
+// badgerDBIterator iterates over [start, end) as the goleveldb iterator, a nil start or end is unbounded
+type badgerDBIterator struct {
+	txn       *badger.Txn
+	itr       *badger.Iterator
+	start     []byte
+	end       []byte
+	isReverse bool
+}
+
+var _ dbm.Iterator = (*badgerDBIterator)(nil)
+
+func newBadgerDBIterator(db *badger.DB, start, end []byte, isReverse bool) *badgerDBIterator {
+	txn := db.NewTransaction(false)
+	itr := txn.NewIterator(badger.IteratorOptions{PrefetchValues: false, Reverse: isReverse})
+
+	if isReverse {
+		if end == nil {
+			itr.Rewind()
+		} else {
+			// the reverse seek stops at the biggest key <= end, and the end itself is excluded
+			itr.Seek(end)
+			if itr.Valid() && bytes.Equal(itr.Item().Key(), end) {
+				itr.Next()
+			}
+		}
+	} else {
+		if start == nil {
+			itr.Rewind()
+		} else {
+			itr.Seek(start)
+		}
+	}
+
+	return &badgerDBIterator{txn, itr, start, end, isReverse}
+}
+
+func (bi *badgerDBIterator) Domain() ([]byte, []byte) {
+	return bi.start, bi.end
+}
+
+func (bi *badgerDBIterator) Valid() bool {
+	if !bi.itr.Valid() {
+		return false
+	}
+
+	key := bi.itr.Item().Key()
+	if bi.isReverse {
+		if bi.start != nil && bytes.Compare(key, bi.start) < 0 {
+			return false
+		}
+	} else {
+		if bi.end != nil && bytes.Compare(bi.end, key) <= 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (bi *badgerDBIterator) Next() {
+	bi.assertIsValid()
+	bi.itr.Next()
+}
+
+func (bi *badgerDBIterator) Key() []byte {
+	bi.assertIsValid()
+	return bi.itr.Item().KeyCopy(nil)
+}
+
+func (bi *badgerDBIterator) Value() []byte {
+	bi.assertIsValid()
+
+	value, err := bi.itr.Item().ValueCopy(nil)
+	if err != nil {
+		panic(err)
+	}
+	if value == nil {
+		value = []byte{}
+	}
+
+	return value
+}
+
+func (bi *badgerDBIterator) Close() {
+	bi.itr.Close()
+	bi.txn.Discard()
+}
+
+func (bi *badgerDBIterator) assertIsValid() {
+	if !bi.Valid() {
+		panic("badgerDBIterator is invalid")
+	}
+}
+
+func nonNilBytes(bz []byte) []byte {
+	if bz == nil {
+		return []byte{}
+	}
+
+	return bz
+}
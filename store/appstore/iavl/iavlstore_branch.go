@@ -0,0 +1,119 @@
+package iavl
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+)
+
+// kvStore returns the store the app store reads and writes, which is the cache over the parent's if it is a branch
+func (sp *IavlStoreApp) kvStore(storeKey string) KVStore {
+	if sp.cacheStores == nil {
+		return sp.iavlSM.IavlStore(storeKey)
+	}
+
+	if cacheS, ok := sp.cacheStores[storeKey]; ok {
+		return cacheS
+	}
+
+	sp.storeLog.Error("can't find the responding cache store", "storeKey", storeKey)
+
+	return nil
+}
+
+// Branch returns a branch of the app store whose writes are kept in memory until Write applies them to this store or
+// Discard drops them. The reads of the branch see its own writes over this store's working state, and a branch can be
+// branched again. The branch shouldn't be used after this store is written by others, nor be committed.
+func (sp *IavlStoreApp) Branch() appstore.AppStore {
+	cacheStores := make(map[string]*cacheKVStore, len(sp.iavlSM.storeMap))
+	for storeKey := range sp.iavlSM.storeMap {
+		cacheStores[storeKey] = newCacheKVStore(sp.kvStore(storeKey))
+	}
+
+	return &IavlStoreApp{
+		iavlSM:         sp.iavlSM,
+		lastCommitID:   sp.lastCommitID,
+		totalTx:        sp.totalTx,
+		storeLog:       sp.storeLog,
+		cdc:            sp.cdc,
+		kvState:        sp.kvState,
+		queryHandleMap: sp.queryHandleMap,
+		parent:         sp,
+		cacheStores:    cacheStores,
+	}
+}
+
+// CommittedBranch returns a branch of the last committed state, which isn't affected by the working state. Its writes
+// can't be written into the stores, and it is used to check the txs without touching the state of the block executed.
+func (sp *IavlStoreApp) CommittedBranch() appstore.AppStore {
+	cacheStores := make(map[string]*cacheKVStore, len(sp.iavlSM.storeMap))
+	for storeKey, iavlS := range sp.iavlSM.storeMap {
+		cacheStores[storeKey] = newCacheKVStore(newCommittedKVStore(iavlS, sp.lastCommitID.Version))
+	}
+
+	branch := &IavlStoreApp{
+		iavlSM:         sp.iavlSM,
+		lastCommitID:   sp.lastCommitID,
+		storeLog:       sp.storeLog,
+		cdc:            sp.cdc,
+		kvState:        sp.kvState,
+		queryHandleMap: sp.queryHandleMap,
+		cacheStores:    cacheStores,
+	}
+	branch.totalTx = branch.committedTotalTx()
+
+	return branch
+}
+
+func (sp *IavlStoreApp) committedTotalTx() int64 {
+	totalTxBytes, _, err := sp.kvStore(IAvlStoreMainKey).GetWithVersionProve([]byte(TotalTxKey), sp.lastCommitID.Version, false)
+	if err != nil || totalTxBytes == nil {
+		return 0
+	}
+
+	totalTx, _ := binary.Varint(totalTxBytes)
+
+	return totalTx
+}
+
+// Write applies the writes of the branch to its parent, and the branch goes on with the parent's state. It does
+// nothing on the app store which isn't a branch, whose writes are persisted by Commit.
+func (sp *IavlStoreApp) Write() {
+	if sp.cacheStores == nil {
+		return
+	}
+
+	if sp.parent == nil {
+		panic("can't write the branch of the committed state")
+	}
+
+	storeKeys := make([]string, 0, len(sp.cacheStores))
+	for storeKey := range sp.cacheStores {
+		storeKeys = append(storeKeys, storeKey)
+	}
+	sort.Strings(storeKeys)
+
+	for _, storeKey := range storeKeys {
+		sp.cacheStores[storeKey].Write()
+	}
+
+	sp.parent.totalTx = sp.totalTx
+}
+
+// Discard drops the writes of the branch, and the branch goes on with the parent's state
+func (sp *IavlStoreApp) Discard() {
+	if sp.cacheStores == nil {
+		return
+	}
+
+	for _, cacheS := range sp.cacheStores {
+		cacheS.Discard()
+	}
+
+	if sp.parent == nil {
+		sp.totalTx = sp.committedTotalTx()
+	} else {
+		sp.totalTx = sp.parent.totalTx
+	}
+}
@@ -0,0 +1,108 @@
+package iavl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func certKey(appStore *IavlStoreApp, dcName string) string {
+	pem, _, _, _ := appStore.CertKey(dcName, 0, false)
+	return pem
+}
+
+func TestIavlStoreBranch(t *testing.T) {
+	appStore := NewMockIavlStoreApp()
+	appStore.SetCertKey("dc1", "pem1")
+	appStore.SetCertKey("dc2", "pem2")
+	appStore.IncTotalTx()
+	appStore.Commit()
+
+	branch := appStore.Branch().(*IavlStoreApp)
+	branch.SetCertKey("dc1", "pem1b")
+	branch.DeleteCertKey("dc2")
+	branch.SetCertKey("dc3", "pem3")
+	branch.IncTotalTx()
+
+	assert.Equal(t, "pem1b", certKey(branch, "dc1"))
+	assert.Equal(t, "", certKey(branch, "dc2"))
+	assert.Equal(t, "pem1", certKey(appStore, "dc1"))
+	assert.Equal(t, "pem2", certKey(appStore, "dc2"))
+	assert.Equal(t, "", certKey(appStore, "dc3"))
+	assert.Equal(t, int64(1), appStore.totalTx)
+
+	_, cnt := branch.CertKeyList()
+	assert.Equal(t, uint64(2), cnt)
+
+	// the nested branch sees the writes of its parent, and its own writes are dropped by Discard
+	nested := branch.Branch().(*IavlStoreApp)
+	assert.Equal(t, "pem1b", certKey(nested, "dc1"))
+	nested.SetCertKey("dc4", "pem4")
+	nested.IncTotalTx()
+	nested.Discard()
+	assert.Equal(t, "", certKey(nested, "dc4"))
+	assert.Equal(t, int64(2), nested.totalTx)
+
+	nested.SetCertKey("dc5", "pem5")
+	nested.Write()
+	assert.Equal(t, "pem5", certKey(branch, "dc5"))
+	assert.Equal(t, "", certKey(appStore, "dc5"))
+
+	branch.Write()
+	assert.Equal(t, "pem1b", certKey(appStore, "dc1"))
+	assert.Equal(t, "", certKey(appStore, "dc2"))
+	assert.Equal(t, "pem3", certKey(appStore, "dc3"))
+	assert.Equal(t, "pem5", certKey(appStore, "dc5"))
+	assert.Equal(t, int64(2), appStore.totalTx)
+
+	// the branch discarded never touches the state written by the branches before
+	failed := appStore.Branch()
+	failed.SetCertKey("dc1", "pemx")
+	failed.Rollback()
+	assert.Equal(t, "pem1b", certKey(appStore, "dc1"))
+
+	appStore.Commit()
+	pem, _, _, _ := appStore.CertKey("dc3", 2, false)
+	assert.Equal(t, "pem3", pem)
+
+	assert.Panics(t, func() { branch.Commit() })
+}
+
+func TestIavlStoreCommittedBranch(t *testing.T) {
+	appStore := NewMockIavlStoreApp()
+
+	checkState := appStore.CommittedBranch().(*IavlStoreApp)
+	assert.Equal(t, "", certKey(checkState, "dc1"))
+	assert.Equal(t, int64(0), checkState.totalTx)
+
+	appStore.SetCertKey("dc1", "pem1")
+	appStore.IncTotalTx()
+	appStore.Commit()
+
+	checkState = appStore.CommittedBranch().(*IavlStoreApp)
+	assert.Equal(t, "pem1", certKey(checkState, "dc1"))
+	assert.Equal(t, int64(1), checkState.totalTx)
+
+	// the working state of the block executed isn't seen by the check state
+	appStore.SetCertKey("dc1", "pem1b")
+	appStore.SetCertKey("dc2", "pem2")
+	assert.Equal(t, "pem1", certKey(checkState, "dc1"))
+	assert.Equal(t, "", certKey(checkState, "dc2"))
+
+	// the writes of the check state never reach the stores
+	nested := checkState.Branch().(*IavlStoreApp)
+	nested.SetCertKey("dc3", "pem3")
+	nested.Write()
+	assert.Equal(t, "pem3", certKey(checkState, "dc3"))
+	assert.Equal(t, "", certKey(appStore, "dc3"))
+	assert.Panics(t, func() { checkState.Write() })
+
+	checkState.Rollback()
+	assert.Equal(t, "", certKey(checkState, "dc3"))
+	assert.Equal(t, "pem1b", certKey(appStore, "dc1"))
+
+	appStore.Commit()
+	checkState = appStore.CommittedBranch().(*IavlStoreApp)
+	assert.Equal(t, "pem1b", certKey(checkState, "dc1"))
+	assert.Equal(t, "pem2", certKey(checkState, "dc2"))
+}
@@ -0,0 +1,215 @@
+package iavl
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/tendermint/iavl"
+)
+
+// KVStore is the kv access of one store the app store works on, it is either the iavl store or a cache over it
+type KVStore interface {
+	Get(key []byte) ([]byte, error)
+	Set(key []byte, value []byte) bool
+	Has(key []byte) bool
+	Remove(key []byte) ([]byte, bool)
+	GetWithVersionProve(key []byte, ver int64, prove bool) ([]byte, *iavl.RangeProof, error)
+	IterateRange(start, end []byte, ascending bool, fn func(key []byte, value []byte) bool) bool
+}
+
+// committedKVStore reads the version committed of the iavl store, which is never changed by the working state. It is the
+// base of the branch of the committed state, and can't be written.
+type committedKVStore struct {
+	store *IavlStore
+	tree  *iavl.ImmutableTree
+	ver   int64
+}
+
+func newCommittedKVStore(store *IavlStore, ver int64) *committedKVStore {
+	tree, err := store.GetImmutable(ver)
+	if err != nil {
+		// nothing committed yet, the empty tree has no root
+		tree = &iavl.ImmutableTree{}
+	}
+
+	return &committedKVStore{store: store, tree: tree, ver: ver}
+}
+
+func (cs *committedKVStore) Get(key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, errors.New("key is nil")
+	}
+
+	_, value := cs.tree.Get(key)
+
+	return value, nil
+}
+
+func (cs *committedKVStore) Set(key []byte, value []byte) bool {
+	panic("can't write the committed store")
+}
+
+func (cs *committedKVStore) Has(key []byte) bool {
+	return cs.tree.Has(key)
+}
+
+func (cs *committedKVStore) Remove(key []byte) ([]byte, bool) {
+	panic("can't write the committed store")
+}
+
+func (cs *committedKVStore) GetWithVersionProve(key []byte, ver int64, prove bool) ([]byte, *iavl.RangeProof, error) {
+	if !prove && (ver <= 0 || ver == cs.ver) {
+		value, err := cs.Get(key)
+		return value, nil, err
+	}
+
+	return cs.store.GetWithVersionProve(key, ver, prove)
+}
+
+func (cs *committedKVStore) IterateRange(start, end []byte, ascending bool, fn func(key []byte, value []byte) bool) bool {
+	return cs.tree.IterateRange(start, end, ascending, fn)
+}
+
+type cacheValue struct {
+	value   []byte
+	deleted bool
+}
+
+// cacheKVStore keeps the writes in memory over the parent store until they are written into it. The reads of the
+// committed versions and the proofs go to the parent directly, since only the working state is cached.
+type cacheKVStore struct {
+	parent KVStore
+	cache  map[string]*cacheValue
+}
+
+func newCacheKVStore(parent KVStore) *cacheKVStore {
+	return &cacheKVStore{parent: parent, cache: make(map[string]*cacheValue)}
+}
+
+func (cs *cacheKVStore) Get(key []byte) ([]byte, error) {
+	if len(key) == 0 {
+		return nil, errors.New("key is nil")
+	}
+
+	if cValue, ok := cs.cache[string(key)]; ok {
+		if cValue.deleted {
+			return nil, nil
+		}
+
+		return cValue.value, nil
+	}
+
+	return cs.parent.Get(key)
+}
+
+func (cs *cacheKVStore) Set(key []byte, value []byte) bool {
+	updated := cs.Has(key)
+	cs.cache[string(key)] = &cacheValue{value: value}
+
+	return updated
+}
+
+func (cs *cacheKVStore) Has(key []byte) bool {
+	if cValue, ok := cs.cache[string(key)]; ok {
+		return !cValue.deleted
+	}
+
+	return cs.parent.Has(key)
+}
+
+func (cs *cacheKVStore) Remove(key []byte) ([]byte, bool) {
+	if !cs.Has(key) {
+		return nil, false
+	}
+
+	value, _ := cs.Get(key)
+	cs.cache[string(key)] = &cacheValue{deleted: true}
+
+	return value, true
+}
+
+func (cs *cacheKVStore) GetWithVersionProve(key []byte, ver int64, prove bool) ([]byte, *iavl.RangeProof, error) {
+	if ver <= 0 && !prove {
+		value, err := cs.Get(key)
+		return value, nil, err
+	}
+
+	return cs.parent.GetWithVersionProve(key, ver, prove)
+}
+
+// IterateRange iterates the keys in [start, end) of the parent merged with the cached writes, it stops if fn returns
+// true and returns whether it is stopped
+func (cs *cacheKVStore) IterateRange(start, end []byte, ascending bool, fn func(key []byte, value []byte) bool) bool {
+	inRange := func(key []byte) bool {
+		return (start == nil || bytes.Compare(key, start) >= 0) && (end == nil || bytes.Compare(key, end) < 0)
+	}
+
+	pairs := make(map[string][]byte)
+	cs.parent.IterateRange(start, end, true, func(key []byte, value []byte) bool {
+		pairs[string(key)] = value
+		return false
+	})
+
+	for key, cValue := range cs.cache {
+		if !inRange([]byte(key)) {
+			continue
+		}
+
+		if cValue.deleted {
+			delete(pairs, key)
+		} else {
+			pairs[key] = cValue.value
+		}
+	}
+
+	keys := sortedKeys(pairs)
+	if !ascending {
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+
+	for _, key := range keys {
+		if fn([]byte(key), pairs[key]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Write applies the cached writes to the parent in the order of the keys, so the shape of the iavl tree written is the
+// same on all the nodes
+func (cs *cacheKVStore) Write() {
+	keys := make([]string, 0, len(cs.cache))
+	for key := range cs.cache {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		cValue := cs.cache[key]
+		if cValue.deleted {
+			cs.parent.Remove([]byte(key))
+		} else {
+			cs.parent.Set([]byte(key), cValue.value)
+		}
+	}
+
+	cs.Discard()
+}
+
+func (cs *cacheKVStore) Discard() {
+	cs.cache = make(map[string]*cacheValue)
+}
+
+func sortedKeys(pairs map[string][]byte) []string {
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
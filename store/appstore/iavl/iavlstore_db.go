@@ -0,0 +1,142 @@
+package iavl
+
+import (
+	"bytes"
+	"fmt"
+
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+const (
+	AppStoreDBName = "appstore"
+
+	// DefaultDBBackend is the backend the app store was hardwired to
+	DefaultDBBackend = string(dbm.GoLevelDBBackend)
+
+	migrateBatchSize = 10000
+)
+
+// NewAppDB opens the db of the app store in the dir on the backend, which is one of goleveldb, cleveldb (built with
+// gcc), badgerdb and memdb. The memdb keeps nothing on the disk and the dir is ignored. The fsdb isn't supported since
+// the iavl trees are saved by batches. The rocksdb isn't supported either, tendermint has no backend for it.
+func NewAppDB(backend string, dbDir string) (db dbm.DB, err error) {
+	if backend == string(dbm.FSDBBackend) {
+		return nil, fmt.Errorf("can't open the app store db on backend %s, no batch supported", backend)
+	}
+
+	if backend == BadgerDBBackend {
+		return newBadgerDB(AppStoreDBName, dbDir)
+	}
+
+	defer func() {
+		if rErr := recover(); rErr != nil {
+			db  = nil
+			err = fmt.Errorf("can't open the app store db on backend %s: %v", backend, rErr)
+		}
+	}()
+
+	return dbm.NewDB(AppStoreDBName, dbm.DBBackendType(backend), dbDir), nil
+}
+
+// MigrateDB copies all the kv pairs of the app store db, including the iavl nodes of the stores, the commit infos and
+// the snapshots, into the db of another backend, and returns the count of the kv pairs copied. The db migrated to must
+// be empty, the kv pairs left in it would be mixed with the ones copied.
+func MigrateDB(srcDB dbm.DB, dstDB dbm.DB, migrateLog log.Logger) (int64, error) {
+	dstItr := dstDB.Iterator(nil, nil)
+	dstEmpty := !dstItr.Valid()
+	dstItr.Close()
+	if !dstEmpty {
+		return 0, fmt.Errorf("the db migrated to isn't empty")
+	}
+
+	itr := srcDB.Iterator(nil, nil)
+	defer itr.Close()
+
+	batch := dstDB.NewBatch()
+
+	count := int64(0)
+	for ; itr.Valid(); itr.Next() {
+		batch.Set(itr.Key(), itr.Value())
+		count++
+
+		if count%migrateBatchSize == 0 {
+			batch.Write()
+			batch.Close()
+			batch = dstDB.NewBatch()
+
+			migrateLog.Info("Migrating app store db", "count", count)
+		}
+	}
+
+	batch.WriteSync()
+	batch.Close()
+
+	if err := VerifyMigratedDB(srcDB, dstDB); err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// VerifyMigratedDB loads the stores from both dbs, and compares the commit infos of all the versions committed and the
+// root hashes of the stores at each version kept
+func VerifyMigratedDB(srcDB dbm.DB, dstDB dbm.DB) error {
+	srcSM := NewIavlStoreMulti(srcDB, log.NewNopLogger())
+	srcSM.Load()
+
+	dstSM := NewIavlStoreMulti(dstDB, log.NewNopLogger())
+	dstSM.Load()
+
+	srcCommit, dstCommit := srcSM.lastCommit(), dstSM.lastCommit()
+	if srcCommit.Version != dstCommit.Version || !bytes.Equal(srcCommit.Hash, dstCommit.Hash) {
+		return fmt.Errorf("the last commit migrated mismatches: version %d, hash %X, expected version %d, hash %X",
+			dstCommit.Version, dstCommit.Hash, srcCommit.Version, srcCommit.Hash)
+	}
+
+	for ver := int64(1); ver <= srcCommit.Version; ver++ {
+		if !srcSM.commitInfoExists(ver) {
+			continue
+		}
+
+		if !dstSM.commitInfoExists(ver) {
+			return fmt.Errorf("the commit info of version %d isn't migrated", ver)
+		}
+
+		srcInfo, dstInfo := srcSM.cdc.MustMarshalBinaryBare(srcSM.commitInfo(ver)), dstSM.cdc.MustMarshalBinaryBare(dstSM.commitInfo(ver))
+		if !bytes.Equal(srcInfo, dstInfo) {
+			return fmt.Errorf("the commit info of version %d migrated mismatches", ver)
+		}
+
+		for key, srcS := range srcSM.storeMap {
+			if !srcS.VersionExists(ver) {
+				continue
+			}
+
+			srcHash, dstHash, err := storeVersionHashes(srcS, dstSM.storeMap[key], ver)
+			if err != nil {
+				return fmt.Errorf("can't get the root hash of store %s at version %d: %v", key, ver, err)
+			}
+
+			if !bytes.Equal(srcHash, dstHash) {
+				return fmt.Errorf("the root hash of store %s at version %d migrated mismatches: %X, expected %X", key, ver, dstHash, srcHash)
+			}
+		}
+	}
+
+	return nil
+}
+
+func storeVersionHashes(srcS *IavlStore, dstS *IavlStore, ver int64) ([]byte, []byte, error) {
+	srcTree, err := srcS.GetImmutable(ver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dstTree, err := dstS.GetImmutable(ver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return srcTree.Hash(), dstTree.Hash(), nil
+}
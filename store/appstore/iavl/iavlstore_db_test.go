@@ -0,0 +1,151 @@
+package iavl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/stretchr/testify/assert"
+	dbm "github.com/tendermint/tendermint/libs/db"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestIavlStoreMigrateDB(t *testing.T) {
+	_, err := NewAppDB("unknown", "")
+	assert.NotEqual(t, nil, err)
+
+	_, err = NewAppDB(string(dbm.FSDBBackend), "")
+	assert.NotEqual(t, nil, err)
+
+	appStore := NewIavlStoreAppWithBackend("", string(dbm.MemDBBackend), log.NewNopLogger())
+	appStore.SetChainID("test-chain")
+	for i := 0; i < 3; i++ {
+		appStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(int64(1000 + i)).Bytes()})
+		appStore.IncTotalTx()
+		appStore.Commit()
+	}
+
+	toDir, err := ioutil.TempDir("", "iavlstore")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(toDir)
+
+	dstDB, err := NewAppDB(string(dbm.GoLevelDBBackend), toDir)
+	assert.Equal(t, nil, err)
+
+	count, err := MigrateDB(appStore.DB(), dstDB, log.NewNopLogger())
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, int64(0), count)
+	dstDB.Close()
+
+	migratedStore := NewIavlStoreAppWithBackend(toDir, string(dbm.GoLevelDBBackend), log.NewNopLogger())
+	assert.Equal(t, appStore.LastCommitState(), migratedStore.LastCommitState())
+	assert.Equal(t, appStore.APPHash(), migratedStore.APPHash())
+	defer migratedStore.DB().Close()
+
+	_, storeHashes, err := appStore.StoreHashes(2)
+	assert.Equal(t, nil, err)
+	_, migratedStoreHashes, err := migratedStore.StoreHashes(2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, storeHashes, migratedStoreHashes)
+
+	bal, _, _, _, err := migratedStore.Balance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", "ANKR", 2, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1001", bal.String())
+
+	// the db not empty isn't migrated to
+	_, err = MigrateDB(appStore.DB(), migratedStore.DB(), log.NewNopLogger())
+	assert.NotEqual(t, nil, err)
+
+	// the commit info of a version before the latest one is compared too
+	infoKey := []byte(fmt.Sprintf(CommitInfoKey, 1))
+	infoV   := migratedStore.DB().Get(infoKey)
+	migratedStore.DB().Set(infoKey, migratedStore.DB().Get([]byte(fmt.Sprintf(CommitInfoKey, 2))))
+	assert.NotEqual(t, nil, VerifyMigratedDB(appStore.DB(), migratedStore.DB()))
+	migratedStore.DB().Set(infoKey, infoV)
+	assert.Equal(t, nil, VerifyMigratedDB(appStore.DB(), migratedStore.DB()))
+
+	// the db changed after the migration mismatches
+	appStore.IncTotalTx()
+	appStore.Commit()
+	assert.NotEqual(t, nil, VerifyMigratedDB(appStore.DB(), migratedStore.DB()))
+}
+
+func TestIavlStoreBadgerDB(t *testing.T) {
+	dbDir, err := ioutil.TempDir("", "iavlstore")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dbDir)
+
+	db, err := NewAppDB(BadgerDBBackend, dbDir)
+	assert.Equal(t, nil, err)
+
+	memDB := dbm.NewMemDB()
+	for _, kv := range [][2]string{{"a", "1"}, {"b", ""}, {"c", "3"}, {"d", "4"}} {
+		db.Set([]byte(kv[0]), []byte(kv[1]))
+		memDB.Set([]byte(kv[0]), []byte(kv[1]))
+	}
+
+	batch := db.NewBatch()
+	batch.Set([]byte("e"), []byte("5"))
+	batch.Delete([]byte("c"))
+	assert.Equal(t, false, db.Has([]byte("e")))
+	batch.WriteSync()
+	batch.Close()
+	memDB.Set([]byte("e"), []byte("5"))
+	memDB.Delete([]byte("c"))
+
+	assert.Equal(t, []byte("1"), db.Get([]byte("a")))
+	assert.Equal(t, []byte{}, db.Get([]byte("b")))
+	assert.Equal(t, []byte(nil), db.Get([]byte("c")))
+	assert.Equal(t, true, db.Has([]byte("e")))
+
+	// the iterators walk the same keys as the ones of the tendermint backends
+	collect := func(itr dbm.Iterator) []string {
+		defer itr.Close()
+
+		var kvs []string
+		for ; itr.Valid(); itr.Next() {
+			kvs = append(kvs, string(itr.Key())+"="+string(itr.Value()))
+		}
+
+		return kvs
+	}
+
+	for _, r := range [][2][]byte{{nil, nil}, {[]byte("b"), []byte("d")}, {[]byte("bb"), []byte("dd")}, {[]byte("c"), nil}, {nil, []byte("c")}, {[]byte("f"), nil}} {
+		assert.Equal(t, collect(memDB.Iterator(r[0], r[1])), collect(db.Iterator(r[0], r[1])))
+		assert.Equal(t, collect(memDB.ReverseIterator(r[0], r[1])), collect(db.ReverseIterator(r[0], r[1])))
+	}
+
+	db.Close()
+
+	// the app store is migrated to badgerdb and loaded from it
+	appStore := NewIavlStoreAppWithBackend("", string(dbm.MemDBBackend), log.NewNopLogger())
+	appStore.SetChainID("test-chain")
+	for i := 0; i < 3; i++ {
+		appStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(int64(1000 + i)).Bytes()})
+		appStore.IncTotalTx()
+		appStore.Commit()
+	}
+
+	toDir, err := ioutil.TempDir("", "iavlstore")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(toDir)
+
+	dstDB, err := NewAppDB(BadgerDBBackend, toDir)
+	assert.Equal(t, nil, err)
+
+	_, err = MigrateDB(appStore.DB(), dstDB, log.NewNopLogger())
+	assert.Equal(t, nil, err)
+	dstDB.Close()
+
+	migratedStore := NewIavlStoreAppWithBackend(toDir, BadgerDBBackend, log.NewNopLogger())
+	defer migratedStore.DB().Close()
+	assert.Equal(t, appStore.LastCommitState(), migratedStore.LastCommitState())
+	assert.Equal(t, appStore.APPHash(), migratedStore.APPHash())
+
+	bal, _, _, _, err := migratedStore.Balance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", "ANKR", 0, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1002", bal.String())
+}
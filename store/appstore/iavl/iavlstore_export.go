@@ -0,0 +1,139 @@
+package iavl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Ankr-network/ankr-chain/account"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+)
+
+type exportPrefix struct {
+	name   string
+	prefix string
+}
+
+// exportStores lists the stores in the export order and the known key prefixes of each one
+var exportStores = []struct {
+	storeName string
+	prefixes  []exportPrefix
+}{
+	{IAvlStoreMainKey, []exportPrefix{
+		{"validators", StoreValidatorPrefix},
+		{"certkeys", StoreCertKeyPrefix},
+		{"meterings", StoreMeteringPrefix},
+		{"mingasprices", StoreMinGasPricePrefix},
+		{"feecurrencies", StoreFeeCurrencyPrefix},
+		{"admins", account.StoreAdminAccountPrefix},
+		{"upgrades", StoreUpgradePrefix},
+	}},
+	{IavlStoreAccountKey, []exportPrefix{
+		{"accounts", StoreAccountPrefix},
+		{"allowances", StoreAccountAllowPrefix},
+		{"multisigaccounts", StoreMultiSigPrefix},
+	}},
+	{IAvlStoreContractKey, []exportPrefix{
+		{"contracts", StoreContractInfoPrefix},
+		{"currencycontracts", StoreContractCurrencyPrefix},
+		{"currencies", StoreCurrencyPrefix},
+	}},
+	{IavlStorePermKey, []exportPrefix{
+		{"roles", StoreRolePrefix},
+		{"boundactions", StoreBoundActionPrefix},
+	}},
+}
+
+// the keys belong to the chain exported, and the importing chain sets its own ones
+var importSkippedKeys = map[string]bool{
+	ChainIDKey:     true,
+	MaxBlockGasKey: true,
+}
+
+// Export walks every key of the stores at the height, the latest committed one if height <= 0. Only the heights
+// not pruned yet can be exported.
+func (sp *IavlStoreApp) Export(height int64) (*ankrcmm.GenesisSnapshot, error) {
+	if height <= 0 {
+		height = sp.Height()
+	}
+
+	snapshot := &ankrcmm.GenesisSnapshot{Height: height}
+
+	for _, eStore := range exportStores {
+		iavlS := sp.iavlSM.IavlStore(eStore.storeName)
+		if !iavlS.VersionExists(height) {
+			return nil, fmt.Errorf("the height %d of store %s doesn't exist", height, eStore.storeName)
+		}
+
+		tree, err := iavlS.GetImmutable(height)
+		if err != nil {
+			return nil, err
+		}
+
+		gPrefixes := make([]ankrcmm.GenesisStorePrefix, len(eStore.prefixes)+1)
+		for i, ePrefix := range eStore.prefixes {
+			gPrefixes[i] = ankrcmm.GenesisStorePrefix{Name: ePrefix.name, Prefix: ePrefix.prefix, KVs: []ankrcmm.GenesisStoreKV{}}
+		}
+		gPrefixes[len(eStore.prefixes)] = ankrcmm.GenesisStorePrefix{Name: "others", Prefix: "", KVs: []ankrcmm.GenesisStoreKV{}}
+
+		tree.Iterate(func(key []byte, value []byte) bool {
+			if !utf8.Valid(key) {
+				err = fmt.Errorf("the key %X of store %s isn't readable", key, eStore.storeName)
+				return true
+			}
+
+			index := len(eStore.prefixes)
+			for i, ePrefix := range eStore.prefixes {
+				if strings.HasPrefix(string(key), ePrefix.prefix) {
+					index = i
+					break
+				}
+			}
+
+			gPrefixes[index].KVs = append(gPrefixes[index].KVs, ankrcmm.GenesisStoreKV{string(key), value})
+
+			return false
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if eStore.storeName == IAvlStoreMainKey {
+			if _, chainIDBytes := tree.Get([]byte(ChainIDKey)); chainIDBytes != nil {
+				snapshot.ChainID = string(chainIDBytes)
+			}
+		}
+
+		snapshot.Stores = append(snapshot.Stores, ankrcmm.GenesisStore{eStore.storeName, gPrefixes})
+	}
+
+	return snapshot, nil
+}
+
+// ImportSnapshot writes the key values of the snapshot into the working stores
+func (sp *IavlStoreApp) ImportSnapshot(snapshot *ankrcmm.GenesisSnapshot) error {
+	for _, gStore := range snapshot.Stores {
+		iavlS, ok := sp.iavlSM.storeMap[gStore.Name]
+		if !ok {
+			return fmt.Errorf("unknown store %s", gStore.Name)
+		}
+
+		for _, gPrefix := range gStore.Prefixes {
+			for _, kv := range gPrefix.KVs {
+				if gStore.Name == IAvlStoreMainKey && importSkippedKeys[kv.Key] {
+					continue
+				}
+
+				iavlS.Set([]byte(kv.Key), kv.Value)
+			}
+		}
+	}
+
+	totalTxBytes, err := sp.iavlSM.IavlStore(IAvlStoreMainKey).Get([]byte(TotalTxKey))
+	if err == nil && len(totalTxBytes) > 0 {
+		sp.totalTx, _ = binary.Varint(totalTxBytes)
+	}
+
+	return nil
+}
@@ -20,13 +20,9 @@ const (
 	IAvlStoreContractDefCacheSize = 10000
 	IAvlStorePermDefCacheSize     = 10000
 
-	IAVLStoreAccountKeepVersionNum  = 100
-	IAVLStoreMainKeepVersionNum     = 100
-	IAVLStoreContractKeepVersionNum = 100
-	IAVLStorePermKeepVersionNum     = 100
-
     CommitInfoKey     = "cminfo%d"
     LatestVerKey      = "latestverkey"
+    LastCommitStateKey = "lastcommitstate"
 )
 
 type IavlStoreMulti struct {
@@ -34,6 +30,9 @@ type IavlStoreMulti struct {
 	storeMap     map[string]*IavlStore
 	log          log.Logger
 	cdc          *amino.Codec
+	restorer     *snapshotRestorer
+	pruning      ankrcmm.PruningOptions
+	snapshotVersion int64
 }
 
 type storeCommitID struct {
@@ -59,18 +58,29 @@ func NewIavlStoreMulti(db dbm.DB, storeLog log.Logger) *IavlStoreMulti {
 	storeMap := make(map[string]*IavlStore)
 
 	dbAcc := dbm.NewPrefixDB(db, []byte("ankr:"+IavlStoreAccountKey+"/"))
-	storeMap[IavlStoreAccountKey] = NewIavlStore(dbAcc, IavlStoreAccountDefCacheSize, IAVLStoreAccountKeepVersionNum, storeLog.With("module", "accountstore"))
+	storeMap[IavlStoreAccountKey] = NewIavlStore(dbAcc, IavlStoreAccountDefCacheSize, storeLog.With("module", "accountstore"))
 
 	dbTran := dbm.NewPrefixDB(db, []byte("ankr:"+IAvlStoreMainKey+"/"))
-	storeMap[IAvlStoreMainKey] = NewIavlStore(dbTran, IAvlStoreTxDefCacheSize, IAVLStoreMainKeepVersionNum, storeLog.With("module", "txstore"))
+	storeMap[IAvlStoreMainKey] = NewIavlStore(dbTran, IAvlStoreTxDefCacheSize, storeLog.With("module", "txstore"))
 
 	dbMt := dbm.NewPrefixDB(db, []byte("ankr:"+IAvlStoreContractKey+"/"))
-	storeMap[IAvlStoreContractKey] = NewIavlStore(dbMt, IAvlStoreContractDefCacheSize, IAVLStoreContractKeepVersionNum, storeLog.With("module", "contractstore"))
+	storeMap[IAvlStoreContractKey] = NewIavlStore(dbMt, IAvlStoreContractDefCacheSize, storeLog.With("module", "contractstore"))
 
 	dbR := dbm.NewPrefixDB(db, []byte("ankr:"+IavlStorePermKey+"/"))
-	storeMap[IavlStorePermKey] = NewIavlStore(dbR, IAvlStorePermDefCacheSize, IAVLStorePermKeepVersionNum, storeLog.With("module", "permstore"))
+	storeMap[IavlStorePermKey] = NewIavlStore(dbR, IAvlStorePermDefCacheSize, storeLog.With("module", "permstore"))
+
+	iavlSM := &IavlStoreMulti{db: db, storeMap: storeMap, log: storeLog, cdc: amino.NewCodec(), pruning: DefaultPruningOptions()}
+
+	// the stores restored from a snapshot keep their versions shifted to the chain height
+	if offset := iavlSM.versionOffset(); offset > 0 {
+		for _, iavlS := range storeMap {
+			iavlS.setVersionOffset(offset)
+		}
+	}
 
-	return &IavlStoreMulti{db, storeMap, storeLog, amino.NewCodec()}
+	iavlSM.resumeRollback()
+
+	return iavlSM
 }
 
 func (ms *IavlStoreMulti) IavlStore(storeKey string) *IavlStore {
@@ -83,10 +93,41 @@ func (ms *IavlStoreMulti) IavlStore(storeKey string) *IavlStore {
 	return nil
 }
 
-// Load the latest versioned tree from disk.
+// Load the latest committed version of the trees from disk, the versions saved by a commit not finished are ignored
 func (ms *IavlStoreMulti) Load() {
+	latestVer := ms.latestVersion()
 	for key, iavlS := range ms.storeMap {
-		ver, err := iavlS.Load()
+		var ver int64
+		var err error
+		if latestVer > 0 {
+			ver, err = iavlS.LoadVersion(latestVer)
+		} else {
+			ver, err = iavlS.Load()
+		}
+
+		if err != nil {
+			ms.log.Error("Load the latest db failed", "key", key, "err", err)
+		}else {
+			ms.log.Info("Load the latest db successful", "key", key, "ver", ver)
+		}
+	}
+}
+
+// LoadForWriting loads the latest committed version like Load, and deletes the versions saved by a commit interrupted
+// by a crash, so the block is committed again when it is replayed
+func (ms *IavlStoreMulti) LoadForWriting() {
+	latestVer := ms.latestVersion()
+	if latestVer <= 0 {
+		ms.Load()
+		return
+	}
+
+	for key, iavlS := range ms.storeMap {
+		if iavlS.Version() > latestVer {
+			ms.log.Info("Discard the versions not committed", "key", key, "ver", iavlS.Version(), "committedVer", latestVer)
+		}
+
+		ver, err := iavlS.LoadVersionForOverwriting(latestVer)
 		if err != nil {
 			ms.log.Error("Load the latest db failed", "key", key, "err", err)
 		}else {
@@ -137,6 +178,26 @@ func (ms *IavlStoreMulti) setLatestVersion(batch dbm.Batch, version int64) {
 	batch.Set([]byte(LatestVerKey), latestVerBtest)
 }
 
+func (ms *IavlStoreMulti) setLastCommitState(batch dbm.Batch, state ankrcmm.CommitState) {
+	stateBytes := ms.cdc.MustMarshalBinaryLengthPrefixed(state)
+	batch.Set([]byte(LastCommitStateKey), stateBytes)
+}
+
+// lastCommitState returns the state written with the latest commit, it falls back to the latest commit info without
+// the chain id for the db committed before the state is persisted
+func (ms *IavlStoreMulti) lastCommitState() ankrcmm.CommitState {
+	stateBytes := ms.db.Get([]byte(LastCommitStateKey))
+	if stateBytes != nil {
+		var state ankrcmm.CommitState
+		ms.cdc.MustUnmarshalBinaryLengthPrefixed(stateBytes, &state)
+		return state
+	}
+
+	cmmID := ms.lastCommit()
+
+	return ankrcmm.CommitState{Height: cmmID.Version, AppHash: cmmID.Hash}
+}
+
 func (ms *IavlStoreMulti) lastCommit() ankrcmm.CommitID {
 	latestVer := ms.latestVersion()
 	if latestVer == 0 {
@@ -157,7 +218,9 @@ func (ms *IavlStoreMulti) lastCommit() ankrcmm.CommitID {
     return ankrcmm.CommitID{}
 }
 
-func (ms *IavlStoreMulti) Commit(version int64, totalTx int64) ankrcmm.CommitID {
+// Commit saves a new version of the stores. The app hash is the total tx count, or the one committing to the root hashes
+// of the stores too if hashStores, see storesAppHash.
+func (ms *IavlStoreMulti) Commit(version int64, totalTx int64, hashStores bool) ankrcmm.CommitID {
 	var cmmInfo commitInfo
 
 	version += 1
@@ -166,7 +229,6 @@ func (ms *IavlStoreMulti) Commit(version int64, totalTx int64) ankrcmm.CommitID
 	binary.PutVarint(appHash, totalTx)
 
 	cmmInfo.Version = version
-	cmmInfo.AppHash = appHash
 
 	hashM := make(map[string][]byte)
 	for k, s := range ms.storeMap {
@@ -180,13 +242,22 @@ func (ms *IavlStoreMulti) Commit(version int64, totalTx int64) ankrcmm.CommitID
 		cmmInfo.Commits = append(cmmInfo.Commits, storeCommitID{k,commitID})
 	}
 
+	if hashStores {
+		appHash = storesAppHash(totalTx, cmmInfo.Commits)
+	}
+	cmmInfo.AppHash = appHash
+
+	chainID, _ := ms.storeMap[IAvlStoreMainKey].Get([]byte(ChainIDKey))
+
 	batch := ms.db.NewBatch()
 	defer batch.Close()
 
+	ms.prune(batch, version)
 	ms.setCommitInfo(batch, version, cmmInfo)
 	ms.setLatestVersion(batch, version)
+	ms.setLastCommitState(batch, ankrcmm.CommitState{version, appHash, string(chainID)})
 
-	batch.Write()
+	batch.WriteSync()
 
 	return ankrcmm.CommitID{version, cmmInfo.AppHash}
 }
@@ -33,11 +33,11 @@ func boundInfoKey(contractAddr string, actionName string) []byte {
 }
 
 func (sp *IavlStoreApp) AddRole(rType ankrcmm.RoleType, name string, pubKey string, contractAddr string) {
-	if !sp.iavlSM.IavlStore(IavlStorePermKey).Has([]byte(containRolePrefix(name))) {
+	if !sp.kvStore(IavlStorePermKey).Has([]byte(containRolePrefix(name))) {
 		rInfo := &ankrcmm.RoleInfo{name, rType, pubKey, contractAddr}
 		bytes := ankrcmm.EncodeRoleInfo(sp.cdc, rInfo)
 
-		sp.iavlSM.IavlStore(IavlStorePermKey).Set([]byte(containRolePrefix(rInfo.Name)), bytes)
+		sp.kvStore(IavlStorePermKey).Set([]byte(containRolePrefix(rInfo.Name)), bytes)
 	}
 }
 
@@ -46,7 +46,7 @@ func (sp *IavlStoreApp) LoadRole(name string, height int64, prove bool) (*ankrcm
 		return nil, "", nil, nil, errors.New("LoadRole, blank name")
 	}
 
-	rInfoBytes, proof, err := sp.iavlSM.IavlStore(IavlStorePermKey).GetWithVersionProve([]byte(containRolePrefix(name)), height, prove)
+	rInfoBytes, proof, err := sp.kvStore(IavlStorePermKey).GetWithVersionProve([]byte(containRolePrefix(name)), height, prove)
 	if err != nil || len(rInfoBytes) == 0 {
 		sp.storeLog.Error("can't get the role info", "name", name)
 		return nil, containRolePrefix(name), nil, nil, err
@@ -80,15 +80,15 @@ func (sp *IavlStoreApp) RoleQuery(name string, height int64, prove bool) (*ankrc
 
 func (sp *IavlStoreApp) AddBoundAction(roleName string, contractAddr string, actionName string) {
 	key := boundInfoKey(contractAddr, actionName)
-	if !sp.iavlSM.IavlStore(IavlStorePermKey).Has(key) {
+	if !sp.kvStore(IavlStorePermKey).Has(key) {
 
 		rbaInfo     := &ankrcmm.RoleBoundActionInfo{roleName, contractAddr, actionName}
 		rbaInfoList := &ankrcmm.RoleBoundActionInfoList{[]*ankrcmm.RoleBoundActionInfo{rbaInfo}}
 		bytes       := ankrcmm.EncodeBoundActionInfoList(sp.cdc, rbaInfoList)
 
-		sp.iavlSM.IavlStore(IavlStorePermKey).Set(key, bytes)
+		sp.kvStore(IavlStorePermKey).Set(key, bytes)
 	}else {
-		bytes, err  := sp.iavlSM.IavlStore(IavlStorePermKey).Get(key)
+		bytes, err  := sp.kvStore(IavlStorePermKey).Get(key)
 		if err == nil && bytes != nil{
 			rbaInfoList := ankrcmm.DecodeBoundActionInfoList(sp.cdc, bytes)
 			for _, rbaInfo := range rbaInfoList.RoleBounds {
@@ -102,7 +102,7 @@ func (sp *IavlStoreApp) AddBoundAction(roleName string, contractAddr string, act
 
 			bytes := ankrcmm.EncodeBoundActionInfoList(sp.cdc, &rbaInfoList)
 
-			sp.iavlSM.IavlStore(IavlStorePermKey).Set(key, bytes)
+			sp.kvStore(IavlStorePermKey).Set(key, bytes)
 		}else {
 			sp.storeLog.Error("can't load role bound info", "contractAddr", contractAddr, "actionName", actionName)
 		}
@@ -111,12 +111,12 @@ func (sp *IavlStoreApp) AddBoundAction(roleName string, contractAddr string, act
 
 func (sp *IavlStoreApp) LoadBoundAction(contractAddr string, actionName string) ankrcmm.RoleBoundActionInfoList {
 	key := boundInfoKey(contractAddr, actionName)
-	if !sp.iavlSM.IavlStore(IavlStorePermKey).Has(key) {
+	if !sp.kvStore(IavlStorePermKey).Has(key) {
 		sp.storeLog.Error("can't load role bound info", "contractAddr", contractAddr, "actionName", actionName)
 		return ankrcmm.RoleBoundActionInfoList{}
 	}
 
-	bytes, err := sp.iavlSM.IavlStore(IavlStorePermKey).Get(key)
+	bytes, err := sp.kvStore(IavlStorePermKey).Get(key)
 	if err == nil && bytes != nil {
 		rbaInfoList := ankrcmm.DecodeBoundActionInfoList(sp.cdc, bytes)
 		return rbaInfoList
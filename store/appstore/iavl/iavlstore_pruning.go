@@ -0,0 +1,105 @@
+package iavl
+
+import (
+	"sync/atomic"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+const (
+	PrunedVerKey = "prunedverkey"
+
+	// MaxPrunedVersionsPerCommit bounds the versions deleted by one commit, so the history kept before the pruning is
+	// enabled is deleted over the following commits instead of stalling one block
+	MaxPrunedVersionsPerCommit = 100
+)
+
+// DefaultPruningOptions keeps the latest 100 versions
+func DefaultPruningOptions() ankrcmm.PruningOptions {
+	return ankrcmm.PruningOptions{ankrcmm.PruningEverything, 100, 0}
+}
+
+func (ms *IavlStoreMulti) SetPruningOptions(opts ankrcmm.PruningOptions) {
+	ms.pruning = opts
+}
+
+func (ms *IavlStoreMulti) prunedVersion() int64 {
+	verBytes := ms.db.Get([]byte(PrunedVerKey))
+	if verBytes == nil {
+		return 0
+	}
+
+	var prunedVer int64
+	ms.cdc.MustUnmarshalBinaryLengthPrefixed(verBytes, &prunedVer)
+
+	return prunedVer
+}
+
+// prune deletes the versions not kept by the pruning options after the version is saved, and the version all the
+// versions before it have been checked is written into the batch. The version being snapshotted and the ones after it
+// are kept until the snapshot is finished.
+func (ms *IavlStoreMulti) prune(batch dbm.Batch, version int64) {
+	if ms.pruning.Strategy == ankrcmm.PruningNothing {
+		return
+	}
+
+	pruneTo := version - ms.pruning.KeepRecent
+	if snapshotVer := atomic.LoadInt64(&ms.snapshotVersion); snapshotVer > 0 && snapshotVer <= pruneTo {
+		pruneTo = snapshotVer - 1
+	}
+
+	prunedVer := ms.prunedVersion()
+	if pruneTo <= prunedVer {
+		return
+	}
+
+	prunedCount := 0
+	for ver := prunedVer + 1; ver <= pruneTo; ver++ {
+		if !ms.pruning.ShouldPrune(ver, version) {
+			prunedVer = ver
+			continue
+		}
+
+		if prunedCount == MaxPrunedVersionsPerCommit {
+			break
+		}
+
+		for key, iavlS := range ms.storeMap {
+			if !iavlS.VersionExists(ver) {
+				continue
+			}
+
+			if err := iavlS.DeleteVersion(ver); err != nil {
+				panic(err)
+			}
+
+			ms.log.Debug("IavlStoreMulti pruned version", "key", key, "ver", ver)
+			prunedCount++
+		}
+
+		prunedVer = ver
+	}
+
+	prunedVerBytes, _ := ms.cdc.MarshalBinaryLengthPrefixed(prunedVer)
+	batch.Set([]byte(PrunedVerKey), prunedVerBytes)
+}
+
+// VersionPruned tells if the version committed has been deleted by the pruning
+func (ms *IavlStoreMulti) VersionPruned(version int64) bool {
+	if version <= 0 || version > ms.latestVersion() {
+		return false
+	}
+
+	for _, iavlS := range ms.storeMap {
+		if !iavlS.VersionExists(version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (sp *IavlStoreApp) SetPruningOptions(opts ankrcmm.PruningOptions) {
+	sp.iavlSM.SetPruningOptions(opts)
+}
@@ -0,0 +1,74 @@
+package iavl
+
+import (
+	"fmt"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+func TestIavlStorePruning(t *testing.T) {
+	_, err := ankrcmm.NewPruningOptions(ankrcmm.PruningKeepEvery, 3, 0)
+	assert.NotEqual(t, nil, err)
+
+	opts, err := ankrcmm.NewPruningOptions(ankrcmm.PruningNothing, 0, 0)
+	assert.Equal(t, nil, err)
+
+	appStore := NewMockIavlStoreApp()
+	appStore.SetPruningOptions(opts)
+
+	commit := func(count int) {
+		for i := 0; i < count; i++ {
+			appStore.SetCertKey("dc1", fmt.Sprintf("pem%d", appStore.Height()+1))
+			appStore.IncTotalTx()
+			appStore.Commit()
+		}
+	}
+
+	commit(6)
+	for ver := int64(1); ver <= 6; ver++ {
+		assert.Equal(t, false, appStore.iavlSM.VersionPruned(ver))
+	}
+
+	// the history kept by the pruning nothing is pruned after the strategy is changed
+	opts, err = ankrcmm.NewPruningOptions(ankrcmm.PruningKeepEvery, 3, 5)
+	assert.Equal(t, nil, err)
+	appStore.SetPruningOptions(opts)
+
+	commit(6)
+	for ver := int64(1); ver <= 12; ver++ {
+		assert.Equal(t, ver < 10 && ver != 5, appStore.iavlSM.VersionPruned(ver), "ver %d", ver)
+	}
+	assert.Equal(t, int64(9), appStore.iavlSM.prunedVersion())
+
+	reqBytes, _ := appStore.cdc.MarshalJSON(&ankrcmm.CertKeyQueryReq{"dc1"})
+	resQuery := appStore.Query(types.RequestQuery{Path: "certkey", Data: reqBytes, Height: 4})
+	assert.Equal(t, code.CodeTypeHeightPruned, resQuery.Code)
+
+	rangeReqBytes, _ := appStore.cdc.MarshalJSON(&ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: []byte(StoreCertKeyPrefix)})
+	resQuery = appStore.Query(types.RequestQuery{Path: RangeQueryPath, Data: rangeReqBytes, Height: 9})
+	assert.Equal(t, code.CodeTypeHeightPruned, resQuery.Code)
+
+	resQuery = appStore.Query(types.RequestQuery{Path: RangeQueryPath, Data: rangeReqBytes, Height: 5})
+	assert.Equal(t, code.CodeTypeOK, resQuery.Code)
+
+	resQuery = appStore.Query(types.RequestQuery{Path: RangeQueryPath, Data: rangeReqBytes, Height: 10, Prove: true})
+	assert.Equal(t, code.CodeTypeOK, resQuery.Code)
+
+	// the versions from the one being snapshotted are kept
+	appStore.iavlSM.snapshotVersion = 11
+	commit(3)
+	assert.Equal(t, false, appStore.iavlSM.VersionPruned(11))
+	assert.Equal(t, false, appStore.iavlSM.VersionPruned(12))
+	assert.Equal(t, int64(10), appStore.iavlSM.prunedVersion())
+
+	appStore.iavlSM.snapshotVersion = 0
+	commit(1)
+	assert.Equal(t, true, appStore.iavlSM.VersionPruned(11))
+	assert.Equal(t, true, appStore.iavlSM.VersionPruned(12))
+	assert.Equal(t, true, appStore.iavlSM.VersionPruned(13))
+	assert.Equal(t, false, appStore.iavlSM.VersionPruned(14))
+}
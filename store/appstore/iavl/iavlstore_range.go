@@ -0,0 +1,280 @@
+package iavl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/iavl"
+	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/merkle"
+)
+
+const (
+	RangeQueryPath    = "range"
+	DefaultRangeLimit = 100
+	MaxRangeLimit     = 1000
+)
+
+// GetRange iterates the keys in [start, end) of the version, nil means unbounded. At most limit kv pairs are returned
+// if limit > 0, and nextKey is the key the next page starts from. The uncommitted tree is iterated if ver <= 0 and the
+// proof isn't required.
+func (s *IavlStore) GetRange(start, end []byte, reverse bool, limit int, ver int64, prove bool) (keys [][]byte, values [][]byte, nextKey []byte, proof *iavl.RangeProof, err error) {
+	tree := s.tree.ImmutableTree
+	if ver > 0 || prove {
+		if ver <= 0 {
+			ver = s.Version()
+		}
+
+		if !s.VersionExists(ver) {
+			return nil, nil, nil, nil, iavl.ErrVersionDoesNotExist
+		}
+
+		tree, err = s.GetImmutable(ver)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+
+	if start == nil || end == nil || bytes.Compare(start, end) < 0 {
+		tree.IterateRange(start, end, !reverse, func(key []byte, value []byte) bool {
+			if limit > 0 && len(keys) == limit {
+				nextKey = key
+				return true
+			}
+
+			keys   = append(keys, key)
+			values = append(values, value)
+
+			return false
+		})
+	}
+
+	if !prove {
+		return
+	}
+
+	// the range proof is built in the ascending order, so the one of the reverse page starts from its last key. The proof
+	// includes the leaf before the range and the one after it, which prove no other key is in the range.
+	proofStart := start
+	if nextKey != nil && reverse {
+		proofStart = keys[len(keys)-1]
+	}
+
+	_, _, proof, err = tree.GetRangeWithProof(proofStart, nil, len(keys)+2)
+
+	return
+}
+
+// RangeBounds returns the range [start, end) of the range query req, nil means unbounded
+func RangeBounds(req *ankrcmm.RangeQueryReq) (start []byte, end []byte) {
+	if len(req.Prefix) > 0 {
+		start = req.Prefix
+		end   = prefixEndBytes(req.Prefix)
+	}
+
+	if len(req.Start) > 0 {
+		start = append(append([]byte{}, req.Prefix...), req.Start...)
+	}
+
+	if len(req.End) > 0 {
+		end = append(append([]byte{}, req.Prefix...), req.End...)
+	}
+
+	if len(req.Cursor) == 0 {
+		return
+	}
+
+	if req.Reverse {
+		cursorEnd := append(append([]byte{}, req.Cursor...), 0x00)
+		if end == nil || bytes.Compare(cursorEnd, end) < 0 {
+			end = cursorEnd
+		}
+	} else if start == nil || bytes.Compare(req.Cursor, start) > 0 {
+		start = req.Cursor
+	}
+
+	return
+}
+
+func rangeLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRangeLimit
+	}
+
+	if limit > MaxRangeLimit {
+		return MaxRangeLimit
+	}
+
+	return limit
+}
+
+// Range lists a page of the kv pairs of the store in the range of the req, the proof is nil if the store is empty
+func (sp *IavlStoreApp) Range(req *ankrcmm.RangeQueryReq, height int64, prove bool) (*ankrcmm.RangeQueryResp, *iavl.RangeProof, error) {
+	iavlS, ok := sp.iavlSM.storeMap[req.Store]
+	if !ok {
+		return nil, nil, fmt.Errorf("IavlStoreApp Range, invalid store name: %s", req.Store)
+	}
+
+	start, end := RangeBounds(req)
+	keys, values, nextKey, proof, err := iavlS.GetRange(start, end, req.Reverse, rangeLimit(req.Limit), height, prove)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp := &ankrcmm.RangeQueryResp{NextKey: nextKey}
+	for i, key := range keys {
+		resp.KVs = append(resp.KVs, ankrcmm.KVPair{key, values[i]})
+	}
+
+	if proof != nil {
+		resp.Proof, err = sp.cdc.MarshalBinaryLengthPrefixed(proof)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return resp, proof, nil
+}
+
+// rangeQuery serves the range query of any store, and the proof of the store root is attached if required. The range
+// proof itself is returned in the resp, since it proves a page of the kv pairs instead of one key.
+func (sp *IavlStoreApp) rangeQuery(reqQuery types.RequestQuery) (resQuery types.ResponseQuery) {
+	var req ankrcmm.RangeQueryReq
+	err := sp.cdc.UnmarshalJSON(reqQuery.Data, &req)
+	if err != nil {
+		resQuery.Code = code.CodeTypeQueryInvalidQueryReqData
+		resQuery.Log  = fmt.Sprintf("invalid range query req data, err=%s", err.Error())
+		return
+	}
+
+	if _, ok := sp.iavlSM.storeMap[req.Store]; !ok {
+		resQuery.Code = code.CodeTypeQueryInvalidStoreName
+		resQuery.Log  = fmt.Sprintf("invalid range query store name: %s", req.Store)
+		return
+	}
+
+	qVer := reqQuery.Height
+	if qVer == 0 {
+		qVer = sp.lastCommitID.Version
+	}
+
+	resp, _, err := sp.Range(&req, qVer, reqQuery.Prove)
+	if err != nil {
+		resQuery.Code = code.CodeTypeLoadBalError
+		resQuery.Log  = fmt.Sprintf("load range query err, err=%s", err.Error())
+		return
+	}
+
+	respBytes, _ := sp.cdc.MarshalJSON(resp)
+	resQDataBytes, _ := sp.cdc.MarshalJSON(&ankrcmm.QueryResp{respBytes, nil})
+
+	resQuery.Code   = code.CodeTypeOK
+	resQuery.Value  = resQDataBytes
+	resQuery.Height = qVer
+	resQuery.Key    = []byte(req.Store)
+
+	if reqQuery.Prove {
+		if commInfo := sp.iavlSM.commitInfo(qVer); commInfo != nil {
+			pOP := NewIavlStoreMultiOp([]byte(req.Store), &IavlStoreMultiProof{*commInfo}).ProofOp()
+			resQuery.Proof = &merkle.Proof{Ops: []merkle.ProofOp{pOP}}
+		}
+	}
+
+	return
+}
+
+// VerifyRangeProof verifies the page of the range query against its range proof and returns the root hash of the store
+// proved. All the keys of the store in the range up to the end of the page must be in the page.
+func VerifyRangeProof(req *ankrcmm.RangeQueryReq, resp *ankrcmm.RangeQueryResp) ([]byte, error) {
+	if len(resp.Proof) == 0 {
+		if len(resp.KVs) > 0 {
+			return nil, errors.New("no range proof of the kv pairs")
+		}
+
+		// the store is empty
+		return nil, nil
+	}
+
+	var proof iavl.RangeProof
+	if err := amino.NewCodec().UnmarshalBinaryLengthPrefixed(resp.Proof, &proof); err != nil {
+		return nil, fmt.Errorf("invalid range proof: %v", err)
+	}
+
+	rootHash := proof.ComputeRootHash()
+	if err := proof.Verify(rootHash); err != nil {
+		return nil, err
+	}
+
+	var pageKeys [][]byte
+	for _, kv := range resp.KVs {
+		if err := proof.VerifyItem(kv.Key, kv.Value); err != nil {
+			return nil, fmt.Errorf("kv pair not proved, key=%X: %v", kv.Key, err)
+		}
+
+		if req.Reverse {
+			pageKeys = append([][]byte{kv.Key}, pageKeys...)
+		} else {
+			pageKeys = append(pageKeys, kv.Key)
+		}
+	}
+
+	// the page truncated is proved up to its last key
+	lower, upper := RangeBounds(req)
+	truncated := resp.NextKey != nil && len(pageKeys) > 0
+	if truncated {
+		if req.Reverse {
+			lower = pageKeys[0]
+		} else {
+			upper = append(append([]byte{}, pageKeys[len(pageKeys)-1]...), 0x00)
+		}
+	}
+
+	proofKeys := proof.Keys()
+	if lower == nil {
+		if proof.LeftIndex() != 0 {
+			return nil, errors.New("range proof doesn't start from the first key")
+		}
+	} else if !containsKey(proofKeys, lower) && proof.VerifyAbsence(lower) != nil {
+		return nil, fmt.Errorf("range proof doesn't cover the start key %X", lower)
+	}
+
+	lastKey := proofKeys[len(proofKeys)-1]
+	if !(truncated && !req.Reverse) && (upper == nil || bytes.Compare(lastKey, upper) < 0) {
+		if err := proof.VerifyAbsence(append(append([]byte{}, lastKey...), 0x00)); err != nil {
+			return nil, fmt.Errorf("range proof doesn't cover the end key %X: %v", upper, err)
+		}
+	}
+
+	var provedKeys [][]byte
+	for _, key := range proofKeys {
+		if (lower == nil || bytes.Compare(key, lower) >= 0) && (upper == nil || bytes.Compare(key, upper) < 0) {
+			provedKeys = append(provedKeys, key)
+		}
+	}
+
+	if len(provedKeys) != len(pageKeys) {
+		return nil, fmt.Errorf("range proof has %d keys in the range, but %d kv pairs returned", len(provedKeys), len(pageKeys))
+	}
+
+	for i, key := range provedKeys {
+		if !bytes.Equal(key, pageKeys[i]) {
+			return nil, fmt.Errorf("key %X proved in the range isn't returned", key)
+		}
+	}
+
+	return rootHash, nil
+}
+
+func containsKey(keys [][]byte, key []byte) bool {
+	for _, k := range keys {
+		if bytes.Equal(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,112 @@
+package iavl
+
+import (
+	"fmt"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+func rangeAllPages(t *testing.T, appStore *IavlStoreApp, req *ankrcmm.RangeQueryReq, height int64, storeRoot []byte) []string {
+	var keys []string
+	for {
+		resp, _, err := appStore.Range(req, height, true)
+		assert.Equal(t, nil, err)
+
+		root, err := VerifyRangeProof(req, resp)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, storeRoot, root)
+
+		for _, kv := range resp.KVs {
+			keys = append(keys, string(kv.Key))
+		}
+
+		if resp.NextKey == nil {
+			return keys
+		}
+
+		req.Cursor = resp.NextKey
+	}
+}
+
+func TestIavlStoreRange(t *testing.T) {
+	appStore := NewMockIavlStoreApp()
+	for i := 0; i < 5; i++ {
+		appStore.SetMetering("dc1", fmt.Sprintf("ns%d", i), fmt.Sprintf("%d", i))
+	}
+	appStore.SetMetering("dc2", "ns0", "0")
+	appStore.SetCertKey("dc1", "pem")
+	appStore.IncTotalTx()
+	appStore.Commit()
+
+	appStore.SetMetering("dc1", "ns5", "5")
+	appStore.IncTotalTx()
+	appStore.Commit()
+
+	storeRoot := func(height int64) []byte {
+		_, storeHashes, err := appStore.StoreHashes(height)
+		assert.Equal(t, nil, err)
+		for _, storeHash := range storeHashes {
+			if storeHash.Name == IAvlStoreMainKey {
+				return storeHash.Hash
+			}
+		}
+		return nil
+	}
+
+	prefix := []byte(containMeteringPrefix("dc1_"))
+
+	keys := rangeAllPages(t, appStore, &ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: prefix, Limit: 2}, 2, storeRoot(2))
+	assert.Equal(t, 6, len(keys))
+	assert.Equal(t, containMeteringPrefix("dc1_ns0"), keys[0])
+	assert.Equal(t, containMeteringPrefix("dc1_ns5"), keys[5])
+
+	keys = rangeAllPages(t, appStore, &ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: prefix, Reverse: true, Limit: 4}, 2, storeRoot(2))
+	assert.Equal(t, 6, len(keys))
+	assert.Equal(t, containMeteringPrefix("dc1_ns5"), keys[0])
+	assert.Equal(t, containMeteringPrefix("dc1_ns0"), keys[5])
+
+	keys = rangeAllPages(t, appStore, &ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: prefix, Start: []byte("ns1"), End: []byte("ns3")}, 2, storeRoot(2))
+	assert.Equal(t, []string{containMeteringPrefix("dc1_ns1"), containMeteringPrefix("dc1_ns2")}, keys)
+
+	keys = rangeAllPages(t, appStore, &ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: prefix, Limit: 3}, 1, storeRoot(1))
+	assert.Equal(t, 5, len(keys))
+
+	keys = rangeAllPages(t, appStore, &ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: []byte(containMeteringPrefix("dc3_"))}, 2, storeRoot(2))
+	assert.Equal(t, 0, len(keys))
+
+	// a page missing a key in the range is refused
+	req := &ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: prefix, Limit: 3}
+	resp, _, err := appStore.Range(req, 2, true)
+	assert.Equal(t, nil, err)
+	resp.KVs = append(resp.KVs[:1], resp.KVs[2:]...)
+	_, err = VerifyRangeProof(req, resp)
+	assert.NotEqual(t, nil, err)
+
+	_, _, err = appStore.Range(&ankrcmm.RangeQueryReq{Store: "unknown"}, 0, false)
+	assert.NotEqual(t, nil, err)
+
+	reqBytes, _ := appStore.cdc.MarshalJSON(&ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: prefix})
+	resQuery := appStore.Query(types.RequestQuery{Path: RangeQueryPath, Data: reqBytes, Prove: true})
+	assert.Equal(t, code.CodeTypeOK, resQuery.Code)
+	assert.Equal(t, int64(2), resQuery.Height)
+	assert.Equal(t, 1, len(resQuery.Proof.Ops))
+
+	var qResp ankrcmm.QueryResp
+	assert.Equal(t, nil, appStore.cdc.UnmarshalJSON(resQuery.Value, &qResp))
+	var rangeResp ankrcmm.RangeQueryResp
+	assert.Equal(t, nil, appStore.cdc.UnmarshalJSON(qResp.RespData, &rangeResp))
+	assert.Equal(t, 6, len(rangeResp.KVs))
+
+	root, err := VerifyRangeProof(&ankrcmm.RangeQueryReq{Store: IAvlStoreMainKey, Prefix: prefix}, &rangeResp)
+	assert.Equal(t, nil, err)
+
+	op, err := IavlStoreMultiOpDecoder(resQuery.Proof.Ops[0])
+	assert.Equal(t, nil, err)
+	appHash, err := op.Run([][]byte{root})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, appStore.APPHash(), appHash[0])
+}
@@ -0,0 +1,169 @@
+package iavl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+)
+
+const (
+	AppHashMismatchKey = "apphashmismatch"
+	RollbackVersionKey = "rollbackverkey"
+)
+
+func (ms *IavlStoreMulti) commitInfoExists(version int64) bool {
+	return ms.db.Has([]byte(fmt.Sprintf(CommitInfoKey, version)))
+}
+
+// StoreHashes returns the app hash and the root hashes of the stores committed at the version
+func (ms *IavlStoreMulti) StoreHashes(version int64) ([]byte, []ankrcmm.StoreHash, error) {
+	if !ms.commitInfoExists(version) {
+		return nil, nil, fmt.Errorf("no commit info at version %d", version)
+	}
+
+	cmmInfo := ms.commitInfo(version)
+
+	var storeHashes []ankrcmm.StoreHash
+	for _, cmmID := range cmmInfo.Commits {
+		storeHashes = append(storeHashes, ankrcmm.StoreHash{cmmID.Name, cmmID.CID.Version, cmmID.CID.Hash})
+	}
+
+	return cmmInfo.AppHash, storeHashes, nil
+}
+
+// LoadVersion rolls the stores back to the committed version, and the versions after it are deleted. The version is
+// persisted before the stores are overwritten, so a rollback interrupted is finished when the stores are loaded again.
+func (ms *IavlStoreMulti) LoadVersion(version int64) error {
+	latestVer := ms.latestVersion()
+	if version <= 0 || version > latestVer {
+		return fmt.Errorf("can't roll back to version %d, the latest version is %d", version, latestVer)
+	}
+
+	if !ms.commitInfoExists(version) {
+		return fmt.Errorf("can't roll back to version %d, no commit info", version)
+	}
+
+	for key, iavlS := range ms.storeMap {
+		if !iavlS.VersionExists(version) {
+			return fmt.Errorf("can't roll back to version %d, it has been pruned from %s", version, key)
+		}
+	}
+
+	verBytes, _ := ms.cdc.MarshalBinaryLengthPrefixed(version)
+	ms.db.SetSync([]byte(RollbackVersionKey), verBytes)
+
+	return ms.rollback(version)
+}
+
+func (ms *IavlStoreMulti) rollback(version int64) error {
+	for key, iavlS := range ms.storeMap {
+		if _, err := iavlS.LoadVersionForOverwriting(version); err != nil {
+			return fmt.Errorf("roll back %s to version %d failed: %v", key, version, err)
+		}
+	}
+
+	batch := ms.db.NewBatch()
+	defer batch.Close()
+
+	for ver := ms.latestVersion(); ver > version; ver-- {
+		batch.Delete([]byte(fmt.Sprintf(CommitInfoKey, ver)))
+	}
+
+	chainID, _ := ms.storeMap[IAvlStoreMainKey].Get([]byte(ChainIDKey))
+
+	ms.setLatestVersion(batch, version)
+	ms.setLastCommitState(batch, ankrcmm.CommitState{version, ms.commitInfo(version).AppHash, string(chainID)})
+	batch.Delete([]byte(RollbackVersionKey))
+
+	batch.WriteSync()
+
+	ms.log.Info("IavlStoreMulti rolled back", "version", version)
+
+	return nil
+}
+
+// resumeRollback finishes the rollback interrupted last time
+func (ms *IavlStoreMulti) resumeRollback() {
+	verBytes := ms.db.Get([]byte(RollbackVersionKey))
+	if verBytes == nil {
+		return
+	}
+
+	var version int64
+	ms.cdc.MustUnmarshalBinaryLengthPrefixed(verBytes, &version)
+
+	ms.log.Info("IavlStoreMulti resumes the rollback interrupted", "version", version)
+
+	if err := ms.rollback(version); err != nil {
+		panic(err)
+	}
+}
+
+// RecordAppHashMismatch persists the mismatch of the app hash at the height with the diff of the store root hashes
+// between the height and the last agreed one. The record is kept out of the stores, so it doesn't change the app state.
+func (sp *IavlStoreApp) RecordAppHashMismatch(height int64, expectedAppHash []byte) *ankrcmm.AppHashMismatch {
+	mismatch := &ankrcmm.AppHashMismatch{Height: height, AgreedHeight: height - 1, ExpectedAppHash: expectedAppHash}
+
+	appHash, storeHashes, err := sp.iavlSM.StoreHashes(height)
+	if err != nil {
+		sp.storeLog.Error("IavlStoreApp RecordAppHashMismatch can't get the store hashes", "height", height, "err", err)
+	}
+	mismatch.AppHash = appHash
+
+	agreedHashes := make(map[string][]byte)
+	if _, agreedStoreHashes, err := sp.iavlSM.StoreHashes(height - 1); err == nil {
+		for _, storeHash := range agreedStoreHashes {
+			agreedHashes[storeHash.Name] = storeHash.Hash
+		}
+	}
+
+	for _, storeHash := range storeHashes {
+		agreedHash := agreedHashes[storeHash.Name]
+		mismatch.Stores = append(mismatch.Stores, ankrcmm.StoreHashDiff{storeHash.Name, agreedHash, storeHash.Hash, !bytes.Equal(agreedHash, storeHash.Hash)})
+	}
+
+	mismatchBytes, _ := sp.cdc.MarshalJSON(mismatch)
+	sp.iavlSM.db.SetSync([]byte(AppHashMismatchKey), mismatchBytes)
+
+	return mismatch
+}
+
+// AppHashMismatch returns the last mismatch recorded, nil if there isn't any
+func (sp *IavlStoreApp) AppHashMismatch() *ankrcmm.AppHashMismatch {
+	mismatchBytes := sp.iavlSM.db.Get([]byte(AppHashMismatchKey))
+	if mismatchBytes == nil {
+		return nil
+	}
+
+	var mismatch ankrcmm.AppHashMismatch
+	if err := sp.cdc.UnmarshalJSON(mismatchBytes, &mismatch); err != nil {
+		sp.storeLog.Error("IavlStoreApp AppHashMismatch unmarshal failed", "err", err)
+		return nil
+	}
+
+	return &mismatch
+}
+
+func (sp *IavlStoreApp) StoreHashes(height int64) ([]byte, []ankrcmm.StoreHash, error) {
+	return sp.iavlSM.StoreHashes(height)
+}
+
+// LoadVersion rolls the app store back to the height, and the uncommitted changes are discarded
+func (sp *IavlStoreApp) LoadVersion(height int64) error {
+	if err := sp.iavlSM.LoadVersion(height); err != nil {
+		return err
+	}
+
+	sp.lastCommitID = sp.iavlSM.lastCommit()
+
+	totalTxBytes, err := sp.iavlSM.IavlStore(IAvlStoreMainKey).Get([]byte(TotalTxKey))
+	if err == nil && len(totalTxBytes) > 0 {
+		sp.totalTx, _ = binary.Varint(totalTxBytes)
+	}
+
+	sp.storeLog.Info("IavlStoreApp rolled back", "height", sp.lastCommitID.Version, "totalTx", sp.totalTx)
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package iavl
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestIavlStoreRecovery(t *testing.T) {
+	appStore := NewMockIavlStoreApp()
+	for i := 0; i < 4; i++ {
+		appStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(int64(1000 + i)).Bytes()})
+		appStore.IncTotalTx()
+		appStore.Commit()
+	}
+
+	assert.Equal(t, (*ankrcmm.AppHashMismatch)(nil), appStore.AppHashMismatch())
+
+	mismatch := appStore.RecordAppHashMismatch(4, []byte{0x01})
+	assert.Equal(t, int64(3), mismatch.AgreedHeight)
+	assert.Equal(t, appStore.APPHashByHeight(4), mismatch.AppHash)
+	assert.Equal(t, 4, len(mismatch.Stores))
+	for _, storeDiff := range mismatch.Stores {
+		assert.Equal(t, storeDiff.Name == IavlStoreAccountKey || storeDiff.Name == IAvlStoreMainKey, storeDiff.Changed)
+	}
+	assert.Equal(t, mismatch, appStore.AppHashMismatch())
+
+	assert.NotEqual(t, nil, appStore.LoadVersion(5))
+
+	assert.Equal(t, nil, appStore.LoadVersion(2))
+	assert.Equal(t, int64(2), appStore.Height())
+	assert.Equal(t, appStore.APPHashByHeight(2), appStore.APPHash())
+
+	_, _, err := appStore.StoreHashes(3)
+	assert.NotEqual(t, nil, err)
+
+	bal, _, _, _, err := appStore.Balance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", "ANKR", 0, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1001", bal.String())
+
+	appStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(2000).Bytes()})
+	appStore.Commit()
+	assert.Equal(t, int64(3), appStore.Height())
+
+	// the rollback interrupted is resumed when the stores are loaded again
+	verBytes, _ := appStore.iavlSM.cdc.MarshalBinaryLengthPrefixed(int64(1))
+	appStore.iavlSM.db.Set([]byte(RollbackVersionKey), verBytes)
+
+	iavlSM := NewIavlStoreMulti(appStore.iavlSM.db, log.NewNopLogger())
+	iavlSM.Load()
+	assert.Equal(t, int64(1), iavlSM.lastCommit().Version)
+	assert.Equal(t, int64(1), iavlSM.IavlStore(IavlStoreAccountKey).Version())
+	assert.Equal(t, false, iavlSM.db.Has([]byte(RollbackVersionKey)))
+}
+
+func TestIavlStoreCommitInterrupted(t *testing.T) {
+	dbDir, err := ioutil.TempDir("", "iavlstore")
+	assert.Equal(t, nil, err)
+	defer os.RemoveAll(dbDir)
+
+	appStore := NewIavlStoreApp(dbDir, log.NewNopLogger())
+	appStore.SetChainID("test-chain")
+	for i := 0; i < 2; i++ {
+		appStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(int64(1000 + i)).Bytes()})
+		appStore.IncTotalTx()
+		appStore.Commit()
+	}
+
+	// the node crashes after some stores are saved but before the commit info is written
+	appStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(3000).Bytes()})
+	appStore.IncTotalTx()
+	appStore.iavlSM.IavlStore(IavlStoreAccountKey).Commit()
+	appStore.iavlSM.IavlStore(IAvlStoreMainKey).Commit()
+
+	assert.Equal(t, true, appStore.iavlSM.IavlStore(IavlStoreAccountKey).VersionExists(3))
+	appStore.DB().Close()
+
+	appStore = NewIavlStoreApp(dbDir, log.NewNopLogger())
+	defer appStore.DB().Close()
+
+	state := appStore.LastCommitState()
+	assert.Equal(t, int64(2), state.Height)
+	assert.Equal(t, appStore.APPHashByHeight(2), state.AppHash)
+	assert.Equal(t, "test-chain", state.ChainID)
+	assert.Equal(t, int64(2), appStore.Height())
+	assert.Equal(t, state.AppHash, appStore.APPHash())
+	assert.Equal(t, false, appStore.iavlSM.IavlStore(IavlStoreAccountKey).VersionExists(3))
+
+	bal, _, _, _, err := appStore.Balance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", "ANKR", 0, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1001", bal.String())
+
+	// the block is replayed on the committed state
+	appStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(2000).Bytes()})
+	appStore.IncTotalTx()
+	appStore.Commit()
+
+	state = appStore.LastCommitState()
+	assert.Equal(t, int64(3), state.Height)
+	assert.Equal(t, appStore.APPHash(), state.AppHash)
+	assert.Equal(t, "test-chain", state.ChainID)
+}
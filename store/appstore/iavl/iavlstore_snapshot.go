@@ -0,0 +1,687 @@
+package iavl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"sync/atomic"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+const (
+	// SnapshotFormat holds the key values of the stores. The trees restored from them have root hashes other than the
+	// ones of the snapshot, and the app hash before the upgrade UpgradeStoreAppHash only commits to the total tx count,
+	// so nothing checks the state restored against the chain: the node serving the snapshot must be fully trusted.
+	SnapshotFormat uint32 = 1
+	// SnapshotFormatNodes holds the nodes of the trees, which are restored with the same root hashes. It's taken at the
+	// heights whose app hash commits to the root hashes of the stores, so every node restored is checked against the
+	// app hash.
+	SnapshotFormatNodes uint32 = 2
+
+	DefaultSnapshotChunkSize = 4 * 1024 * 1024
+
+	SnapshotKeyPrefix      = "snapshot:"
+	SnapshotChunkKeyPrefix = "snapshotchunk:"
+	VersionOffsetKey       = "veroffsetkey"
+)
+
+var (
+	ErrSnapshotFormatUnknown = errors.New("unknown snapshot format")
+	ErrSnapshotChunkInvalid  = errors.New("invalid snapshot chunk")
+)
+
+type snapshotKV struct {
+	Key   []byte
+	Value []byte
+}
+
+// snapshotChunk holds the key values of one store, the ones of a store may be split into several chunks in key order
+type snapshotChunk struct {
+	Store string
+	KVs   []snapshotKV
+}
+
+type snapshotStore struct {
+	Name    string
+	KVCount int64
+	KVHash  []byte
+}
+
+// snapshotMetadata of SnapshotFormat keeps the commit info at the snapshot height, and the count and the hash of every
+// store's key values. They only check the chunks against the metadata, which is served with them, and not against the
+// app hash, so they catch a chunk corrupted in transit but not a snapshot forged by the node serving it.
+type snapshotMetadata struct {
+	Commit      commitInfo
+	Stores      []snapshotStore
+	ChunkHashes [][]byte
+}
+
+// snapshotNodesMetadata of SnapshotFormatNodes keeps the commit info and the total tx count the app hash at the
+// snapshot height is computed from, so the root hashes of the stores are checked against the app hash before any chunk
+type snapshotNodesMetadata struct {
+	Commit      commitInfo
+	TotalTx     int64
+	ChunkHashes [][]byte
+}
+
+// snapshotNodesChunk holds the nodes of one store in the bytes the tree saves them as, the nodes of a store are split
+// into several chunks in pre-order
+type snapshotNodesChunk struct {
+	Store string
+	Nodes [][]byte
+}
+
+type snapshotRestorer struct {
+	info        *ankrcmm.SnapshotInfo
+	commit      commitInfo
+	chunkHashes [][]byte
+	nextChunk   uint32
+
+	// SnapshotFormat
+	stores    []snapshotStore
+	kvCounts  map[string]int64
+	kvHashers map[string]hash.Hash
+
+	// SnapshotFormatNodes
+	totalTx   int64
+	importers map[string]*snapshotNodesImporter
+}
+
+func snapshotKey(height int64, format uint32) []byte {
+	return []byte(fmt.Sprintf("%s%020d:%d", SnapshotKeyPrefix, height, format))
+}
+
+func snapshotChunkKey(height int64, format uint32, chunk uint32) []byte {
+	return []byte(fmt.Sprintf("%s%020d:%d:%d", SnapshotChunkKeyPrefix, height, format, chunk))
+}
+
+func writeSnapshotKV(hasher hash.Hash, key []byte, value []byte) {
+	lenBuf := make([]byte, binary.MaxVarintLen64)
+
+	n := binary.PutUvarint(lenBuf, uint64(len(key)))
+	hasher.Write(lenBuf[:n])
+	hasher.Write(key)
+
+	n = binary.PutUvarint(lenBuf, uint64(len(value)))
+	hasher.Write(lenBuf[:n])
+	hasher.Write(value)
+}
+
+func (ms *IavlStoreMulti) sortedStoreNames() []string {
+	var storeNames []string
+	for name, _ := range ms.storeMap {
+		storeNames = append(storeNames, name)
+	}
+	sort.Strings(storeNames)
+
+	return storeNames
+}
+
+func (ms *IavlStoreMulti) versionOffset() int64 {
+	offsetBytes := ms.db.Get([]byte(VersionOffsetKey))
+	if offsetBytes == nil {
+		return 0
+	}
+
+	var offset int64
+	ms.cdc.MustUnmarshalBinaryLengthPrefixed(offsetBytes, &offset)
+
+	return offset
+}
+
+func (ms *IavlStoreMulti) setVersionOffset(offset int64) {
+	offsetBytes := ms.cdc.MustMarshalBinaryLengthPrefixed(offset)
+	ms.db.SetSync([]byte(VersionOffsetKey), offsetBytes)
+
+	for _, iavlS := range ms.storeMap {
+		iavlS.setVersionOffset(offset)
+	}
+}
+
+// storesAppHash is the app hash from the upgrade UpgradeStoreAppHash, the simple merkle root of the total tx count and
+// the root hashes of the stores by their names
+func storesAppHash(totalTx int64, commits []storeCommitID) []byte {
+	totalTxBytes := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(totalTxBytes, totalTx)
+
+	hashMap := map[string][]byte{TotalTxKey: totalTxBytes[:n]}
+	for _, cmmID := range commits {
+		hashMap[cmmID.Name] = cmmID.CID.Hash
+	}
+
+	return merkle.SimpleHashFromMap(hashMap)
+}
+
+func (ms *IavlStoreMulti) snapshotInfo(version int64) *ankrcmm.SnapshotInfo {
+	for _, format := range []uint32{SnapshotFormatNodes, SnapshotFormat} {
+		if infoBytes := ms.db.Get(snapshotKey(version, format)); infoBytes != nil {
+			var info ankrcmm.SnapshotInfo
+			ms.cdc.MustUnmarshalBinaryLengthPrefixed(infoBytes, &info)
+			return &info
+		}
+	}
+
+	return nil
+}
+
+// snapshotWriter writes the chunks of the snapshot and keeps their hashes
+type snapshotWriter struct {
+	ms          *IavlStoreMulti
+	version     int64
+	format      uint32
+	chunkHashes [][]byte
+}
+
+func (sw *snapshotWriter) writeChunk(chunk interface{}) {
+	chunkBytes := sw.ms.cdc.MustMarshalBinaryLengthPrefixed(chunk)
+	chunkHash  := sha256.Sum256(chunkBytes)
+
+	sw.ms.db.Set(snapshotChunkKey(sw.version, sw.format, uint32(len(sw.chunkHashes))), chunkBytes)
+
+	sw.chunkHashes = append(sw.chunkHashes, chunkHash[:])
+}
+
+func (sw *snapshotWriter) abort() {
+	sw.ms.deleteSnapshotChunks(sw.version, sw.format, uint32(len(sw.chunkHashes)))
+}
+
+// finish writes the info after all the chunks, so a snapshot interrupted is never listed
+func (sw *snapshotWriter) finish(appHash []byte, metadata interface{}) *ankrcmm.SnapshotInfo {
+	metadataBytes := sw.ms.cdc.MustMarshalBinaryLengthPrefixed(metadata)
+	metadataHash  := sha256.Sum256(metadataBytes)
+
+	info := &ankrcmm.SnapshotInfo{sw.version, sw.format, uint32(len(sw.chunkHashes)), metadataHash[:], appHash, metadataBytes}
+
+	sw.ms.db.SetSync(snapshotKey(sw.version, sw.format), sw.ms.cdc.MustMarshalBinaryLengthPrefixed(info))
+
+	return info
+}
+
+// CreateSnapshot splits the stores at the version into chunks of about chunkSize bytes, in SnapshotFormatNodes if the
+// app hash at the version commits to the root hashes of the stores, or else in SnapshotFormat. It reads the stores
+// loaded independently from the db, so it can run while the working stores keep committing, but it must finish before
+// the version is pruned.
+func (ms *IavlStoreMulti) CreateSnapshot(version int64, chunkSize int) (*ankrcmm.SnapshotInfo, error) {
+	if info := ms.snapshotInfo(version); info != nil {
+		return info, nil
+	}
+
+	if version <= 0 || ms.db.Get([]byte(fmt.Sprintf(CommitInfoKey, version))) == nil {
+		return nil, fmt.Errorf("can't create snapshot, no commit info of version %d", version)
+	}
+
+	// the versions from the snapshot one aren't pruned while the snapshot is being created
+	atomic.StoreInt64(&ms.snapshotVersion, version)
+	defer atomic.StoreInt64(&ms.snapshotVersion, 0)
+
+	snapshotSM := NewIavlStoreMulti(ms.db, ms.log)
+	snapshotSM.Load()
+
+	for _, storeName := range snapshotSM.sortedStoreNames() {
+		if !snapshotSM.storeMap[storeName].VersionExists(version) {
+			return nil, fmt.Errorf("can't create snapshot, the version %d of store %s doesn't exist", version, storeName)
+		}
+	}
+
+	cmmInfo := snapshotSM.commitInfo(version)
+
+	var totalTx int64
+	mainTree, err := snapshotSM.storeMap[IAvlStoreMainKey].GetImmutable(version)
+	if err != nil {
+		return nil, err
+	}
+	if _, totalTxBytes := mainTree.Get([]byte(TotalTxKey)); len(totalTxBytes) > 0 {
+		totalTx, _ = binary.Varint(totalTxBytes)
+	}
+
+	if bytes.Equal(storesAppHash(totalTx, cmmInfo.Commits), cmmInfo.AppHash) {
+		return snapshotSM.createNodesSnapshot(ms, version, chunkSize, cmmInfo, totalTx)
+	}
+
+	return snapshotSM.createKVsSnapshot(ms, version, chunkSize, cmmInfo)
+}
+
+func (ms *IavlStoreMulti) createKVsSnapshot(dst *IavlStoreMulti, version int64, chunkSize int, cmmInfo *commitInfo) (*ankrcmm.SnapshotInfo, error) {
+	sw       := &snapshotWriter{ms: dst, version: version, format: SnapshotFormat}
+	metadata := &snapshotMetadata{Commit: *cmmInfo}
+
+	for _, storeName := range ms.sortedStoreNames() {
+		tree, err := ms.storeMap[storeName].GetImmutable(version)
+		if err != nil {
+			sw.abort()
+			return nil, err
+		}
+
+		sStore    := snapshotStore{Name: storeName}
+		hasher    := sha256.New()
+		chunk     := &snapshotChunk{Store: storeName}
+		chunkSizeCur := 0
+		tree.Iterate(func(key []byte, value []byte) bool {
+			writeSnapshotKV(hasher, key, value)
+			sStore.KVCount++
+
+			chunk.KVs = append(chunk.KVs, snapshotKV{key, value})
+			chunkSizeCur += len(key) + len(value)
+			if chunkSizeCur >= chunkSize {
+				sw.writeChunk(chunk)
+				chunk        = &snapshotChunk{Store: storeName}
+				chunkSizeCur = 0
+			}
+
+			return false
+		})
+		if len(chunk.KVs) > 0 {
+			sw.writeChunk(chunk)
+		}
+
+		sStore.KVHash = hasher.Sum(nil)
+		metadata.Stores = append(metadata.Stores, sStore)
+	}
+
+	metadata.ChunkHashes = sw.chunkHashes
+
+	return sw.finish(cmmInfo.AppHash, metadata), nil
+}
+
+func (ms *IavlStoreMulti) createNodesSnapshot(dst *IavlStoreMulti, version int64, chunkSize int, cmmInfo *commitInfo, totalTx int64) (*ankrcmm.SnapshotInfo, error) {
+	sw       := &snapshotWriter{ms: dst, version: version, format: SnapshotFormatNodes}
+	metadata := &snapshotNodesMetadata{Commit: *cmmInfo, TotalTx: totalTx}
+
+	for _, storeName := range ms.sortedStoreNames() {
+		chunk        := &snapshotNodesChunk{Store: storeName}
+		chunkSizeCur := 0
+		err := ms.storeMap[storeName].exportNodes(version, func(nodeBytes []byte) {
+			chunk.Nodes = append(chunk.Nodes, nodeBytes)
+			chunkSizeCur += len(nodeBytes)
+			if chunkSizeCur >= chunkSize {
+				sw.writeChunk(chunk)
+				chunk        = &snapshotNodesChunk{Store: storeName}
+				chunkSizeCur = 0
+			}
+		})
+		if err != nil {
+			sw.abort()
+			return nil, fmt.Errorf("can't export store %s: %v", storeName, err)
+		}
+		if len(chunk.Nodes) > 0 {
+			sw.writeChunk(chunk)
+		}
+	}
+
+	metadata.ChunkHashes = sw.chunkHashes
+
+	return sw.finish(cmmInfo.AppHash, metadata), nil
+}
+
+func (ms *IavlStoreMulti) deleteSnapshotChunks(height int64, format uint32, chunks uint32) {
+	for i := uint32(0); i < chunks; i++ {
+		ms.db.Delete(snapshotChunkKey(height, format, i))
+	}
+}
+
+// Snapshots returns the snapshots in height order
+func (ms *IavlStoreMulti) Snapshots() []*ankrcmm.SnapshotInfo {
+	var snapshots []*ankrcmm.SnapshotInfo
+
+	it := dbm.IteratePrefix(ms.db, []byte(SnapshotKeyPrefix))
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		var info ankrcmm.SnapshotInfo
+		if err := ms.cdc.UnmarshalBinaryLengthPrefixed(it.Value(), &info); err != nil {
+			ms.log.Error("can't decode snapshot info", "key", string(it.Key()), "err", err)
+			continue
+		}
+
+		snapshots = append(snapshots, &info)
+	}
+
+	return snapshots
+}
+
+func (ms *IavlStoreMulti) SnapshotChunk(height int64, format uint32, chunk uint32) []byte {
+	return ms.db.Get(snapshotChunkKey(height, format, chunk))
+}
+
+// PruneSnapshots deletes the old snapshots, only the latest keepRecent ones are kept
+func (ms *IavlStoreMulti) PruneSnapshots(keepRecent int) {
+	snapshots := ms.Snapshots()
+	if keepRecent < 0 || len(snapshots) <= keepRecent {
+		return
+	}
+
+	for _, info := range snapshots[:len(snapshots)-keepRecent] {
+		ms.db.DeleteSync(snapshotKey(info.Height, info.Format))
+		ms.deleteSnapshotChunks(info.Height, info.Format, info.Chunks)
+	}
+}
+
+// abortRestore discards the key values set by the chunks of SnapshotFormat applied. The nodes saved by the chunks of
+// SnapshotFormatNodes are left in the db without a root, so the trees never see them.
+func (ms *IavlStoreMulti) abortRestore() {
+	if ms.restorer != nil && ms.restorer.info.Format == SnapshotFormat && ms.restorer.nextChunk > 0 {
+		for _, iavlS := range ms.storeMap {
+			iavlS.Rollback()
+		}
+	}
+
+	ms.restorer = nil
+}
+
+// OfferSnapshot starts restoring the snapshot, which can be only restored into the stores never committed
+func (ms *IavlStoreMulti) OfferSnapshot(info *ankrcmm.SnapshotInfo) error {
+	if info.Format != SnapshotFormat && info.Format != SnapshotFormatNodes {
+		return ErrSnapshotFormatUnknown
+	}
+
+	if ms.latestVersion() != 0 {
+		return errors.New("can't restore snapshot into the committed stores")
+	}
+
+	metadataHash := sha256.Sum256(info.Metadata)
+	if !bytes.Equal(metadataHash[:], info.Hash) {
+		return fmt.Errorf("snapshot hash mismatch: expected %X, got %X", info.Hash, metadataHash[:])
+	}
+
+	restorer := &snapshotRestorer{info: info}
+	if info.Format == SnapshotFormat {
+		var metadata snapshotMetadata
+		if err := ms.cdc.UnmarshalBinaryLengthPrefixed(info.Metadata, &metadata); err != nil {
+			return fmt.Errorf("can't decode snapshot metadata: %v", err)
+		}
+
+		for _, sStore := range metadata.Stores {
+			if _, ok := ms.storeMap[sStore.Name]; !ok {
+				return fmt.Errorf("unknown snapshot store %s", sStore.Name)
+			}
+		}
+
+		restorer.commit      = metadata.Commit
+		restorer.chunkHashes = metadata.ChunkHashes
+		restorer.stores      = metadata.Stores
+		restorer.kvCounts    = make(map[string]int64)
+		restorer.kvHashers   = make(map[string]hash.Hash)
+	} else {
+		var metadata snapshotNodesMetadata
+		if err := ms.cdc.UnmarshalBinaryLengthPrefixed(info.Metadata, &metadata); err != nil {
+			return fmt.Errorf("can't decode snapshot metadata: %v", err)
+		}
+
+		if len(metadata.Commit.Commits) != len(ms.storeMap) {
+			return fmt.Errorf("snapshot stores mismatch: expected %d, got %d", len(ms.storeMap), len(metadata.Commit.Commits))
+		}
+
+		restorer.importers = make(map[string]*snapshotNodesImporter)
+		for _, cmmID := range metadata.Commit.Commits {
+			if _, ok := ms.storeMap[cmmID.Name]; !ok {
+				return fmt.Errorf("unknown snapshot store %s", cmmID.Name)
+			}
+
+			restorer.importers[cmmID.Name] = newSnapshotNodesImporter(cmmID.CID.Hash)
+		}
+
+		// the root hashes the nodes are checked against are committed to by the app hash
+		if appHash := storesAppHash(metadata.TotalTx, metadata.Commit.Commits); !bytes.Equal(appHash, info.AppHash) {
+			return fmt.Errorf("snapshot store root hashes mismatch the app hash %X, got %X", info.AppHash, appHash)
+		}
+
+		restorer.commit      = metadata.Commit
+		restorer.chunkHashes = metadata.ChunkHashes
+		restorer.totalTx     = metadata.TotalTx
+	}
+
+	if restorer.commit.Version != info.Height || !bytes.Equal(restorer.commit.AppHash, info.AppHash) {
+		return fmt.Errorf("snapshot commit info mismatch: height=%d, appHash=%X", restorer.commit.Version, restorer.commit.AppHash)
+	}
+
+	if uint32(len(restorer.chunkHashes)) != info.Chunks {
+		return fmt.Errorf("snapshot chunks mismatch: expected %d, got %d", info.Chunks, len(restorer.chunkHashes))
+	}
+
+	ms.abortRestore()
+
+	ms.restorer = restorer
+
+	return nil
+}
+
+// ApplySnapshotChunk writes the chunk into the stores, the chunks must be applied in order. ErrSnapshotChunkInvalid
+// is returned if the chunk doesn't match its hash, which should be fetched again. After the last chunk is applied,
+// the stores are checked and committed at the snapshot height, and true is returned.
+func (ms *IavlStoreMulti) ApplySnapshotChunk(index uint32, chunkBytes []byte) (bool, error) {
+	restorer := ms.restorer
+	if restorer == nil {
+		return false, errors.New("no snapshot being restored")
+	}
+
+	if index != restorer.nextChunk {
+		return false, fmt.Errorf("unexpected snapshot chunk %d, expected %d", index, restorer.nextChunk)
+	}
+
+	chunkHash := sha256.Sum256(chunkBytes)
+	if !bytes.Equal(chunkHash[:], restorer.chunkHashes[index]) {
+		return false, ErrSnapshotChunkInvalid
+	}
+
+	var err error
+	if restorer.info.Format == SnapshotFormat {
+		err = ms.applyKVsChunk(chunkBytes)
+	} else {
+		err = ms.applyNodesChunk(chunkBytes)
+	}
+	if err == ErrSnapshotChunkInvalid {
+		return false, err
+	}
+	if err != nil {
+		ms.abortRestore()
+		return false, err
+	}
+
+	restorer.nextChunk++
+	if restorer.nextChunk < restorer.info.Chunks {
+		return false, nil
+	}
+
+	if restorer.info.Format == SnapshotFormat {
+		err = ms.finishKVsRestore()
+	} else {
+		err = ms.finishNodesRestore()
+	}
+	if err != nil {
+		ms.abortRestore()
+		return false, err
+	}
+
+	ms.restorer = nil
+
+	return true, nil
+}
+
+func (ms *IavlStoreMulti) applyKVsChunk(chunkBytes []byte) error {
+	restorer := ms.restorer
+
+	var chunk snapshotChunk
+	if err := ms.cdc.UnmarshalBinaryLengthPrefixed(chunkBytes, &chunk); err != nil {
+		return ErrSnapshotChunkInvalid
+	}
+
+	iavlS, ok := ms.storeMap[chunk.Store]
+	if !ok {
+		return fmt.Errorf("unknown snapshot store %s", chunk.Store)
+	}
+
+	hasher, ok := restorer.kvHashers[chunk.Store]
+	if !ok {
+		hasher = sha256.New()
+		restorer.kvHashers[chunk.Store] = hasher
+	}
+
+	for _, kv := range chunk.KVs {
+		iavlS.Set(kv.Key, kv.Value)
+		writeSnapshotKV(hasher, kv.Key, kv.Value)
+	}
+	restorer.kvCounts[chunk.Store] += int64(len(chunk.KVs))
+
+	return nil
+}
+
+// applyNodesChunk saves the nodes only after all of them are checked, so a forged chunk leaves nothing in the db
+func (ms *IavlStoreMulti) applyNodesChunk(chunkBytes []byte) error {
+	var chunk snapshotNodesChunk
+	if err := ms.cdc.UnmarshalBinaryLengthPrefixed(chunkBytes, &chunk); err != nil {
+		return ErrSnapshotChunkInvalid
+	}
+
+	importer, ok := ms.restorer.importers[chunk.Store]
+	if !ok {
+		return fmt.Errorf("unknown snapshot store %s", chunk.Store)
+	}
+
+	hashes := make([][]byte, len(chunk.Nodes))
+	for i, nodeBytes := range chunk.Nodes {
+		nodeHash, err := importer.check(nodeBytes)
+		if err != nil {
+			return fmt.Errorf("invalid node of snapshot store %s: %v", chunk.Store, err)
+		}
+
+		hashes[i] = nodeHash
+	}
+
+	iavlS := ms.storeMap[chunk.Store]
+
+	batch := iavlS.db.NewBatch()
+	defer batch.Close()
+
+	for i, nodeBytes := range chunk.Nodes {
+		iavlS.importNode(batch, hashes[i], nodeBytes)
+	}
+	batch.Write()
+
+	return nil
+}
+
+// finishKVsRestore checks the key values against the metadata and the total tx count against the app hash, nothing
+// else of the state is committed to by the app hash of SnapshotFormat
+func (ms *IavlStoreMulti) finishKVsRestore() error {
+	restorer := ms.restorer
+
+	for _, sStore := range restorer.stores {
+		kvHash := sha256.New().Sum(nil)
+		if hasher, ok := restorer.kvHashers[sStore.Name]; ok {
+			kvHash = hasher.Sum(nil)
+		}
+
+		if restorer.kvCounts[sStore.Name] != sStore.KVCount || !bytes.Equal(kvHash, sStore.KVHash) {
+			return fmt.Errorf("restored store %s mismatch: kvCount=%d, expected %d", sStore.Name, restorer.kvCounts[sStore.Name], sStore.KVCount)
+		}
+	}
+
+	var totalTx int64
+	totalTxBytes, err := ms.storeMap[IAvlStoreMainKey].Get([]byte(TotalTxKey))
+	if err == nil && len(totalTxBytes) > 0 {
+		totalTx, _ = binary.Varint(totalTxBytes)
+	}
+
+	appHash := make([]byte, 8)
+	binary.PutVarint(appHash, totalTx)
+	if !bytes.Equal(appHash, restorer.info.AppHash) {
+		return fmt.Errorf("restored app hash mismatch: expected %X, got %X", restorer.info.AppHash, appHash)
+	}
+
+	ms.setVersionOffset(restorer.info.Height - 1)
+	ms.Commit(restorer.info.Height-1, totalTx, false)
+
+	return nil
+}
+
+// finishNodesRestore saves the roots of the trees at the snapshot height, the trees are loaded at it and their root
+// hashes checked against the ones the app hash commits to. The commit info of the snapshot is saved as the one of the
+// height, no version is committed by the stores.
+func (ms *IavlStoreMulti) finishNodesRestore() error {
+	restorer := ms.restorer
+	version  := restorer.info.Height
+
+	for name, importer := range restorer.importers {
+		if !importer.done() {
+			return fmt.Errorf("restored store %s is missing nodes", name)
+		}
+	}
+
+	for _, cmmID := range restorer.commit.Commits {
+		iavlS := ms.storeMap[cmmID.Name]
+		if err := iavlS.importRoot(version, cmmID.CID.Hash); err != nil {
+			return fmt.Errorf("can't load restored store %s: %v", cmmID.Name, err)
+		}
+
+		if rootHash := iavlS.LatestVersion().Hash; !bytes.Equal(rootHash, cmmID.CID.Hash) {
+			return fmt.Errorf("restored store %s root hash mismatch: expected %X, got %X", cmmID.Name, cmmID.CID.Hash, rootHash)
+		}
+	}
+
+	var totalTx int64
+	totalTxBytes, err := ms.storeMap[IAvlStoreMainKey].Get([]byte(TotalTxKey))
+	if err == nil && len(totalTxBytes) > 0 {
+		totalTx, _ = binary.Varint(totalTxBytes)
+	}
+	if totalTx != restorer.totalTx {
+		return fmt.Errorf("restored total tx mismatch: expected %d, got %d", restorer.totalTx, totalTx)
+	}
+
+	chainID, _ := ms.storeMap[IAvlStoreMainKey].Get([]byte(ChainIDKey))
+
+	batch := ms.db.NewBatch()
+	defer batch.Close()
+
+	ms.setCommitInfo(batch, version, restorer.commit)
+	ms.setLatestVersion(batch, version)
+	ms.setLastCommitState(batch, ankrcmm.CommitState{version, restorer.commit.AppHash, string(chainID)})
+
+	batch.WriteSync()
+
+	return nil
+}
+
+func (sp *IavlStoreApp) CreateSnapshot(height int64) (*ankrcmm.SnapshotInfo, error) {
+	return sp.iavlSM.CreateSnapshot(height, DefaultSnapshotChunkSize)
+}
+
+func (sp *IavlStoreApp) Snapshots() []*ankrcmm.SnapshotInfo {
+	return sp.iavlSM.Snapshots()
+}
+
+func (sp *IavlStoreApp) SnapshotChunk(height int64, format uint32, chunk uint32) []byte {
+	return sp.iavlSM.SnapshotChunk(height, format, chunk)
+}
+
+func (sp *IavlStoreApp) PruneSnapshots(keepRecent int) {
+	sp.iavlSM.PruneSnapshots(keepRecent)
+}
+
+func (sp *IavlStoreApp) OfferSnapshot(info *ankrcmm.SnapshotInfo) error {
+	return sp.iavlSM.OfferSnapshot(info)
+}
+
+func (sp *IavlStoreApp) ApplySnapshotChunk(index uint32, chunk []byte) (bool, error) {
+	done, err := sp.iavlSM.ApplySnapshotChunk(index, chunk)
+	if !done {
+		return done, err
+	}
+
+	sp.lastCommitID = sp.iavlSM.lastCommit()
+
+	totalTxBytes, err := sp.iavlSM.IavlStore(IAvlStoreMainKey).Get([]byte(TotalTxKey))
+	if err == nil && len(totalTxBytes) > 0 {
+		sp.totalTx, _ = binary.Varint(totalTxBytes)
+	}
+
+	sp.storeLog.Info("IavlStoreApp snapshot restored", "height", sp.lastCommitID.Version, "totalTx", sp.totalTx)
+
+	return true, nil
+}
@@ -0,0 +1,213 @@
+package iavl
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/tendermint/go-amino"
+	"github.com/tendermint/iavl"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	dbm "github.com/tendermint/tendermint/libs/db"
+)
+
+// the keys the iavl tree saves its nodes and roots at
+var (
+	iavlNodeKeyFormat = iavl.NewKeyFormat('n', tmhash.Size)
+	iavlRootKeyFormat = iavl.NewKeyFormat('r', 8)
+)
+
+// snapshotNode is the iavl node decoded from the bytes the tree saves it as, the encoding and the hash follow the ones
+// of the iavl node, so a node got from the snapshot is checked by its hash without the tree
+type snapshotNode struct {
+	height    int8
+	size      int64
+	version   int64
+	key       []byte
+	value     []byte
+	leftHash  []byte
+	rightHash []byte
+}
+
+func decodeSnapshotNode(buf []byte) (*snapshotNode, error) {
+	height, n, err := amino.DecodeInt8(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding node height: %v", err)
+	}
+	buf = buf[n:]
+
+	size, n, err := amino.DecodeVarint(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding node size: %v", err)
+	}
+	buf = buf[n:]
+
+	version, n, err := amino.DecodeVarint(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding node version: %v", err)
+	}
+	buf = buf[n:]
+
+	key, n, err := amino.DecodeByteSlice(buf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding node key: %v", err)
+	}
+	buf = buf[n:]
+
+	node := &snapshotNode{height: height, size: size, version: version, key: key}
+	if height == 0 {
+		if node.value, n, err = amino.DecodeByteSlice(buf); err != nil {
+			return nil, fmt.Errorf("decoding node value: %v", err)
+		}
+		buf = buf[n:]
+	} else {
+		if node.leftHash, n, err = amino.DecodeByteSlice(buf); err != nil {
+			return nil, fmt.Errorf("decoding node left hash: %v", err)
+		}
+		buf = buf[n:]
+
+		if node.rightHash, n, err = amino.DecodeByteSlice(buf); err != nil {
+			return nil, fmt.Errorf("decoding node right hash: %v", err)
+		}
+		buf = buf[n:]
+
+		if len(node.leftHash) != tmhash.Size || len(node.rightHash) != tmhash.Size {
+			return nil, fmt.Errorf("invalid node child hash")
+		}
+	}
+
+	if len(buf) != 0 {
+		return nil, fmt.Errorf("%d bytes left after the node", len(buf))
+	}
+
+	return node, nil
+}
+
+// hash is the one the parent node and the root key refer to the node by
+func (sn *snapshotNode) hash() []byte {
+	buf := new(bytes.Buffer)
+	amino.EncodeInt8(buf, sn.height)
+	amino.EncodeVarint(buf, sn.size)
+	amino.EncodeVarint(buf, sn.version)
+
+	if sn.height == 0 {
+		amino.EncodeByteSlice(buf, sn.key)
+		amino.EncodeByteSlice(buf, tmhash.Sum(sn.value))
+	} else {
+		amino.EncodeByteSlice(buf, sn.leftHash)
+		amino.EncodeByteSlice(buf, sn.rightHash)
+	}
+
+	return tmhash.Sum(buf.Bytes())
+}
+
+// exportNodes walks the nodes of the tree saved at the version in pre-order, so a node is always given before its
+// children. The empty tree has no nodes.
+func (s *IavlStore) exportNodes(ver int64, fn func(nodeBytes []byte)) error {
+	rootHash := s.db.Get(iavlRootKeyFormat.Key(ver - s.versionOffset))
+	if rootHash == nil {
+		return fmt.Errorf("no root of version %d", ver)
+	}
+
+	if len(rootHash) == 0 {
+		return nil
+	}
+
+	return s.exportNode(rootHash, fn)
+}
+
+func (s *IavlStore) exportNode(hash []byte, fn func(nodeBytes []byte)) error {
+	nodeBytes := s.db.Get(iavlNodeKeyFormat.KeyBytes(hash))
+	if nodeBytes == nil {
+		return fmt.Errorf("node %X not found", hash)
+	}
+
+	node, err := decodeSnapshotNode(nodeBytes)
+	if err != nil {
+		return err
+	}
+
+	fn(nodeBytes)
+
+	if node.height == 0 {
+		return nil
+	}
+
+	if err := s.exportNode(node.leftHash, fn); err != nil {
+		return err
+	}
+
+	return s.exportNode(node.rightHash, fn)
+}
+
+// importNode saves the node checked by its hash, the tree doesn't see it until importRoot
+func (s *IavlStore) importNode(batch dbm.Batch, hash []byte, nodeBytes []byte) {
+	batch.Set(iavlNodeKeyFormat.KeyBytes(hash), nodeBytes)
+}
+
+// importRoot saves the root of the nodes imported as the version and loads the tree at it, the root hash of the
+// empty tree is blank
+func (s *IavlStore) importRoot(ver int64, rootHash []byte) error {
+	if rootHash == nil {
+		rootHash = []byte{}
+	}
+
+	s.db.SetSync(iavlRootKeyFormat.Key(ver), rootHash)
+
+	_, err := s.LoadVersion(ver)
+
+	return err
+}
+
+type pendingSnapshotNode struct {
+	leftmostKey []byte
+	checkKey    bool
+}
+
+// snapshotNodesImporter checks the nodes of one store given in pre-order against its root hash. Every node must be
+// referred to by the root or a node checked before it. The key of an inner node isn't covered by its hash, so it must
+// be the leftmost key of its right subtree, which the tree searches by.
+type snapshotNodesImporter struct {
+	pending map[string]pendingSnapshotNode
+}
+
+func newSnapshotNodesImporter(rootHash []byte) *snapshotNodesImporter {
+	si := &snapshotNodesImporter{pending: make(map[string]pendingSnapshotNode)}
+	if len(rootHash) > 0 {
+		si.pending[string(rootHash)] = pendingSnapshotNode{}
+	}
+
+	return si
+}
+
+// check returns the hash of the node if it belongs to the tree
+func (si *snapshotNodesImporter) check(nodeBytes []byte) ([]byte, error) {
+	node, err := decodeSnapshotNode(nodeBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := node.hash()
+	pending, ok := si.pending[string(hash)]
+	if !ok {
+		return nil, fmt.Errorf("node %X isn't referred to", hash)
+	}
+	delete(si.pending, string(hash))
+
+	if node.height == 0 {
+		if pending.checkKey && !bytes.Equal(node.key, pending.leftmostKey) {
+			return nil, fmt.Errorf("node %X key mismatches the one of its ancestor", hash)
+		}
+
+		return hash, nil
+	}
+
+	si.pending[string(node.leftHash)]  = pending
+	si.pending[string(node.rightHash)] = pendingSnapshotNode{node.key, true}
+
+	return hash, nil
+}
+
+// done reports whether all the nodes of the tree are checked
+func (si *snapshotNodesImporter) done() bool {
+	return len(si.pending) == 0
+}
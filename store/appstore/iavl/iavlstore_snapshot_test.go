@@ -0,0 +1,206 @@
+package iavl
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/upgrade"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/go-amino"
+)
+
+func TestIavlStoreSnapshotRestore(t *testing.T) {
+	srcStore := NewMockIavlStoreApp()
+	for i := 0; i < 3; i++ {
+		srcStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(int64(1000 + i)).Bytes()})
+		srcStore.SetChainID("test-chain")
+		srcStore.IncTotalTx()
+		srcStore.Commit()
+	}
+
+	info, err := srcStore.iavlSM.CreateSnapshot(3, 64)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, int64(3), info.Height)
+	assert.True(t, info.Chunks > 1)
+	assert.Equal(t, 1, len(srcStore.Snapshots()))
+
+	dstStore := NewMockIavlStoreApp()
+	assert.Equal(t, nil, dstStore.OfferSnapshot(info))
+
+	tamperedChunk := append([]byte(nil), srcStore.SnapshotChunk(3, SnapshotFormat, 0)...)
+	tamperedChunk[len(tamperedChunk)-1]++
+	_, err = dstStore.ApplySnapshotChunk(0, tamperedChunk)
+	assert.Equal(t, ErrSnapshotChunkInvalid, err)
+
+	for i := uint32(0); i < info.Chunks; i++ {
+		done, err := dstStore.ApplySnapshotChunk(i, srcStore.SnapshotChunk(3, SnapshotFormat, i))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, i == info.Chunks-1, done)
+	}
+
+	assert.Equal(t, int64(3), dstStore.Height())
+	assert.Equal(t, info.AppHash, dstStore.APPHash())
+	assert.Equal(t, "test-chain", dstStore.ChainID())
+
+	bal, _, _, _, err := dstStore.Balance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", "ANKR", 3, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1002", bal.String())
+
+	dstStore.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(2000).Bytes()})
+	dstStore.Commit()
+	assert.Equal(t, int64(4), dstStore.Height())
+
+	bal, _, _, _, err = dstStore.Balance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67", "ANKR", 4, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "2000", bal.String())
+
+	assert.NotEqual(t, nil, dstStore.OfferSnapshot(info))
+
+	srcStore.PruneSnapshots(0)
+	assert.Equal(t, 0, len(srcStore.Snapshots()))
+	assert.Equal(t, 0, len(srcStore.SnapshotChunk(3, SnapshotFormat, 0)))
+}
+
+func TestIavlStoreSnapshotRestoreNodes(t *testing.T) {
+	srcStore := NewMockIavlStoreApp()
+	srcStore.SetChainID("test-chain")
+	srcStore.IncTotalTx()
+	srcStore.Commit()
+	assert.Equal(t, 8, len(srcStore.APPHash()))
+
+	// the app hash commits to the root hashes of the stores from the upgrade
+	srcStore.SetUpgradeDone(upgrade.UpgradeStoreAppHash, 2)
+	for i := 0; i < 20; i++ {
+		srcStore.SetBalance(fmt.Sprintf("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD%02d", i), ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(int64(1000 + i)).Bytes()})
+		if i%10 == 9 {
+			srcStore.IncTotalTx()
+			srcStore.Commit()
+		}
+	}
+	assert.Equal(t, 32, len(srcStore.APPHash()))
+
+	info, err := srcStore.iavlSM.CreateSnapshot(3, 256)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, SnapshotFormatNodes, info.Format)
+	assert.Equal(t, srcStore.APPHashByHeight(3), info.AppHash)
+	assert.True(t, info.Chunks > 1)
+
+	// the snapshot of a height before the upgrade keeps the key values
+	infoKVs, err := srcStore.iavlSM.CreateSnapshot(1, 256)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, SnapshotFormat, infoKVs.Format)
+
+	// the metadata not committed to by the app hash is refused
+	var metadata snapshotNodesMetadata
+	srcStore.cdc.MustUnmarshalBinaryLengthPrefixed(info.Metadata, &metadata)
+	metadata.TotalTx++
+	forgedInfo := *info
+	forgedInfo.Metadata = srcStore.cdc.MustMarshalBinaryLengthPrefixed(metadata)
+	forgedHash := sha256.Sum256(forgedInfo.Metadata)
+	forgedInfo.Hash = forgedHash[:]
+	dstStore := NewMockIavlStoreApp()
+	assert.NotEqual(t, nil, dstStore.OfferSnapshot(&forgedInfo))
+
+	assert.Equal(t, nil, dstStore.OfferSnapshot(info))
+
+	tamperedChunk := append([]byte(nil), srcStore.SnapshotChunk(3, SnapshotFormatNodes, 0)...)
+	tamperedChunk[len(tamperedChunk)-1]++
+	_, err = dstStore.ApplySnapshotChunk(0, tamperedChunk)
+	assert.Equal(t, ErrSnapshotChunkInvalid, err)
+
+	for i := uint32(0); i < info.Chunks; i++ {
+		done, err := dstStore.ApplySnapshotChunk(i, srcStore.SnapshotChunk(3, SnapshotFormatNodes, i))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, i == info.Chunks-1, done)
+	}
+
+	assert.Equal(t, int64(3), dstStore.Height())
+	assert.Equal(t, info.AppHash, dstStore.APPHash())
+	assert.Equal(t, "test-chain", dstStore.ChainID())
+
+	_, srcStoreHashes, err := srcStore.StoreHashes(3)
+	assert.Equal(t, nil, err)
+	_, dstStoreHashes, err := dstStore.StoreHashes(3)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, srcStoreHashes, dstStoreHashes)
+
+	bal, _, _, _, err := dstStore.Balance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD07", "ANKR", 3, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1007", bal.String())
+
+	// the restored trees are the ones of the chain, so the next commits give the same app hash
+	for _, store := range []*IavlStoreApp{srcStore, dstStore} {
+		store.SetBalance("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD07", ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(2000).Bytes()})
+		store.IncTotalTx()
+		store.Commit()
+	}
+	assert.Equal(t, int64(4), dstStore.Height())
+	assert.Equal(t, srcStore.APPHash(), dstStore.APPHash())
+
+	assert.NotEqual(t, nil, dstStore.OfferSnapshot(info))
+}
+
+func TestSnapshotNodesImporter(t *testing.T) {
+	store := NewMockIavlStoreApp()
+	for i := 0; i < 10; i++ {
+		store.SetBalance(fmt.Sprintf("B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD%02d", i), ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, big.NewInt(int64(1000 + i)).Bytes()})
+	}
+	store.Commit()
+
+	accStore := store.iavlSM.storeMap[IavlStoreAccountKey]
+	rootHash := accStore.LatestVersion().Hash
+
+	var nodes [][]byte
+	assert.Equal(t, nil, accStore.exportNodes(1, func(nodeBytes []byte) {
+		nodes = append(nodes, nodeBytes)
+	}))
+
+	importer := newSnapshotNodesImporter(rootHash)
+	for _, nodeBytes := range nodes {
+		_, err := importer.check(nodeBytes)
+		assert.Equal(t, nil, err)
+	}
+	assert.Equal(t, true, importer.done())
+
+	// a node missing leaves the import undone
+	importer = newSnapshotNodesImporter(rootHash)
+	for _, nodeBytes := range nodes[:len(nodes)-1] {
+		importer.check(nodeBytes)
+	}
+	assert.Equal(t, false, importer.done())
+
+	// the key of an inner node isn't covered by its hash, it's checked against the leftmost key of its right subtree
+	node, err := decodeSnapshotNode(nodes[0])
+	assert.Equal(t, nil, err)
+	assert.True(t, node.height > 0)
+	node.key = append([]byte(nil), node.key...)
+	node.key[len(node.key)-1]++
+
+	buf := new(bytes.Buffer)
+	amino.EncodeInt8(buf, node.height)
+	amino.EncodeVarint(buf, node.size)
+	amino.EncodeVarint(buf, node.version)
+	amino.EncodeByteSlice(buf, node.key)
+	amino.EncodeByteSlice(buf, node.leftHash)
+	amino.EncodeByteSlice(buf, node.rightHash)
+
+	importer = newSnapshotNodesImporter(rootHash)
+	_, err = importer.check(buf.Bytes())
+	assert.Equal(t, nil, err)
+	var checkErr error
+	for _, nodeBytes := range nodes[1:] {
+		if _, err := importer.check(nodeBytes); err != nil {
+			checkErr = err
+		}
+	}
+	assert.NotEqual(t, nil, checkErr)
+
+	// a node not referred to is refused
+	importer = newSnapshotNodesImporter(rootHash)
+	_, err = importer.check(nodes[1])
+	assert.NotEqual(t, nil, err)
+}
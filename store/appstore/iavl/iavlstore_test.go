@@ -2,6 +2,8 @@ package iavl
 
 import (
 	"fmt"
+	"math/big"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/go-amino"
 	"github.com/tendermint/iavl"
@@ -18,7 +20,7 @@ func TestIavlStoreVersion(t *testing.T) {
 	db := dbm.NewMemDB()
 	storeLog := log.NewNopLogger()
 
-	iavlStore := NewIavlStore(db, 100, 10, storeLog)
+	iavlStore := NewIavlStore(db, 100, storeLog)
 
 	iavlStore.Set([]byte("testkey1"), []byte("testvalue1"))
 	iavlStore.Commit()
@@ -47,7 +49,7 @@ func TestIavlStoreVersionCommitRollback(t *testing.T) {
 	db := dbm.NewMemDB()
 	storeLog := log.NewNopLogger()
 
-	iavlStore := NewIavlStore(db, 100, 10, storeLog)
+	iavlStore := NewIavlStore(db, 100, storeLog)
 
 	iavlStore.Set([]byte("testkey1"), []byte("testvalue1"))
 	iavlStore.Commit()
@@ -143,3 +145,33 @@ func TestRollBack(t *testing.T)  {
 	fmt.Printf("testkey1's=%s\n", string(storeAPP.Get([]byte("testkey1"))))
 }
 
+
+func TestMinGasPrice(t *testing.T) {
+	storeAPP := NewMockIavlStoreApp()
+
+	minGasPrice, _, _, _, err := storeAPP.MinGasPrice("ANKR", 0, false)
+	assert.Equal(t, nil, err)
+	assert.Nil(t, minGasPrice)
+
+	storeAPP.SetMinGasPrice(&ankrcmm.Amount{ankrcmm.Currency{"ANKR", 18}, new(big.Int).SetUint64(20000000000000).Bytes()})
+
+	minGasPrice, _, _, _, err = storeAPP.MinGasPrice("ANKR", 0, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "20000000000000", new(big.Int).SetBytes(minGasPrice.Value).String())
+}
+
+func TestFeeCurrency(t *testing.T) {
+	storeAPP := NewMockIavlStoreApp()
+
+	storeAPP.SetFeeCurrency(&ankrcmm.FeeCurrencyInfo{"TESTCOIN", "1/2"})
+
+	feeCurInfo, _, _, _, err := storeAPP.FeeCurrency("TESTCOIN", 0, false)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1/2", feeCurInfo.ExchangeRate)
+
+	storeAPP.DeleteFeeCurrency("TESTCOIN")
+
+	feeCurInfo, _, _, _, err = storeAPP.FeeCurrency("TESTCOIN", 0, false)
+	assert.Equal(t, nil, err)
+	assert.Nil(t, feeCurInfo)
+}
@@ -0,0 +1,81 @@
+package iavl
+
+import (
+	"encoding/binary"
+	"errors"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/tendermint/iavl"
+)
+
+const (
+	StoreUpgradePrefix     = "upgrade:"
+	StoreUpgradePlanKey    = StoreUpgradePrefix + "plan"
+	StoreUpgradeDonePrefix = StoreUpgradePrefix + "done:"
+)
+
+func containUpgradeDonePrefix(name string) string {
+	return containPrefix(name, StoreUpgradeDonePrefix)
+}
+
+// SetUpgradePlan replaces the plan scheduled, only one plan can be scheduled at a time
+func (sp *IavlStoreApp) SetUpgradePlan(plan *ankrcmm.UpgradePlan) {
+	planBytes, _ := sp.cdc.MarshalJSON(plan)
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(StoreUpgradePlanKey), planBytes)
+}
+
+// UpgradePlan returns nil plan without error if there is no plan scheduled
+func (sp *IavlStoreApp) UpgradePlan(height int64, prove bool) (*ankrcmm.UpgradePlan, string, *iavl.RangeProof, []byte, error) {
+	planBytes, proof, err := sp.kvStore(IAvlStoreMainKey).GetWithVersionProve([]byte(StoreUpgradePlanKey), height, prove)
+	if err != nil || len(planBytes) == 0 {
+		return nil, StoreUpgradePlanKey, proof, nil, err
+	}
+
+	var plan ankrcmm.UpgradePlan
+	err = sp.cdc.UnmarshalJSON(planBytes, &plan)
+	if err != nil {
+		return nil, StoreUpgradePlanKey, proof, planBytes, err
+	}
+
+	return &plan, StoreUpgradePlanKey, proof, planBytes, nil
+}
+
+func (sp *IavlStoreApp) UpgradePlanQuery(height int64, prove bool) (*ankrcmm.QueryResp, string, *iavl.RangeProof, error) {
+	plan, storeKey, proof, proofVal, err := sp.UpgradePlan(height, prove)
+	if err != nil {
+		return nil, storeKey, proof, err
+	}
+
+	if plan == nil {
+		return nil, storeKey, proof, errors.New("no upgrade plan scheduled")
+	}
+
+	respData, err := sp.cdc.MarshalJSON(&ankrcmm.UpgradePlanQueryResp{plan.Name, plan.Height, plan.Info})
+	if err != nil {
+		return nil, storeKey, proof, err
+	}
+
+	return &ankrcmm.QueryResp{respData, proofVal}, storeKey, proof, nil
+}
+
+func (sp *IavlStoreApp) DeleteUpgradePlan() {
+	sp.kvStore(IAvlStoreMainKey).Remove([]byte(StoreUpgradePlanKey))
+}
+
+func (sp *IavlStoreApp) SetUpgradeDone(name string, height int64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutVarint(buf, height)
+	sp.kvStore(IAvlStoreMainKey).Set([]byte(containUpgradeDonePrefix(name)), buf[:n])
+}
+
+// UpgradeDoneHeight returns the height the upgrade was applied at, 0 if it hasn't been applied
+func (sp *IavlStoreApp) UpgradeDoneHeight(name string) int64 {
+	heightBytes, err := sp.kvStore(IAvlStoreMainKey).Get([]byte(containUpgradeDonePrefix(name)))
+	if err != nil || len(heightBytes) == 0 {
+		return 0
+	}
+
+	height, _ := binary.Varint(heightBytes)
+
+	return height
+}
@@ -7,8 +7,10 @@ import (
 	client2 "github.com/Ankr-network/ankr-chain/client"
 	"github.com/Ankr-network/ankr-chain/common"
 	"github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx/gasprice"
 	"github.com/Ankr-network/ankr-chain/tx/metering"
 	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/Ankr-network/ankr-chain/tx/upgrade"
 	"github.com/Ankr-network/ankr-chain/tx/validator"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -51,6 +53,14 @@ var (
 	removeCertDc        = "removeCertDc"
 	removeCertNs = "removeCertNs"
 	removeCertPub = "removeCertPub"
+	minGasPriceSymbol = "minGasPriceSymbol"
+	minGasPriceValue  = "minGasPriceValue"
+	feeCurSymbol      = "feeCurSymbol"
+	feeCurRate        = "feeCurRate"
+	feeCurRemove      = "feeCurRemove"
+	upgradeName       = "upgradeName"
+	upgradeHeight     = "upgradeHeight"
+	upgradeInfo       = "upgradeInfo"
 )
 
 func init() {
@@ -91,6 +101,9 @@ func init() {
 	appendSubCmd(adminCmd, "setcert", "set metering cert", setCert, addCertFlags)
 	appendSubCmd(adminCmd, "validator", "add a new validator", setValidator, addSetValidatorFlags)
 	appendSubCmd(adminCmd, "removecert", "remove cert from validator", removeCert, addRemoveCertFlags)
+	appendSubCmd(adminCmd, "mingasprice", "set the min gas price of a fee currency", setMinGasPrice, addMinGasPriceFlags)
+	appendSubCmd(adminCmd, "feecurrency", "add, update or remove a fee currency", setFeeCurrency, addFeeCurrencyFlags)
+	appendSubCmd(adminCmd, "upgrade", "schedule or cancel an upgrade at a height", setUpgrade, addUpgradeFlags)
 }
 
 //admin setcert --dcname dataCenterName --certPerm certString --url https://validator-url:port
@@ -301,6 +314,153 @@ func addRemoveCertFlags(cmd *cobra.Command) {
 	}
 }
 
+// mingasprice --symbol --value
+func setMinGasPrice(cmd *cobra.Command, args []string) {
+	client := newAnkrHttpClient(viper.GetString(adminUrl))
+	opPrivateKey := viper.GetString(adminPrivateKey)
+	if len(opPrivateKey) < 1 {
+		fmt.Println("Invalid operator private key!")
+		return
+	}
+	header, err := getAdminMsgHeader()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	value, ok := new(big.Int).SetString(viper.GetString(minGasPriceValue), 10)
+	if !ok || value.Sign() < 0 {
+		fmt.Println("Invalid min gas price value.")
+		return
+	}
+	txMsg := new(gasprice.MinGasPriceMsg)
+	txMsg.MinGasPrice.Cur = common.Currency{Symbol: viper.GetString(minGasPriceSymbol)}
+	txMsg.MinGasPrice.Value = value.Bytes()
+	key := crypto.NewSecretKeyEd25519(opPrivateKey)
+	keyAddr, _ := key.Address()
+	txMsg.FromAddr = fmt.Sprintf("%X", keyAddr)
+	builder := client2.NewTxMsgBuilder(*header, txMsg, serializer.NewTxSerializerCDC(), key)
+	fmt.Println("Start Sending transaction...")
+	txHash, cHeight, _, err := builder.BuildAndCommit(client)
+	if err != nil {
+		fmt.Println("Set min gas price failed.")
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println("Set min gas price success.")
+	fmt.Println("Transaction hash:", txHash)
+	fmt.Println("Block Height:", cHeight)
+}
+
+func addMinGasPriceFlags(cmd *cobra.Command) {
+	err := addStringFlag(cmd, minGasPriceSymbol, symbolParam, "", "ANKR", "symbol of the fee currency", notRequired)
+	if err != nil {
+		panic(err)
+	}
+	err = addStringFlag(cmd, minGasPriceValue, valueParam, "", "", "min gas price value", required)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// feecurrency --symbol --rate [--remove]
+func setFeeCurrency(cmd *cobra.Command, args []string) {
+	client := newAnkrHttpClient(viper.GetString(adminUrl))
+	opPrivateKey := viper.GetString(adminPrivateKey)
+	if len(opPrivateKey) < 1 {
+		fmt.Println("Invalid operator private key!")
+		return
+	}
+	header, err := getAdminMsgHeader()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	txMsg := new(gasprice.FeeCurrencyMsg)
+	txMsg.Symbol = viper.GetString(feeCurSymbol)
+	txMsg.ExchangeRate = viper.GetString(feeCurRate)
+	txMsg.Remove = viper.GetBool(feeCurRemove)
+	key := crypto.NewSecretKeyEd25519(opPrivateKey)
+	keyAddr, _ := key.Address()
+	txMsg.FromAddr = fmt.Sprintf("%X", keyAddr)
+	builder := client2.NewTxMsgBuilder(*header, txMsg, serializer.NewTxSerializerCDC(), key)
+	fmt.Println("Start Sending transaction...")
+	txHash, cHeight, _, err := builder.BuildAndCommit(client)
+	if err != nil {
+		fmt.Println("Set fee currency failed.")
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println("Set fee currency success.")
+	fmt.Println("Transaction hash:", txHash)
+	fmt.Println("Block Height:", cHeight)
+}
+
+func addFeeCurrencyFlags(cmd *cobra.Command) {
+	err := addStringFlag(cmd, feeCurSymbol, symbolParam, "", "", "symbol of the fee currency", required)
+	if err != nil {
+		panic(err)
+	}
+	err = addStringFlag(cmd, feeCurRate, rateParam, "", "", "amount of the currency in whole tokens equivalent to one ANKR, eg. 0.5 or 1/2", notRequired)
+	if err != nil {
+		panic(err)
+	}
+	err = addBoolFlag(cmd, feeCurRemove, removeParam, "", false, "remove the currency from the fee currency whitelist", notRequired)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// upgrade --name --height [--info]
+func setUpgrade(cmd *cobra.Command, args []string) {
+	client := newAnkrHttpClient(viper.GetString(adminUrl))
+	opPrivateKey := viper.GetString(adminPrivateKey)
+	if len(opPrivateKey) < 1 {
+		fmt.Println("Invalid operator private key!")
+		return
+	}
+	header, err := getAdminMsgHeader()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	txMsg := new(upgrade.UpgradeMsg)
+	txMsg.Name = viper.GetString(upgradeName)
+	txMsg.Height = viper.GetInt64(upgradeHeight)
+	txMsg.Info = viper.GetString(upgradeInfo)
+	key := crypto.NewSecretKeyEd25519(opPrivateKey)
+	keyAddr, _ := key.Address()
+	txMsg.FromAddr = fmt.Sprintf("%X", keyAddr)
+	builder := client2.NewTxMsgBuilder(*header, txMsg, serializer.NewTxSerializerCDC(), key)
+	fmt.Println("Start Sending transaction...")
+	txHash, cHeight, _, err := builder.BuildAndCommit(client)
+	if err != nil {
+		fmt.Println("Set upgrade plan failed.")
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println("Set upgrade plan success.")
+	fmt.Println("Transaction hash:", txHash)
+	fmt.Println("Block Height:", cHeight)
+}
+
+func addUpgradeFlags(cmd *cobra.Command) {
+	err := addStringFlag(cmd, upgradeName, nameParam, "", "", "name of the upgrade", required)
+	if err != nil {
+		panic(err)
+	}
+	err = addInt64Flag(cmd, upgradeHeight, heightParam, "", 0, "height the upgrade is applied at, 0 cancels the upgrade scheduled", required)
+	if err != nil {
+		panic(err)
+	}
+	err = addStringFlag(cmd, upgradeInfo, infoParam, "", "", "info of the upgrade, eg. the url of the new binary", notRequired)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // get transaction header .
 func getAdminMsgHeader() (*client2.TxMsgHeader, error) {
 	chainId := viper.GetString(adminChId)
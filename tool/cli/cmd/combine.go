@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/serializer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	combineUrl    = "combineUrl"
+	combineOutput = "combineOutput"
+)
+
+// combineCmd represents the combine command
+var combineCmd = &cobra.Command{
+	Use:   "combine [partial signed files...]",
+	Short: "combine the partial signed transactions into a final transaction",
+	Args:  cobra.MinimumNArgs(1),
+	Run:   runCombine,
+}
+
+func init() {
+	err := addStringFlag(combineCmd, combineUrl, urlParam, "", "", "validator url, used to check the multisig account threshold", notRequired)
+	if err != nil {
+		panic(err)
+	}
+
+	err = addStringFlag(combineCmd, combineOutput, outputParam, "o", "", "output file of the combined transaction", notRequired)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// combineTxs merges the signatures of the partial signed transactions of the same tx, and verifies the combined one
+func combineTxs(txBytesList [][]byte) (*tx.TxMsg, error) {
+	txSerializer := serializer.NewTxSerializerCDC()
+
+	var txMsg *tx.TxMsg
+	for i, txBytes := range txBytesList {
+		partialTx, err := txSerializer.DeserializeCDCV1(txBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize partial signed transaction %d: %s", i, err.Error())
+		}
+
+		if txMsg == nil {
+			txMsg = partialTx
+			continue
+		}
+
+		err = txMsg.MergeSigns(txSerializer, partialTx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to combine transaction %d: %s", i, err.Error())
+		}
+	}
+
+	if txMsg == nil {
+		return nil, errors.New("no partial signed transaction")
+	}
+
+	err := txMsg.VerifySigns(txSerializer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify the combined transaction: %s", err.Error())
+	}
+
+	return txMsg, nil
+}
+
+func runCombine(cmd *cobra.Command, args []string) {
+	txSerializer := serializer.NewTxSerializerCDC()
+
+	txBytesList := make([][]byte, 0, len(args))
+	for _, partialFile := range args {
+		txBytes, err := ioutil.ReadFile(partialFile)
+		if err != nil {
+			fmt.Println("Failed to read transaction file:", partialFile, err.Error())
+			return
+		}
+		txBytesList = append(txBytesList, txBytes)
+	}
+
+	txMsg, err := combineTxs(txBytesList)
+	if err != nil {
+		fmt.Println("Error:", err.Error())
+		return
+	}
+
+	signerAddr := txMsg.SignerAddr()[0]
+	url := viper.GetString(combineUrl)
+	if url != "" {
+		msResp := new(common.MultiSigAccountQueryResp)
+		err = newAnkrHttpClient(url).Query("/store/multisigaccount", &common.MultiSigAccountQueryReq{Address: signerAddr}, msResp)
+		if err == nil && msResp.Address == signerAddr {
+			if uint32(len(txMsg.Signs)) < msResp.Threshold {
+				fmt.Printf("Not enough signatures for multisig account %s, got %d, expected %d\n", signerAddr, len(txMsg.Signs), msResp.Threshold)
+				return
+			}
+		}
+	}
+
+	combinedBytes, err := txSerializer.Serialize(txMsg)
+	if err != nil {
+		fmt.Println("Failed to serialize the combined transaction:", err.Error())
+		return
+	}
+
+	outFile := viper.GetString(combineOutput)
+	if outFile == "" {
+		outFile = fmt.Sprintf("signed-%s-%d", signerAddr, txMsg.Nonce)
+	}
+	err = WriteToFile(outFile, combinedBytes)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Println("signatures count:", len(txMsg.Signs))
+	fmt.Println("combined transaction is saved in:", outFile)
+}
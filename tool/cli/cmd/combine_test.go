@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Ankr-network/ankr-chain/client"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	ankrchain "github.com/Ankr-network/ankr-chain/consensus"
+	"github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx/token"
+	"github.com/smartystreets/goconvey/convey"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func newMultiSigTestKey() (crypto.SecretKey, string) {
+	privKey := ed25519.GenPrivKey()
+	pubKey  := privKey.PubKey().(ed25519.PubKeyEd25519)
+
+	return crypto.NewSecretKeyEd25519(base64.StdEncoding.EncodeToString(privKey[:])), base64.StdEncoding.EncodeToString(pubKey[:])
+}
+
+func TestSignAppendAndCombine(t *testing.T) {
+	convey.Convey("test offline sign append and combine", t, func() {
+		txContext := ankrchain.NewMockAnkrChainApplication("testApp", log.NewNopLogger())
+
+		key1, pubKey1 := newMultiSigTestKey()
+		key2, pubKey2 := newMultiSigTestKey()
+		_, pubKey3    := newMultiSigTestKey()
+
+		pubKeys := []string{pubKey1, pubKey2, pubKey3}
+		msAddr  := crypto.CreateMultiSigAddress(pubKeys, 2)
+		txContext.AppStore().SetMultiSigAccount(&ankrcmm.MultiSigAccountInfo{Address: msAddr, Threshold: 2, PubKeys: pubKeys})
+
+		rawTx := RawTransaction{
+			Header: &client.TxMsgHeader{
+				ChID:     "ankr-chain",
+				GasLimit: new(big.Int).SetUint64(1000000).Bytes(),
+				GasPrice: ankrcmm.Amount{Cur: ankrcmm.Currency{Symbol: "ANKR", Decimal: 18}, Value: new(big.Int).SetUint64(10000000000000).Bytes()},
+				Memo:     "multisig transfer",
+				Version:  "1.0",
+			},
+			TxMsg: &token.TransferMsg{FromAddr: msAddr, ToAddr: "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB", Amounts: []ankrcmm.Amount{{Cur: ankrcmm.Currency{Symbol: "ANKR", Decimal: 18}, Value: new(big.Int).SetUint64(100).Bytes()}}},
+			Nonce: 1,
+		}
+		rawTxBytes, err := json.Marshal(&rawTx)
+		convey.So(err, convey.ShouldBeNil)
+
+		// each member signs the raw transaction separately
+		partialTx1, partialBytes1, err := appendSign(rawTxBytes, key1)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(len(partialTx1.Signs), convey.ShouldEqual, 1)
+
+		_, partialBytes2, err := appendSign(rawTxBytes, key2)
+		convey.So(err, convey.ShouldBeNil)
+
+		// one signature doesn't reach the threshold
+		codeV, _ := partialTx1.BasicVerify(txContext)
+		convey.So(codeV, convey.ShouldEqual, code.CodeTypeMultiSigNotEnoughSigns)
+
+		combinedTx, err := combineTxs([][]byte{partialBytes1, partialBytes2})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(len(combinedTx.Signs), convey.ShouldEqual, 2)
+		convey.So(combinedTx.SignerAddr()[0], convey.ShouldEqual, msAddr)
+
+		codeV, errLog := combinedTx.BasicVerify(txContext)
+		convey.So(codeV, convey.ShouldEqual, code.CodeTypeOK)
+		convey.So(errLog, convey.ShouldEqual, "")
+
+		// appending to the partial signed transaction gives the same result as combining
+		_, appendedBytes, err := appendSign(partialBytes1, key2)
+		convey.So(err, convey.ShouldBeNil)
+		appendedTx, err := combineTxs([][]byte{appendedBytes})
+		convey.So(err, convey.ShouldBeNil)
+		codeV, _ = appendedTx.BasicVerify(txContext)
+		convey.So(codeV, convey.ShouldEqual, code.CodeTypeOK)
+
+		// the partial signed transactions of the other content can't be combined
+		rawTx.Header.Memo = "changed"
+		otherRawBytes, _ := json.Marshal(&rawTx)
+		_, otherBytes, err := appendSign(otherRawBytes, key2)
+		convey.So(err, convey.ShouldBeNil)
+		_, err = combineTxs([][]byte{partialBytes1, otherBytes})
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
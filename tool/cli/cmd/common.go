@@ -32,6 +32,9 @@ var (
 	chainIDParam      = "chain-id"
 	gasPriceParam     = "gas-price"
 	gasLimitParam     = "gas-limit"
+	gasSymbolParam    = "gas-symbol"
+	feePayerParam     = "fee-payer"
+	timeoutHeightParam = "timeout-height"
 
 	//transaction flags
 	toParam     = "to" //short name `t`
@@ -76,6 +79,10 @@ var (
 	nonceParam        = "nonce"
 	creatorParam      = "creator"
 	detailParam       = "detail"
+	appendParam       = "append"
+	rateParam         = "rate"
+	removeParam       = "remove"
+	infoParam         = "info"
 )
 
 var (
@@ -227,6 +234,21 @@ func addPersistentInt(cmd *cobra.Command, bindKeyName, keyName, shortName string
 	return nil
 }
 
+func addPersistentInt64(cmd *cobra.Command, bindKeyName, keyName, shortName string, defaultValue int64, description string, requiredFlag bool) error {
+	cmd.PersistentFlags().Int64P(keyName, shortName, defaultValue, description)
+	err := viper.BindPFlag(bindKeyName, cmd.PersistentFlags().Lookup(keyName))
+	if err != nil {
+		return err
+	}
+	if requiredFlag {
+		err = cmd.MarkPersistentFlagRequired(keyName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func appendSubCmd(parent *cobra.Command, cmdName, desc string, exec func(cmd *cobra.Command, args []string), flagFunc func(cmd *cobra.Command)) {
 	cmd := &cobra.Command{
 		Use:   cmdName,
@@ -154,16 +154,16 @@ func displayTxMsg(txMsg *core_types.ResultTx, detail bool)  {
 
 func displayTx(data []byte)  {
 	decoder := client2.NewTxDecoder()
-	tx, err  := decoder.Decode(data)
+	tx, err  := decoder.DecodeTx(data)
 	if err != nil {
 		fmt.Println("Decode transaction error!")
 		fmt.Println(err)
 		return
 	}
-	if viper.GetBool(blockTxTransferOnly) && tx.Type() != common3.TxMsgTypeTransfer {
+	if viper.GetBool(blockTxTransferOnly) && tx.Type != common3.TxMsgTypeTransfer {
 		return
 	}
-	decodeAndDisplay(tx)
+	displayStruct(tx)
 }
 
 func formatQueryContent(parameters map[string]string) string {
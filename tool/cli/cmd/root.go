@@ -19,6 +19,7 @@ func init() {
 	RootCmd.AddCommand(queryCmd)
 	RootCmd.AddCommand(subscribeCmd)
 	RootCmd.AddCommand(signCmd)
+	RootCmd.AddCommand(combineCmd)
 	RootCmd.AddCommand(broadcastCmd)
 	RootCmd.AddCommand(versionCmd)
 }
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/Ankr-network/ankr-chain/client"
 	"github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx"
 	"github.com/Ankr-network/ankr-chain/tx/serializer"
 	"io/ioutil"
 
@@ -15,6 +17,7 @@ import (
 var(
 	signFile = "signFile"
 	signKeyStore = "signKeyStore"
+	signAppend = "signAppend"
 )
 // signCmd represents the sign command
 var signCmd = &cobra.Command{
@@ -33,6 +36,11 @@ func init() {
 	if err != nil{
 		panic(err)
 	}
+
+	err = addBoolFlag(signCmd, signAppend, appendParam, "a", false, "append the signature to the raw or partial signed transaction without invalidating others", notRequired)
+	if err != nil{
+		panic(err)
+	}
 }
 
 func runSignTx(cmd *cobra.Command, args []string)  {
@@ -59,6 +67,11 @@ func runSignTx(cmd *cobra.Command, args []string)  {
 	keyAddr, _ := key.Address()
 	fromAddr := fmt.Sprintf("%X", keyAddr)
 
+	if viper.GetBool(signAppend) {
+		appendSignTx(txBytes, key, fromAddr)
+		return
+	}
+
 	var rawTx RawTransaction
 	err = json.Unmarshal(txBytes, &rawTx)
 	if err != nil {
@@ -89,3 +102,65 @@ func runSignTx(cmd *cobra.Command, args []string)  {
 	}
 	fmt.Println("signed transaction is saved in:", outFile)
 }
+
+// loadTxMsg accepts either a partial signed transaction or a raw transaction in json
+func loadTxMsg(txBytes []byte) (*tx.TxMsg, error) {
+	txMsg, err := serializer.NewTxSerializerCDC().DeserializeCDCV1(txBytes)
+	if err == nil {
+		return txMsg, nil
+	}
+
+	var rawTx RawTransaction
+	err = json.Unmarshal(txBytes, &rawTx)
+	if err != nil {
+		return nil, fmt.Errorf("neither raw nor partial signed transaction: %s", err.Error())
+	}
+
+	if rawTx.Header == nil || rawTx.TxMsg == nil {
+		return nil, errors.New("invalid raw transaction, blank header or tx msg")
+	}
+
+	return client.NewTxMsgBuilder(*rawTx.Header, rawTx.TxMsg, nil, nil).BuildUnsigned(rawTx.Nonce), nil
+}
+
+// appendSign appends the signature of key to the raw or partial signed transaction, and returns the partial signed
+// transaction with its serialized bytes
+func appendSign(txBytes []byte, key crypto.SecretKey) (*tx.TxMsg, []byte, error) {
+	txMsg, err := loadTxMsg(txBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load transaction: %s", err.Error())
+	}
+
+	txSerializer := serializer.NewTxSerializerCDC()
+	if len(txMsg.Signs) > 0 {
+		err = txMsg.VerifySigns(txSerializer)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to verify the existed signatures: %s", err.Error())
+		}
+	}
+
+	builder := client.NewTxMsgBuilder(client.TxMsgHeader{}, txMsg.ImplTxMsg, txSerializer, key)
+	partialTxBytes, err := builder.BuildAppend(txMsg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to sign the transaction: %s", err.Error())
+	}
+
+	return txMsg, partialTxBytes, nil
+}
+
+func appendSignTx(txBytes []byte, key crypto.SecretKey, signerAddr string) {
+	txMsg, partialTxBytes, err := appendSign(txBytes, key)
+	if err != nil {
+		fmt.Println("Error:", err.Error())
+		return
+	}
+
+	outFile := fmt.Sprintf("partial-%s-%s-%d", signerAddr, txMsg.SignerAddr()[0], txMsg.Nonce)
+	err = WriteToFile(outFile, partialTxBytes)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	fmt.Println("signatures count:", len(txMsg.Signs))
+	fmt.Println("partial signed transaction is saved in:", outFile)
+}
@@ -26,6 +26,9 @@ var (
 	transferChainId = "transferChainId"
 	transferGasPrice = "transferGasPrice"
 	transferGasLimit = "transferGasLimit"
+	transferGasSymbol = "transferGasSymbol"
+	transferFeePayer = "transferFeePayer"
+	transferTimeoutHeight = "transferTimeoutHeight"
 
 	//names of flags used in viper to bind keys
 	transferTo      = "transferTo"
@@ -53,6 +56,7 @@ var (
 	invokeReturn = "invokeReturn"
 	invokeKeyStore = "invokeKeyStore"
 	getContractAddr = "getContractAddr"
+	estimateFile = "estimateFile"
 	ankrTokenBase = 1e+18
 )
 
@@ -91,6 +95,21 @@ func init() {
 		panic(err)
 	}
 
+	err = addPersistentString(transactionCmd, transferGasSymbol, gasSymbolParam, "", "ANKR", "symbol of the currency paying the fee, it should be ANKR or a whitelisted fee currency", notRequired)
+	if err != nil {
+		panic(err)
+	}
+
+	err = addPersistentString(transactionCmd, transferFeePayer, feePayerParam, "", "", "address of the sponsor paying the fee, it should sign the transaction too", notRequired)
+	if err != nil {
+		panic(err)
+	}
+
+	err = addPersistentInt64(transactionCmd, transferTimeoutHeight, timeoutHeightParam, "", 0, "the transaction expires if it isn't included until this block height, 0 means never", notRequired)
+	if err != nil {
+		panic(err)
+	}
+
 	err = addPersistentString(transactionCmd, transferVersion, versionParam, "", "1.0.2", "block chain net version", notRequired)
 	if err != nil {
 		panic(err)
@@ -100,6 +119,7 @@ func init() {
 	appendSubCmd(transactionCmd, "deploy", "deploy smart contract", runDeploy, addDeployFlags)
 	appendSubCmd(transactionCmd, "invoke", "invoke smart contract", runInvoke, addInvokeFlags)
 	appendSubCmd(transactionCmd, "generate", "generate raw transaction and output to file in json", runGenRaw, addGenRawFlags)
+	appendSubCmd(transactionCmd, "estimate", "estimate the gas used of the raw or signed transaction", runEstimate, addEstimateFlags)
 }
 
 //transaction transfer functions
@@ -492,8 +512,13 @@ func getTxmsgHeader() (*client2.TxMsgHeader, error)  {
 	header.ChID = common.ChainID(chainId)
 	header.GasLimit = limitInt.Bytes()
 	header.GasPrice.Cur = ankrCurrency
+	if gasSymbol := viper.GetString(transferGasSymbol); gasSymbol != "" && gasSymbol != ankrCurrency.Symbol {
+		header.GasPrice.Cur = common.Currency{Symbol: gasSymbol, Decimal: 18}
+	}
 	header.GasPrice.Value = priceInt.Bytes()
 	header.Memo = viper.GetString(transferMemo)
+	header.FeePayer = viper.GetString(transferFeePayer)
+	header.TimeoutHeight = viper.GetInt64(transferTimeoutHeight)
 	return header, nil
 }
 
@@ -585,6 +610,56 @@ func addGenRawFlags(cmd *cobra.Command){
 	}
 }
 
+func runEstimate(cmd *cobra.Command, args []string) {
+	validatorUrl = viper.GetString(transferUrl)
+	if len(validatorUrl) < 1 {
+		fmt.Println("Please specify validator url using --nodeurl")
+		return
+	}
+
+	txBytes, err := ioutil.ReadFile(viper.GetString(estimateFile))
+	if err != nil {
+		fmt.Println("Failed to read transaction file:", err.Error())
+		return
+	}
+
+	txMsg, err := loadTxMsg(txBytes)
+	if err != nil {
+		fmt.Println("Failed to load transaction:", err.Error())
+		return
+	}
+
+	txBytes, err = serializer.NewTxSerializerCDC().Serialize(txMsg)
+	if err != nil {
+		fmt.Println("Failed to serialize transaction:", err.Error())
+		return
+	}
+
+	resp, err := newAnkrHttpClient(validatorUrl).EstimateGas(txBytes)
+	if err != nil {
+		fmt.Println("Failed to estimate gas:", err.Error())
+		return
+	}
+
+	fmt.Println("code:", resp.Code)
+	fmt.Println("log:", resp.Log)
+	fmt.Println("gas used:", resp.GasUsed)
+	fmt.Println("fee:", new(big.Int).SetBytes(resp.Fee.Value).String(), resp.Fee.Cur.Symbol)
+	for _, tag := range resp.Tags {
+		fmt.Printf("tag: %s=%s\n", tag.Key, tag.Value)
+	}
+	if resp.ContractResult != "" {
+		fmt.Println("contract result:", resp.ContractResult)
+	}
+}
+
+func addEstimateFlags(cmd *cobra.Command) {
+	err := addStringFlag(cmd, estimateFile, fileParam, "f", "", "the raw or signed transaction file", required)
+	if err != nil {
+		panic(err)
+	}
+}
+
 func isParamSet(keyName string) bool {
 	return transactionCmd.Flags().Changed(keyName)
 }
\ No newline at end of file
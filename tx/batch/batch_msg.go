@@ -0,0 +1,145 @@
+package batch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/wagon/exec/gas"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+const (
+	MaxBatchMsgCount = 64
+)
+
+// BatchMsg executes the inner messages in order as one atomic unit: all of them take effect or none does.
+// Every inner message must be signed by FromAddr, and the whole batch consumes one nonce.
+type BatchMsg struct {
+	FromAddr string          `json:"fromaddr"`
+	Msgs     []tx.ImplTxMsg  `json:"msgs"`
+}
+
+func NewBatchTxMsg() *tx.TxMsg {
+	return &tx.TxMsg{ImplTxMsg: new(BatchMsg)}
+}
+
+func (b *BatchMsg) SignerAddr() []string {
+	return []string {b.FromAddr}
+}
+
+func (b *BatchMsg) Type() string {
+	return txcmm.TxMsgTypeBatchMsg
+}
+
+func (b *BatchMsg) Bytes(txSerializer tx.TxSerializer) []byte {
+	bytes, _ :=  txSerializer.MarshalJSON(b)
+	return bytes
+}
+
+func (b *BatchMsg) SetSecretKey(sk ankrcrypto.SecretKey) {
+
+}
+
+func (b *BatchMsg) SecretKey() ankrcrypto.SecretKey {
+	return &ankrcrypto.SecretKeyEd25519{}
+}
+
+// PermitKey permits any key of the batch itself, the key is checked against every inner msg by the tx since BatchMsg
+// is a tx.TxMsgContainer
+func (b *BatchMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
+	return true
+}
+
+func (b *BatchMsg) InnerMsgs() []tx.ImplTxMsg {
+	return b.Msgs
+}
+
+func (b *BatchMsg) SenderAddr() string {
+	return b.FromAddr
+}
+
+func (b *BatchMsg) verifyMsgs() (uint32, string) {
+	if len(b.Msgs) == 0 || len(b.Msgs) > MaxBatchMsgCount {
+		return code.CodeTypeBatchMsgInvalid, fmt.Sprintf("BatchMsg ProcessTx, invalid msg count, got %d, expected 1~%d", len(b.Msgs), MaxBatchMsgCount)
+	}
+
+	for i, msg := range b.Msgs {
+		if msg == nil {
+			return code.CodeTypeBatchMsgInvalid, fmt.Sprintf("BatchMsg ProcessTx, blank msg %d", i)
+		}
+
+		if msg.Type() == txcmm.TxMsgTypeBatchMsg {
+			return code.CodeTypeBatchMsgInvalid, fmt.Sprintf("BatchMsg ProcessTx, nested batch msg %d", i)
+		}
+
+		signers := msg.SignerAddr()
+		if len(signers) != 1 || signers[0] != b.FromAddr {
+			return code.CodeTypeBatchMsgInvalid, fmt.Sprintf("BatchMsg ProcessTx, msg %d(%s) signer mismatch, got %v, expected %s", i, msg.Type(), signers, b.FromAddr)
+		}
+	}
+
+	return code.CodeTypeOK, ""
+}
+
+func (b *BatchMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string, []cmn.KVPair) {
+	if len(b.FromAddr) != ankrcmm.KeyAddressLen {
+		return code.CodeTypeInvalidAddress, fmt.Sprintf("BatchMsg ProcessTx, unexpected from address. Got %s, addr len=%d", b.FromAddr, len(b.FromAddr)), nil
+	}
+
+	codeT, log := b.verifyMsgs()
+	if codeT != code.CodeTypeOK {
+		return codeT, log, nil
+	}
+
+	nonce, _, _, _, err := context.AppStore().Nonce(b.FromAddr, 0, false)
+	if err != nil {
+		return code.CodeTypeGetStoreNonceError, fmt.Sprintf("BatchMsg ProcessTx, get nonce err: addr=%s, err=%s", b.FromAddr, err.Error()), nil
+	}
+
+	var logs []string
+	var tags []cmn.KVPair
+	tagMap := make(map[string]bool)
+	for i, msg := range b.Msgs {
+		codeT, log, msgTags := tx.ProcessInnerMsg(context, msg, metric, flag)
+		if codeT != code.CodeTypeOK {
+			// the writes of the msgs before are discarded with the branch the tx runs on
+			return codeT, fmt.Sprintf("BatchMsg ProcessTx, msg %d(%s) failed: %s", i, msg.Type(), log), nil
+		}
+
+		if log != "" {
+			logs = append(logs, log)
+		}
+
+		for _, tag := range msgTags {
+			tagKey := string(tag.Key) + "=" + string(tag.Value)
+			if string(tag.Key) == "app.type" || string(tag.Key) == "app.timestamp" || tagMap[tagKey] {
+				continue
+			}
+
+			tagMap[tagKey] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	if flag == tx.TxExeFlag_OnlyCheck || flag == tx.TxExeFlag_PreRun {
+		return code.CodeTypeOK, strings.Join(logs, ";"), nil
+	}
+
+	context.AppStore().SetNonce(b.FromAddr, nonce+1)
+
+	tvalue := time.Now().UnixNano()
+	tags = append(tags,
+		cmn.KVPair{Key: []byte("app.timestamp"), Value: []byte(strconv.FormatInt(tvalue, 10))},
+		cmn.KVPair{Key: []byte("app.type"), Value: []byte(txcmm.TxMsgTypeBatchMsg)},
+	)
+
+	return code.CodeTypeOK, strings.Join(logs, ";"), tags
+}
@@ -0,0 +1,50 @@
+package tx
+
+import (
+	"math/big"
+
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+// CheckTxType follows the type of RequestCheckTx in the later ABCI. The mempool rechecks the remaining txs after each
+// commit, and the recheck only verifies what the committed block may change. The mempool of this tendermint keeps the
+// txs in the order received and the ABCI has no priority for them, so the gas price doesn't order the txs. Instead the
+// pending txs of an account are limited by the nonce pool window, and the node may accept only the new txs paying a
+// multiple of the min gas price while its mempool is congested, so the txs paying more still get in.
+type CheckTxType int
+
+const (
+	CheckTxTypeNew CheckTxType = iota
+	CheckTxTypeRecheck
+)
+
+// recheckTx verifies the tx accepted before against the committed state and the pending txs rechecked ahead of it
+func (tx *TxMsg) recheckTx(context ContextTx, checkedFee *big.Int) (types.ResponseCheckTx, *big.Int) {
+	codeT, log := tx.verifyTimeoutHeight(context.ChainStateInfo().LatestHeight() + 1)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
+	}
+
+	codeT, log = tx.verifyNonce(context, context.NoncePool())
+	if codeT != code.CodeTypeOK {
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
+	}
+
+	codeT, log = tx.verifyMinGasPrice(context)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
+	}
+
+	codeT, log = tx.verifyFee(context, checkedFee)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
+	}
+
+	gasWanted := int64(0)
+	if checkedFee.Sign() > 0 {
+		gasWanted = new(big.Int).SetBytes(tx.GasLimit).Int64()
+	}
+
+	return types.ResponseCheckTx{Code: code.CodeTypeOK, GasWanted: gasWanted}, checkedFee
+}
@@ -12,4 +12,9 @@ const (
 	TxMsgTypeContractInvokeMsg  = "ContractInvokeMsg"
 	TxMsgTypeAddRole            = "AddRole"
 	TxMsgTypeDeleteRole         = "DeleteRole"
+	TxMsgTypeMultiSigAccountMsg = "MultiSigAccountMsg"
+	TxMsgTypeBatchMsg           = "BatchMsg"
+	TxMsgTypeMinGasPriceMsg     = "MinGasPriceMsg"
+	TxMsgTypeFeeCurrencyMsg     = "FeeCurrencyMsg"
+	TxMsgTypeUpgradeMsg         = "UpgradeMsg"
 )
\ No newline at end of file
@@ -15,11 +15,29 @@ type Publisher interface {
 }
 
 type ContextTx interface {
-	MinGasPrice() ankrcmm.Amount
+	MinGasPrice(symbol string) (ankrcmm.Amount, bool)
 	AppStore() appstore.AppStore
 	TxSerializer() TxSerializer
 	Contract() contract.Contract
 	Publisher() Publisher
 	Logger() log.Logger
 	ChainStateInfo() ankrcmm.ChainStateInfo
-}
\ No newline at end of file
+	NoncePool() *NoncePool
+	TxMsgRegistry() *TxMsgRegistry
+}
+type branchContextTx struct {
+	ContextTx
+	appStore appstore.AppStore
+}
+
+func (bc *branchContextTx) AppStore() appstore.AppStore {
+	return bc.appStore
+}
+
+// BranchContext returns the context working on a branch of the context's app store and the branch, the writes through
+// the context are kept in the branch until it is written
+func BranchContext(context ContextTx) (ContextTx, appstore.AppStore) {
+	branch := context.AppStore().Branch()
+
+	return &branchContextTx{context, branch}, branch
+}
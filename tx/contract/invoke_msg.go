@@ -95,16 +95,22 @@ func (ci *ContractInvokeMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetri
 
 	contractType    := ankrcmm.ContractType(cInfo.Codes[0])
 	contractPatt    := ankrcmm.ContractPatternType(cInfo.Codes[2])
-	contractContext := ankrcontext.NewContextContract(context.AppStore(), metricInjected, ci, cInfo, context.AppStore(), context.AppStore(), context.Publisher(), context.ChainStateInfo())
-	rtn, err := context.Contract().Call(contractContext, context.AppStore(), contractType, contractPatt, cInfo.Codes[ankrcmm.CodePrefixLen:], cInfo.Name, ci.Method, params, ci.RtnType)
+	// the contract call writes into its own branch, which is dropped if the call fails
+	callStore       := context.AppStore().Branch()
+	contractContext := ankrcontext.NewContextContract(callStore, metricInjected, ci, cInfo, callStore, callStore, context.Publisher(), context.ChainStateInfo())
+	rtn, err := context.Contract().Call(contractContext, callStore, contractType, contractPatt, cInfo.Codes[ankrcmm.CodePrefixLen:], cInfo.Name, ci.Method, params, ci.RtnType)
 	if err != nil {
+		callStore.Discard()
 		return code.CodeTypeCallContractErr, fmt.Sprintf("call contract err: contract=%s, method=%s, err=%v", ci.ContractAddr, ci.Method, err), nil
 	}
 
 	if !rtn.IsSuccess {
+		callStore.Discard()
 		return code.CodeTypeCallContractErr, fmt.Sprintf("call contract err: contract=%s, method=%s", ci.ContractAddr, ci.Method), nil
 	}
 
+	callStore.Write()
+
 	if flag == tx.TxExeFlag_PreRun {
 		return code.CodeTypeOK, "", nil
 	}
@@ -0,0 +1,68 @@
+package gasprice
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	NativeFeeDecimal      = 18
+	MaxExchangeRateLen    = 64  // the max length of the exchange rate string
+	MaxExchangeRateBits   = 128 // the max bit length of the exchange rate's numerator and denominator
+	MaxFeeCurrencyDecimal = 36  // the max decimal of the currency accepted as the fee currency
+)
+
+// ParseExchangeRate parses the exchange rate of the fee currency, which is the amount of the currency in whole tokens
+// equivalent to one ANKR. It is a positive decimal or fraction, eg. "0.5" or "1/2", and the exponent form isn't accepted.
+func ParseExchangeRate(rateStr string) (*big.Rat, error) {
+	if rateStr == "" || len(rateStr) > MaxExchangeRateLen {
+		return nil, fmt.Errorf("invalid exchange rate length %d, expected 1~%d", len(rateStr), MaxExchangeRateLen)
+	}
+
+	if strings.TrimLeft(rateStr, "0123456789./") != "" {
+		return nil, fmt.Errorf("invalid exchange rate %s, only digits, \".\" and \"/\" are accepted", rateStr)
+	}
+
+	rate, ok := new(big.Rat).SetString(rateStr)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %s", rateStr)
+	}
+
+	if rate.Num().BitLen() > MaxExchangeRateBits || rate.Denom().BitLen() > MaxExchangeRateBits {
+		return nil, fmt.Errorf("exchange rate %s out of range, its numerator and denominator must be within %d bits", rateStr, MaxExchangeRateBits)
+	}
+
+	return rate, nil
+}
+
+// VerifyFeeCurrencyDecimal checks the decimal of the currency the exchange rate is scaled by
+func VerifyFeeCurrencyDecimal(decimal int64) error {
+	if decimal < 0 || decimal > MaxFeeCurrencyDecimal {
+		return errors.New("fee currency decimal out of range")
+	}
+
+	return nil
+}
+
+// ConvertGasPrice converts the gas price in the smallest units of ANKR into the smallest units of the currency with the
+// decimal by the exchange rate, that is ankrPrice * rate * 10^(decimal-18). It is rounded up, so the converted price
+// is never below the value of the ANKR one.
+func ConvertGasPrice(ankrPrice *big.Int, rate *big.Rat, decimal int64) *big.Int {
+	priceNum := new(big.Int).Mul(ankrPrice, rate.Num())
+	priceDen := new(big.Int).Set(rate.Denom())
+
+	if decimal >= NativeFeeDecimal {
+		priceNum.Mul(priceNum, new(big.Int).Exp(big.NewInt(10), big.NewInt(decimal-NativeFeeDecimal), nil))
+	} else {
+		priceDen.Mul(priceDen, new(big.Int).Exp(big.NewInt(10), big.NewInt(NativeFeeDecimal-decimal), nil))
+	}
+
+	priceVal, remVal := new(big.Int).QuoRem(priceNum, priceDen, new(big.Int))
+	if remVal.Sign() > 0 {
+		priceVal.Add(priceVal, big.NewInt(1))
+	}
+
+	return priceVal
+}
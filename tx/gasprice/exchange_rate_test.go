@@ -0,0 +1,73 @@
+package gasprice
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExchangeRate(t *testing.T) {
+	rate, err := ParseExchangeRate("0.5")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1/2", rate.String())
+
+	rate, err = ParseExchangeRate("3/6")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "1/2", rate.String())
+
+	invalidRates := []string{"", "0", "0/1", "-1", "1/0", "abc", "1e3", "1E3", " 1", "1.5/2/3",
+		strings.Repeat("1", MaxExchangeRateLen+1),
+		new(big.Int).Lsh(big.NewInt(1), MaxExchangeRateBits).String(),
+		"1/" + new(big.Int).Lsh(big.NewInt(1), MaxExchangeRateBits).String(),
+	}
+	for _, rateStr := range invalidRates {
+		_, err = ParseExchangeRate(rateStr)
+		assert.NotEqual(t, nil, err, rateStr)
+	}
+
+	// the bounds are inclusive
+	_, err = ParseExchangeRate(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), MaxExchangeRateBits), big.NewInt(1)).String())
+	assert.Equal(t, nil, err)
+}
+
+func TestVerifyFeeCurrencyDecimal(t *testing.T) {
+	assert.Equal(t, nil, VerifyFeeCurrencyDecimal(0))
+	assert.Equal(t, nil, VerifyFeeCurrencyDecimal(MaxFeeCurrencyDecimal))
+	assert.NotEqual(t, nil, VerifyFeeCurrencyDecimal(-1))
+	assert.NotEqual(t, nil, VerifyFeeCurrencyDecimal(MaxFeeCurrencyDecimal+1))
+}
+
+func TestConvertGasPrice(t *testing.T) {
+	testCases := []struct {
+		ankrPrice int64
+		rate      string
+		decimal   int64
+		expected  string
+	}{
+		// the same decimal as ANKR
+		{10000000000000, "1", 18, "10000000000000"},
+		{10000000000000, "2", 18, "20000000000000"},
+		{10000000000000, "1/2", 18, "5000000000000"},
+		// the currency of 6 decimals: 1e13 * 2 / 1e12
+		{10000000000000, "2", 6, "20"},
+		{10000000000000, "0.25", 6, "3"},
+		// the currency of 20 decimals: 1e13 * 1.5 * 100
+		{10000000000000, "1.5", 20, "1500000000000000"},
+		// rounded up, so the price never falls to zero or below the ANKR price's value
+		{3, "1/2", 18, "2"},
+		{10, "1/3", 20, "334"},
+		{1, "1", 6, "1"},
+		{1, "1/1000000", 0, "1"},
+		{1000000000000, "1", 0, "1"},
+		{1000000000000000000, "1", 0, "1"},
+		{1000000000000000001, "1", 0, "2"},
+	}
+
+	for _, tc := range testCases {
+		rate, err := ParseExchangeRate(tc.rate)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, tc.expected, ConvertGasPrice(big.NewInt(tc.ankrPrice), rate, tc.decimal).String(), "price=%d, rate=%s, decimal=%d", tc.ankrPrice, tc.rate, tc.decimal)
+	}
+}
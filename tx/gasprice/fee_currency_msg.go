@@ -0,0 +1,115 @@
+package gasprice
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Ankr-network/ankr-chain/account"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/wagon/exec/gas"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+const (
+	NativeFeeSymbol = "ANKR"
+)
+
+// FeeCurrencyMsg adds the currency into the fee currency whitelist or updates its exchange rate, and the currency is
+// removed from the whitelist if Remove is true. ANKR is always accepted and can't be changed by it.
+type FeeCurrencyMsg struct {
+	FromAddr     string `json:"fromaddr"`
+	Symbol       string `json:"symbol"`
+	ExchangeRate string `json:"exchangerate"` // the amount of the currency in whole tokens equivalent to one ANKR, see ParseExchangeRate
+	Remove       bool   `json:"remove"`
+}
+
+func NewFeeCurrencyTxMsg() *tx.TxMsg {
+	return &tx.TxMsg{ImplTxMsg: new(FeeCurrencyMsg)}
+}
+
+func (fc *FeeCurrencyMsg) SignerAddr() []string {
+	return []string {fc.FromAddr}
+}
+
+func (fc *FeeCurrencyMsg) Type() string {
+	return txcmm.TxMsgTypeFeeCurrencyMsg
+}
+
+func (fc *FeeCurrencyMsg) Bytes(txSerializer tx.TxSerializer) []byte {
+	bytes, _ := txSerializer.MarshalJSON(fc)
+	return bytes
+}
+
+func (fc *FeeCurrencyMsg) SetSecretKey(sk ankrcrypto.SecretKey) {
+
+}
+
+func (fc *FeeCurrencyMsg) SecretKey() ankrcrypto.SecretKey {
+	return &ankrcrypto.SecretKeyEd25519{}
+}
+
+func (fc *FeeCurrencyMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
+	return account.AccountManagerInstance().IsAdminPubKey(store, ankrcmm.AccountAdminOP, pubKey)
+}
+
+func (fc *FeeCurrencyMsg) SenderAddr() string {
+	return fc.FromAddr
+}
+
+func (fc *FeeCurrencyMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string, []cmn.KVPair) {
+	if len(fc.FromAddr) != ankrcmm.KeyAddressLen {
+		return code.CodeTypeInvalidAddress, fmt.Sprintf("FeeCurrencyMsg ProcessTx, unexpected from address. Got %s, addr len=%d", fc.FromAddr, len(fc.FromAddr)), nil
+	}
+
+	if fc.Symbol == "" || fc.Symbol == NativeFeeSymbol {
+		return code.CodeTypeInvalidFeeCurrency, fmt.Sprintf("FeeCurrencyMsg ProcessTx, invalid fee currency symbol: %s", fc.Symbol), nil
+	}
+
+	if fc.Remove {
+		feeCurInfo, _, _, _, err := context.AppStore().FeeCurrency(fc.Symbol, 0, false)
+		if err != nil || feeCurInfo == nil {
+			return code.CodeTypeInvalidFeeCurrency, fmt.Sprintf("FeeCurrencyMsg ProcessTx, %s isn't a fee currency", fc.Symbol), nil
+		}
+	} else {
+		curInfo, _, _, _, err := context.AppStore().CurrencyInfo(fc.Symbol, 0, false)
+		if err != nil || curInfo == nil {
+			return code.CodeTypeInvalidFeeCurrency, fmt.Sprintf("FeeCurrencyMsg ProcessTx, unknown currency: symbol=%s", fc.Symbol), nil
+		}
+
+		if err = VerifyFeeCurrencyDecimal(curInfo.Decimal); err != nil {
+			return code.CodeTypeInvalidFeeCurrency, fmt.Sprintf("FeeCurrencyMsg ProcessTx, %s: symbol=%s, decimal=%d", err.Error(), fc.Symbol, curInfo.Decimal), nil
+		}
+
+		if _, err = ParseExchangeRate(fc.ExchangeRate); err != nil {
+			return code.CodeTypeInvalidFeeCurrency, fmt.Sprintf("FeeCurrencyMsg ProcessTx, %s", err.Error()), nil
+		}
+	}
+
+	if flag == tx.TxExeFlag_OnlyCheck || flag == tx.TxExeFlag_PreRun {
+		return code.CodeTypeOK, "", nil
+	}
+
+	if fc.Remove {
+		context.AppStore().DeleteFeeCurrency(fc.Symbol)
+	} else {
+		context.AppStore().SetFeeCurrency(&ankrcmm.FeeCurrencyInfo{fc.Symbol, fc.ExchangeRate})
+	}
+
+	context.AppStore().IncNonce(fc.FromAddr)
+
+	tvalue := time.Now().UnixNano()
+	tags := []cmn.KVPair{
+		{Key: []byte("app.fromaddress"), Value: []byte(fc.FromAddr)},
+		{Key: []byte("app.symbol"), Value: []byte(fc.Symbol)},
+		{Key: []byte("app.timestamp"), Value: []byte(strconv.FormatInt(tvalue, 10))},
+		{Key: []byte("app.type"), Value: []byte(txcmm.TxMsgTypeFeeCurrencyMsg)},
+	}
+
+	return code.CodeTypeOK, "", tags
+}
@@ -0,0 +1,92 @@
+package gasprice
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/Ankr-network/ankr-chain/account"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/wagon/exec/gas"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+// MinGasPriceMsg sets the min gas price of one fee currency, and it can only be signed by the admin op account.
+type MinGasPriceMsg struct {
+	FromAddr    string         `json:"fromaddr"`
+	MinGasPrice ankrcmm.Amount `json:"mingasprice"`
+}
+
+func NewMinGasPriceTxMsg() *tx.TxMsg {
+	return &tx.TxMsg{ImplTxMsg: new(MinGasPriceMsg)}
+}
+
+func (mp *MinGasPriceMsg) SignerAddr() []string {
+	return []string {mp.FromAddr}
+}
+
+func (mp *MinGasPriceMsg) Type() string {
+	return txcmm.TxMsgTypeMinGasPriceMsg
+}
+
+func (mp *MinGasPriceMsg) Bytes(txSerializer tx.TxSerializer) []byte {
+	bytes, _ := txSerializer.MarshalJSON(mp)
+	return bytes
+}
+
+func (mp *MinGasPriceMsg) SetSecretKey(sk ankrcrypto.SecretKey) {
+
+}
+
+func (mp *MinGasPriceMsg) SecretKey() ankrcrypto.SecretKey {
+	return &ankrcrypto.SecretKeyEd25519{}
+}
+
+func (mp *MinGasPriceMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
+	return account.AccountManagerInstance().IsAdminPubKey(store, ankrcmm.AccountAdminOP, pubKey)
+}
+
+func (mp *MinGasPriceMsg) SenderAddr() string {
+	return mp.FromAddr
+}
+
+func (mp *MinGasPriceMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string, []cmn.KVPair) {
+	if len(mp.FromAddr) != ankrcmm.KeyAddressLen {
+		return code.CodeTypeInvalidAddress, fmt.Sprintf("MinGasPriceMsg ProcessTx, unexpected from address. Got %s, addr len=%d", mp.FromAddr, len(mp.FromAddr)), nil
+	}
+
+	symbol := mp.MinGasPrice.Cur.Symbol
+	if symbol == "" {
+		return code.CodeTypeInvalidMinGasPrice, "MinGasPriceMsg ProcessTx, blank currency symbol", nil
+	}
+
+	curInfo, _, _, _, err := context.AppStore().CurrencyInfo(symbol, 0, false)
+	if err != nil || curInfo == nil {
+		return code.CodeTypeInvalidMinGasPrice, fmt.Sprintf("MinGasPriceMsg ProcessTx, unknown currency: symbol=%s", symbol), nil
+	}
+
+	if flag == tx.TxExeFlag_OnlyCheck || flag == tx.TxExeFlag_PreRun {
+		return code.CodeTypeOK, "", nil
+	}
+
+	context.AppStore().SetMinGasPrice(&ankrcmm.Amount{ankrcmm.Currency{symbol, curInfo.Decimal}, mp.MinGasPrice.Value})
+
+	context.AppStore().IncNonce(mp.FromAddr)
+
+	tvalue := time.Now().UnixNano()
+	tags := []cmn.KVPair{
+		{Key: []byte("app.fromaddress"), Value: []byte(mp.FromAddr)},
+		{Key: []byte("app.symbol"), Value: []byte(symbol)},
+		{Key: []byte("app.mingasprice"), Value: []byte(new(big.Int).SetBytes(mp.MinGasPrice.Value).String())},
+		{Key: []byte("app.timestamp"), Value: []byte(strconv.FormatInt(tvalue, 10))},
+		{Key: []byte("app.type"), Value: []byte(txcmm.TxMsgTypeMinGasPriceMsg)},
+	}
+
+	return code.CodeTypeOK, "", tags
+}
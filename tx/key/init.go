@@ -1,13 +0,0 @@
-package key
-
-/*
-import (
-	"github.com/Ankr-network/ankr-chain/router"
-	ankrtypes "github.com/Ankr-network/ankr-chain/types"
-)
-
-func init() {
-	router.MsgRouterInstance().AddTxMessageHandler(ankrtypes.SetOpPrefix, NewAdminAccountMsgTxMsg())
-}
-
-*/
\ No newline at end of file
@@ -1,8 +1,6 @@
 package key
 
 import (
-	"bytes"
-	"encoding/base64"
 	"fmt"
 
 	"github.com/Ankr-network/ankr-chain/account"
@@ -49,18 +47,12 @@ func (k *KeyMsg) SecretKey() ankrcrypto.SecretKey {
 }
 
 func (k *KeyMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
-	adminPubkey := account.AccountManagerInstance().AdminOpAccount(ankrcmm.AccountAdminOP)
-	adminPubKeyStr, err := base64.StdEncoding.DecodeString(adminPubkey)
-	if err != nil {
-		return false
-	}
-
-	return  bytes.Equal(pubKey, []byte(adminPubKeyStr))
+	return account.AccountManagerInstance().IsAdminPubKey(store, ankrcmm.AccountAdminOP, pubKey)
 }
 
 func (k *KeyMsg) ProcessTx(context tx.ContextTx,  metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string, []cmn.KVPair) {
-	if k.KeyName != ankrcmm.ADMIN_OP_VAL_PUBKEY_NAME && k.KeyName != ankrcmm.ADMIN_OP_FUND_PUBKEY_NAME &&
-		k.KeyName != ankrcmm.ADMIN_OP_METERING_PUBKEY_NAME {
+	if k.KeyName != ankrcmm.ADMIN_OP_PUBKEY_NAME && k.KeyName != ankrcmm.ADMIN_OP_VAL_PUBKEY_NAME &&
+		k.KeyName != ankrcmm.ADMIN_OP_FUND_PUBKEY_NAME && k.KeyName != ankrcmm.ADMIN_OP_METERING_PUBKEY_NAME {
 		return code.CodeTypeEncodingError, fmt.Sprintf("Unexpected keyname. Got %v", k.KeyName), nil
 	}
 
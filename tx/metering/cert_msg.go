@@ -1,7 +1,6 @@
 package metering
 
 import (
-	"bytes"
 	"fmt"
 
 	"github.com/Ankr-network/ankr-chain/account"
@@ -44,17 +43,7 @@ func (sc *SetCertMsg) SecretKey() ankrcrypto.SecretKey {
 }
 
 func (sc *SetCertMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
-	adminPubkeyBase64 := string(store.Get([]byte(ankrcmm.ADMIN_OP_METERING_PUBKEY_NAME)))
-	if len(adminPubkeyBase64) == 0 {
-		adminPubkeyBase64 = account.AccountManagerInstance().AdminOpAccount(ankrcmm.AccountAdminMetering)
-	}
-
-	adminPubKey, err := ankrcmm.DeserilizePubKey(adminPubkeyBase64)
-	if err != nil {
-		return false
-	}
-
-	return  bytes.Equal(pubKey, adminPubKey.Bytes())
+	return account.AccountManagerInstance().IsAdminPubKey(store, ankrcmm.AccountAdminMetering, pubKey)
 }
 
 func (sc *SetCertMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string, []cmn.KVPair) {
@@ -106,17 +95,7 @@ func (rc *RemoveCertMsg) SecretKey() ankrcrypto.SecretKey {
 }
 
 func (sc *RemoveCertMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
-	adminPubkeyBase64 := string(store.Get([]byte(ankrcmm.ADMIN_OP_METERING_PUBKEY_NAME)))
-	if len(adminPubkeyBase64) == 0 {
-		adminPubkeyBase64 = account.AccountManagerInstance().AdminOpAccount(ankrcmm.AccountAdminMetering)
-	}
-
-	adminPubKey, err := ankrcmm.DeserilizePubKey(adminPubkeyBase64)
-	if err != nil {
-		return false
-	}
-
-	return  bytes.Equal(pubKey, adminPubKey.Bytes())
+	return account.AccountManagerInstance().IsAdminPubKey(store, ankrcmm.AccountAdminMetering, pubKey)
 }
 
 func (rc *RemoveCertMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string, []cmn.KVPair) {
@@ -1,15 +0,0 @@
-package metering
-
-/*
-import (
-	"github.com/Ankr-network/ankr-chain/router"
-	ankrtypes "github.com/Ankr-network/ankr-chain/types"
-)
-
-func init() {
-	router.MsgRouterInstance().AddTxMessageHandler(ankrtypes.SetMeteringPrefix, NewMeteringTxMsg())
-	router.MsgRouterInstance().AddTxMessageHandler(ankrtypes.SetCertPrefix, NewSetCertTxMsg())
-	router.MsgRouterInstance().AddTxMessageHandler(ankrtypes.RemoveCertPrefix, NewRemoveCertTxMsg())
-}
-
-*/
@@ -0,0 +1,140 @@
+package multisig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/wagon/exec/gas"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+const (
+	MaxMultiSigPubKeys = 16
+)
+
+// MultiSigAccountMsg creates a new multisig account if MultiSigAddr is blank, or else updates the existed one's
+// public keys and threshold. The update must be authorized by the multisig account itself.
+type MultiSigAccountMsg struct {
+	FromAddr     string   `json:"fromaddr"`
+	MultiSigAddr string   `json:"multisigaddr"`
+	Threshold    uint32   `json:"threshold"`
+	PubKeys      []string `json:"pubkeys"`
+}
+
+func NewMultiSigAccountTxMsg() *tx.TxMsg {
+	return &tx.TxMsg{ImplTxMsg: new(MultiSigAccountMsg)}
+}
+
+func (ms *MultiSigAccountMsg) SignerAddr() []string {
+	if ms.MultiSigAddr != "" {
+		return []string {ms.MultiSigAddr}
+	}
+
+	return []string {ms.FromAddr}
+}
+
+func (ms *MultiSigAccountMsg) Type() string {
+	return txcmm.TxMsgTypeMultiSigAccountMsg
+}
+
+func (ms *MultiSigAccountMsg) Bytes(txSerializer tx.TxSerializer) []byte {
+	bytes, _ :=  txSerializer.MarshalJSON(ms)
+	return bytes
+}
+
+func (ms *MultiSigAccountMsg) SetSecretKey(sk ankrcrypto.SecretKey) {
+
+}
+
+func (ms *MultiSigAccountMsg) SecretKey() ankrcrypto.SecretKey {
+	return &ankrcrypto.SecretKeyEd25519{}
+}
+
+func (ms *MultiSigAccountMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
+	return true
+}
+
+func (ms *MultiSigAccountMsg) SenderAddr() string {
+	return ms.SignerAddr()[0]
+}
+
+func (ms *MultiSigAccountMsg) verifyPubKeys() (uint32, string) {
+	if len(ms.PubKeys) == 0 || len(ms.PubKeys) > MaxMultiSigPubKeys {
+		return code.CodeTypeMultiSigInvalidPubKey, fmt.Sprintf("MultiSigAccountMsg ProcessTx, invalid public key count, got %d, expected 1~%d", len(ms.PubKeys), MaxMultiSigPubKeys)
+	}
+
+	if ms.Threshold == 0 || int(ms.Threshold) > len(ms.PubKeys) {
+		return code.CodeTypeMultiSigInvalidThreshold, fmt.Sprintf("MultiSigAccountMsg ProcessTx, invalid threshold, got %d, expected 1~%d", ms.Threshold, len(ms.PubKeys))
+	}
+
+	pubKeyMap := make(map[string]bool)
+	for _, pubKey := range ms.PubKeys {
+		pubKeyBytes, err := base64.StdEncoding.DecodeString(pubKey)
+		if err != nil || len(pubKeyBytes) != ankrcrypto.PubKeyEd25519Size {
+			return code.CodeTypeMultiSigInvalidPubKey, fmt.Sprintf("MultiSigAccountMsg ProcessTx, invalid public key: %s", pubKey)
+		}
+
+		if pubKeyMap[pubKey] {
+			return code.CodeTypeMultiSigInvalidPubKey, fmt.Sprintf("MultiSigAccountMsg ProcessTx, duplicated public key: %s", pubKey)
+		}
+		pubKeyMap[pubKey] = true
+	}
+
+	return code.CodeTypeOK, ""
+}
+
+func (ms *MultiSigAccountMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string, []cmn.KVPair) {
+	if len(ms.FromAddr) != ankrcmm.KeyAddressLen {
+		return code.CodeTypeInvalidAddress, fmt.Sprintf("MultiSigAccountMsg ProcessTx, unexpected from address. Got %s, addr len=%d", ms.FromAddr, len(ms.FromAddr)), nil
+	}
+
+	codeT, log := ms.verifyPubKeys()
+	if codeT != code.CodeTypeOK {
+		return codeT, log, nil
+	}
+
+	msAddr := ms.MultiSigAddr
+	if msAddr == "" {
+		msAddr = ankrcrypto.CreateMultiSigAddress(ms.PubKeys, ms.Threshold)
+		msInfo, _, _, _, err := context.AppStore().MultiSigAccount(msAddr, 0, false)
+		if err != nil {
+			return code.CodeTypeMultiSigAccountNotExist, fmt.Sprintf("MultiSigAccountMsg ProcessTx, load multisig account err: addr=%s, err=%s", msAddr, err.Error()), nil
+		}
+		if msInfo != nil {
+			return code.CodeTypeMultiSigAccountExisted, fmt.Sprintf("MultiSigAccountMsg ProcessTx, the multisig account has existed: addr=%s", msAddr), nil
+		}
+	} else {
+		msInfo, _, _, _, err := context.AppStore().MultiSigAccount(msAddr, 0, false)
+		if err != nil || msInfo == nil {
+			return code.CodeTypeMultiSigAccountNotExist, fmt.Sprintf("MultiSigAccountMsg ProcessTx, can't find the multisig account: addr=%s", msAddr), nil
+		}
+	}
+
+	if flag == tx.TxExeFlag_OnlyCheck || flag == tx.TxExeFlag_PreRun {
+		return code.CodeTypeOK, "", nil
+	}
+
+	context.AppStore().SetMultiSigAccount(&ankrcmm.MultiSigAccountInfo{Address: msAddr, Threshold: ms.Threshold, PubKeys: ms.PubKeys})
+
+	context.AppStore().AddAccount(msAddr, ankrcmm.AccountMultiSig)
+
+	context.AppStore().IncNonce(ms.SignerAddr()[0])
+
+	tvalue := time.Now().UnixNano()
+	tags := []cmn.KVPair{
+		{Key: []byte("app.fromaddress"), Value: []byte(ms.FromAddr)},
+		{Key: []byte("app.multisigaddr"), Value: []byte(msAddr)},
+		{Key: []byte("app.timestamp"), Value: []byte(strconv.FormatInt(tvalue, 10))},
+		{Key: []byte("app.type"), Value: []byte(txcmm.TxMsgTypeMultiSigAccountMsg)},
+	}
+
+	return code.CodeTypeOK, msAddr, tags
+}
@@ -0,0 +1,96 @@
+package tx
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/Ankr-network/ankr-chain/common/code"
+)
+
+const (
+	DefaultPendingNonceWindow = 16
+)
+
+// NoncePool tracks the nonces and the fees of the txs accepted by CheckTx but not committed yet, so an account can pipeline
+// at most window txs with the successive nonces in the mempool, and the fees of them can't exceed its balance. It should be
+// reset after each commit and the mempool rechecks will rebuild it from the remaining txs.
+type NoncePool struct {
+	window        uint64
+	pendingMap    map[string]uint64
+	pendingFeeMap map[string]*big.Int
+	poolLocker    sync.Mutex
+}
+
+func NewNoncePool(window uint64) *NoncePool {
+	return &NoncePool{window: window, pendingMap: make(map[string]uint64), pendingFeeMap: make(map[string]*big.Int)}
+}
+
+func (np *NoncePool) SetWindow(window uint64) {
+	np.poolLocker.Lock()
+	defer np.poolLocker.Unlock()
+
+	np.window = window
+}
+
+// Verify accepts the tx nonce only if it is the next one after the pending txs of the account and within the window
+func (np *NoncePool) Verify(address string, storeNonce uint64, txNonce uint64) (uint32, string) {
+	np.poolLocker.Lock()
+	defer np.poolLocker.Unlock()
+
+	expectedNonce := storeNonce
+	if pendingNonce, ok := np.pendingMap[address]; ok && pendingNonce > storeNonce {
+		expectedNonce = pendingNonce
+	}
+
+	if txNonce != expectedNonce {
+		return code.CodeTypeBadNonce, fmt.Sprintf("bad nonce: address=%s, onceStore=%d, expected=%d, tx.Nonce=%d", address, storeNonce, expectedNonce, txNonce)
+	}
+
+	if txNonce-storeNonce > np.window {
+		return code.CodeTypeBadNonce, fmt.Sprintf("too many pending txs: address=%s, onceStore=%d, tx.Nonce=%d, window=%d", address, storeNonce, txNonce, np.window)
+	}
+
+	return code.CodeTypeOK, ""
+}
+
+func (np *NoncePool) Accept(address string, txNonce uint64) {
+	np.poolLocker.Lock()
+	defer np.poolLocker.Unlock()
+
+	if txNonce+1 > np.pendingMap[address] {
+		np.pendingMap[address] = txNonce + 1
+	}
+}
+
+// PendingFee returns the total fee in the symbol the pending txs of the address pay
+func (np *NoncePool) PendingFee(address string, symbol string) *big.Int {
+	np.poolLocker.Lock()
+	defer np.poolLocker.Unlock()
+
+	if fee, ok := np.pendingFeeMap[address+"/"+symbol]; ok {
+		return new(big.Int).Set(fee)
+	}
+
+	return new(big.Int)
+}
+
+func (np *NoncePool) AcceptFee(address string, symbol string, fee *big.Int) {
+	np.poolLocker.Lock()
+	defer np.poolLocker.Unlock()
+
+	feeKey := address + "/" + symbol
+	if pendingFee, ok := np.pendingFeeMap[feeKey]; ok {
+		np.pendingFeeMap[feeKey] = new(big.Int).Add(pendingFee, fee)
+	} else {
+		np.pendingFeeMap[feeKey] = new(big.Int).Set(fee)
+	}
+}
+
+func (np *NoncePool) Reset() {
+	np.poolLocker.Lock()
+	defer np.poolLocker.Unlock()
+
+	np.pendingMap    = make(map[string]uint64)
+	np.pendingFeeMap = make(map[string]*big.Int)
+}
@@ -0,0 +1,83 @@
+package tx
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Ankr-network/ankr-chain/common/code"
+	"github.com/stretchr/testify/assert"
+)
+
+func verifyCode(np *NoncePool, address string, storeNonce uint64, txNonce uint64) uint32 {
+	codeV, _ := np.Verify(address, storeNonce, txNonce)
+	return codeV
+}
+
+func TestNoncePoolWindow(t *testing.T) {
+	np := NewNoncePool(2)
+
+	// the nonce must follow the store nonce if no tx is pending
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 5, 4))
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 5, 6))
+	assert.Equal(t, code.CodeTypeOK, verifyCode(np, "addr1", 5, 5))
+	np.Accept("addr1", 5)
+
+	// the successive nonces are accepted within the window over the store nonce
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 5, 5))
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 5, 7))
+	assert.Equal(t, code.CodeTypeOK, verifyCode(np, "addr1", 5, 6))
+	np.Accept("addr1", 6)
+	assert.Equal(t, code.CodeTypeOK, verifyCode(np, "addr1", 5, 7))
+	np.Accept("addr1", 7)
+
+	// the nonce out of the window is refused
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 5, 8))
+
+	// the window moves with the store nonce
+	assert.Equal(t, code.CodeTypeOK, verifyCode(np, "addr1", 6, 8))
+
+	// the pending nonces behind the store nonce are ignored
+	assert.Equal(t, code.CodeTypeOK, verifyCode(np, "addr1", 9, 9))
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 9, 8))
+
+	// the accounts don't affect each other
+	assert.Equal(t, code.CodeTypeOK, verifyCode(np, "addr2", 1, 1))
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr2", 1, 8))
+
+	// the window 0 accepts the store nonce only
+	np.SetWindow(0)
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 5, 8))
+	assert.Equal(t, code.CodeTypeOK, verifyCode(np, "addr2", 1, 1))
+	np.Accept("addr2", 1)
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr2", 1, 2))
+}
+
+func TestNoncePoolFee(t *testing.T) {
+	np := NewNoncePool(DefaultPendingNonceWindow)
+	assert.Equal(t, big.NewInt(0), np.PendingFee("addr1", "ANKR"))
+
+	np.AcceptFee("addr1", "ANKR", big.NewInt(100))
+	np.AcceptFee("addr1", "ANKR", big.NewInt(50))
+	np.AcceptFee("addr1", "USDX", big.NewInt(7))
+	assert.Equal(t, big.NewInt(150), np.PendingFee("addr1", "ANKR"))
+	assert.Equal(t, big.NewInt(7), np.PendingFee("addr1", "USDX"))
+	assert.Equal(t, big.NewInt(0), np.PendingFee("addr2", "ANKR"))
+
+	// the returned fee is a copy
+	np.PendingFee("addr1", "ANKR").SetInt64(0)
+	assert.Equal(t, big.NewInt(150), np.PendingFee("addr1", "ANKR"))
+}
+
+func TestNoncePoolReset(t *testing.T) {
+	np := NewNoncePool(2)
+	np.Accept("addr1", 1)
+	np.Accept("addr1", 2)
+	np.AcceptFee("addr1", "ANKR", big.NewInt(100))
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 1, 1))
+
+	// the pending txs are forgotten, and the rechecks accept them again from the store nonce
+	np.Reset()
+	assert.Equal(t, big.NewInt(0), np.PendingFee("addr1", "ANKR"))
+	assert.Equal(t, code.CodeTypeOK, verifyCode(np, "addr1", 1, 1))
+	assert.Equal(t, code.CodeTypeBadNonce, verifyCode(np, "addr1", 1, 2))
+}
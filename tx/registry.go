@@ -0,0 +1,89 @@
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+)
+
+// TxMsgPermission decides whether the public key is permitted to sign the msg
+type TxMsgPermission func(store appstore.AppStore, msg ImplTxMsg, pubKey []byte) bool
+
+// TxMsgInfo describes one tx msg type the application accepts
+type TxMsgInfo struct {
+	Type       string          // the same as the msg's Type()
+	CodecName  string          // the concrete name registered into the tx codec
+	Msg        ImplTxMsg       // a pointer to the blank msg, whose concrete type is registered into the tx codec
+	BaseGas    uint64          // the gas charged before the msg is processed, it is fixed once the msg is registered
+	Permission TxMsgPermission // the msg's PermitKey is used if it is nil
+}
+
+// TxMsgRegistry holds the tx msg types the application accepts, it is built at startup before the tx codec is created
+type TxMsgRegistry struct {
+	infoMap        map[string]*TxMsgInfo
+	infos          []*TxMsgInfo
+	registryLocker sync.RWMutex
+}
+
+func NewTxMsgRegistry() *TxMsgRegistry {
+	return &TxMsgRegistry{infoMap: make(map[string]*TxMsgInfo)}
+}
+
+func (r *TxMsgRegistry) Register(info TxMsgInfo) error {
+	if info.Msg == nil || info.Type == "" || info.CodecName == "" {
+		return errors.New("incomplete tx msg info")
+	}
+
+	if info.Msg.Type() != info.Type {
+		return fmt.Errorf("mismatch tx msg type: info type=%s, msg type=%s", info.Type, info.Msg.Type())
+	}
+
+	r.registryLocker.Lock()
+	defer r.registryLocker.Unlock()
+
+	if _, ok := r.infoMap[info.Type]; ok {
+		return fmt.Errorf("tx msg type %s registered already", info.Type)
+	}
+
+	for _, infoR := range r.infos {
+		if infoR.CodecName == info.CodecName {
+			return fmt.Errorf("tx msg codec name %s registered already", info.CodecName)
+		}
+	}
+
+	r.infoMap[info.Type] = &info
+	r.infos = append(r.infos, &info)
+
+	return nil
+}
+
+// Info returns a copy of the tx msg info, so the registered one is fixed once it is registered
+func (r *TxMsgRegistry) Info(msgType string) (*TxMsgInfo, bool) {
+	r.registryLocker.RLock()
+	defer r.registryLocker.RUnlock()
+
+	info, ok := r.infoMap[msgType]
+	if !ok {
+		return nil, false
+	}
+
+	infoC := *info
+
+	return &infoC, true
+}
+
+// Infos returns the copies of the tx msg infos in the registering order
+func (r *TxMsgRegistry) Infos() []*TxMsgInfo {
+	r.registryLocker.RLock()
+	defer r.registryLocker.RUnlock()
+
+	infos := make([]*TxMsgInfo, len(r.infos))
+	for i, info := range r.infos {
+		infoC := *info
+		infos[i] = &infoC
+	}
+
+	return infos
+}
@@ -6,3 +6,8 @@ type TxSerializer interface {
 	DeserializeCDCV1(txBytes []byte) (*TxMsg, error)
 	DeserializeCDCV0(txBytes []byte) (*TxMsgCDCV0, error)
 }
+
+// TxSignSerializer is implemented by the serializer whose sign bytes are encoded in its own format instead of the JSON of the sign msg
+type TxSignSerializer interface {
+	SignBytes(txMsg *TxMsg) ([]byte, error)
+}
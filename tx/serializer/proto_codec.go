@@ -0,0 +1,823 @@
+package serializer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/batch"
+	"github.com/Ankr-network/ankr-chain/tx/contract"
+	"github.com/Ankr-network/ankr-chain/tx/gasprice"
+	"github.com/Ankr-network/ankr-chain/tx/metering"
+	"github.com/Ankr-network/ankr-chain/tx/multisig"
+	"github.com/Ankr-network/ankr-chain/tx/token"
+	"github.com/Ankr-network/ankr-chain/tx/upgrade"
+	"github.com/Ankr-network/ankr-chain/tx/validator"
+	tmcrypto "github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+// The encoding follows the definitions under tx/serializer/proto, see types.proto for the canonical form rules.
+// TestProtoCodecParity checks it against the protobuf runtime, a new built-in msg must be added there too.
+
+const (
+	protoWireVarint = 0
+	protoWireBytes  = 2
+)
+
+// the field numbers of TxData oneof msg
+const (
+	protoMsgTransfer        = 1
+	protoMsgValidator       = 2
+	protoMsgSetCert         = 3
+	protoMsgRemoveCert      = 4
+	protoMsgMetering        = 5
+	protoMsgContractDeploy  = 6
+	protoMsgContractInvoke  = 7
+	protoMsgMultiSigAccount = 8
+	protoMsgBatch           = 9
+	protoMsgMinGasPrice     = 10
+	protoMsgFeeCurrency     = 11
+	protoMsgUpgrade         = 12
+)
+
+const (
+	protoTxFieldSigns = 15
+)
+
+type protoWriter struct {
+	buf bytes.Buffer
+}
+
+func (pw *protoWriter) writeUvarint(v uint64) {
+	var varintBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(varintBuf[:], v)
+	pw.buf.Write(varintBuf[:n])
+}
+
+func (pw *protoWriter) writeTag(field int, wireType int) {
+	pw.writeUvarint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (pw *protoWriter) uvarintField(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+
+	pw.writeTag(field, protoWireVarint)
+	pw.writeUvarint(v)
+}
+
+func (pw *protoWriter) boolField(field int, v bool) {
+	if v {
+		pw.uvarintField(field, 1)
+	}
+}
+
+func (pw *protoWriter) bytesField(field int, v []byte) {
+	if len(v) == 0 {
+		return
+	}
+
+	pw.rawBytesField(field, v)
+}
+
+func (pw *protoWriter) stringField(field int, v string) {
+	pw.bytesField(field, []byte(v))
+}
+
+// rawBytesField writes the field even if it is blank, it is used for the repeated elements and the oneof msg
+func (pw *protoWriter) rawBytesField(field int, v []byte) {
+	pw.writeTag(field, protoWireBytes)
+	pw.writeUvarint(uint64(len(v)))
+	pw.buf.Write(v)
+}
+
+func (pw *protoWriter) Bytes() []byte {
+	return pw.buf.Bytes()
+}
+
+type protoReader struct {
+	data []byte
+	pos  int
+}
+
+func newProtoReader(data []byte) *protoReader {
+	return &protoReader{data: data}
+}
+
+func (pr *protoReader) hasMore() bool {
+	return pr.pos < len(pr.data)
+}
+
+func (pr *protoReader) readUvarint() (uint64, error) {
+	v, n := binary.Uvarint(pr.data[pr.pos:])
+	if n <= 0 {
+		return 0, errors.New("invalid proto varint")
+	}
+	pr.pos += n
+
+	return v, nil
+}
+
+func (pr *protoReader) readTag() (int, int, error) {
+	tag, err := pr.readUvarint()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(tag >> 3), int(tag & 0x07), nil
+}
+
+func (pr *protoReader) readBytes() ([]byte, error) {
+	l, err := pr.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+
+	if l > uint64(len(pr.data)-pr.pos) {
+		return nil, errors.New("proto bytes field out of range")
+	}
+
+	v := pr.data[pr.pos : pr.pos+int(l)]
+	pr.pos += int(l)
+
+	return v, nil
+}
+
+// readField reads the value of the field in the expected wire type, the varint value is returned by uintV and
+// the length-delimited one by bytesV
+func (pr *protoReader) readField(wireType int, expectedWireType int) (uintV uint64, bytesV []byte, err error) {
+	if wireType != expectedWireType {
+		return 0, nil, fmt.Errorf("unexpected proto wire type %d, expected %d", wireType, expectedWireType)
+	}
+
+	if wireType == protoWireVarint {
+		uintV, err = pr.readUvarint()
+	} else {
+		bytesV, err = pr.readBytes()
+	}
+
+	return
+}
+
+// decodeProtoFields calls fieldFunc for each field in data, fieldFunc returns false if the field is unknown
+func decodeProtoFields(data []byte, fieldFunc func(field int, pr *protoReader, wireType int) (bool, error)) error {
+	pr := newProtoReader(data)
+	for pr.hasMore() {
+		field, wireType, err := pr.readTag()
+		if err != nil {
+			return err
+		}
+
+		isKnown, err := fieldFunc(field, pr, wireType)
+		if err != nil {
+			return err
+		}
+		if !isKnown {
+			return fmt.Errorf("unknown proto field %d", field)
+		}
+	}
+
+	return nil
+}
+
+func readProtoUvarint(pr *protoReader, wireType int) (uint64, error) {
+	v, _, err := pr.readField(wireType, protoWireVarint)
+	return v, err
+}
+
+func readProtoBytes(pr *protoReader, wireType int) ([]byte, error) {
+	_, v, err := pr.readField(wireType, protoWireBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(nil), v...), nil
+}
+
+func readProtoString(pr *protoReader, wireType int) (string, error) {
+	_, v, err := pr.readField(wireType, protoWireBytes)
+	return string(v), err
+}
+
+func encodeProtoAmount(amount *ankrcmm.Amount) []byte {
+	curW := &protoWriter{}
+	curW.stringField(1, amount.Cur.Symbol)
+	curW.uvarintField(2, uint64(amount.Cur.Decimal))
+
+	pw := &protoWriter{}
+	pw.bytesField(1, curW.Bytes())
+	pw.bytesField(2, amount.Value)
+
+	return pw.Bytes()
+}
+
+func decodeProtoAmount(data []byte) (ankrcmm.Amount, error) {
+	var amount ankrcmm.Amount
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			var curBytes []byte
+			curBytes, err = readProtoBytes(pr, wireType)
+			if err != nil {
+				return true, err
+			}
+			err = decodeProtoFields(curBytes, func(field int, pr *protoReader, wireType int) (bool, error) {
+				var err error
+				switch field {
+				case 1:
+					amount.Cur.Symbol, err = readProtoString(pr, wireType)
+				case 2:
+					var decimal uint64
+					decimal, err = readProtoUvarint(pr, wireType)
+					amount.Cur.Decimal = int64(decimal)
+				default:
+					return false, nil
+				}
+				return true, err
+			})
+		case 2:
+			amount.Value, err = readProtoBytes(pr, wireType)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return amount, err
+}
+
+func encodeProtoPubKey(keyType string, data []byte) []byte {
+	pw := &protoWriter{}
+	pw.stringField(1, keyType)
+	pw.bytesField(2, data)
+
+	return pw.Bytes()
+}
+
+func decodeProtoPubKey(data []byte) (string, []byte, error) {
+	var keyType string
+	var keyData []byte
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			keyType, err = readProtoString(pr, wireType)
+		case 2:
+			keyData, err = readProtoBytes(pr, wireType)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return keyType, keyData, err
+}
+
+func encodeProtoSignature(sign *ankrcrypto.Signature) ([]byte, error) {
+	pw := &protoWriter{}
+
+	switch pubKey := sign.PubKey.(type) {
+	case nil:
+	case ed25519.PubKeyEd25519:
+		pw.bytesField(1, encodeProtoPubKey(ankrcrypto.CryptoED25519, pubKey[:]))
+	case secp256k1.PubKeySecp256k1:
+		pw.bytesField(1, encodeProtoPubKey(ankrcrypto.CryptoSECP256K1, pubKey[:]))
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", sign.PubKey)
+	}
+
+	pw.bytesField(2, sign.Signed)
+	pw.stringField(3, sign.R)
+	pw.stringField(4, sign.S)
+	pw.stringField(5, sign.PubPEM)
+
+	return pw.Bytes(), nil
+}
+
+func decodeProtoSignature(data []byte) (ankrcrypto.Signature, error) {
+	var sign ankrcrypto.Signature
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			var pubKeyBytes []byte
+			pubKeyBytes, err = readProtoBytes(pr, wireType)
+			if err != nil {
+				return true, err
+			}
+			sign.PubKey, err = protoPubKeyToTM(pubKeyBytes)
+		case 2:
+			sign.Signed, err = readProtoBytes(pr, wireType)
+		case 3:
+			sign.R, err = readProtoString(pr, wireType)
+		case 4:
+			sign.S, err = readProtoString(pr, wireType)
+		case 5:
+			sign.PubPEM, err = readProtoString(pr, wireType)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return sign, err
+}
+
+func protoPubKeyToTM(data []byte) (tmcrypto.PubKey, error) {
+	keyType, keyData, err := decodeProtoPubKey(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyType {
+	case ankrcrypto.CryptoED25519:
+		var pubKey ed25519.PubKeyEd25519
+		if len(keyData) != len(pubKey) {
+			return nil, fmt.Errorf("invalid ed25519 public key len %d", len(keyData))
+		}
+		copy(pubKey[:], keyData)
+		return pubKey, nil
+	case ankrcrypto.CryptoSECP256K1:
+		var pubKey secp256k1.PubKeySecp256k1
+		if len(keyData) != len(pubKey) {
+			return nil, fmt.Errorf("invalid secp256k1 public key len %d", len(keyData))
+		}
+		copy(pubKey[:], keyData)
+		return pubKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %s", keyType)
+	}
+}
+
+// encodeProtoTxData encodes the tx msg as TxData
+func encodeProtoTxData(msg tx.ImplTxMsg) ([]byte, error) {
+	pw := &protoWriter{}
+	msgW := &protoWriter{}
+
+	switch m := msg.(type) {
+	case *token.TransferMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.ToAddr)
+		for i := range m.Amounts {
+			msgW.rawBytesField(3, encodeProtoAmount(&m.Amounts[i]))
+		}
+		pw.rawBytesField(protoMsgTransfer, msgW.Bytes())
+	case *validator.ValidatorMsg:
+		msgW.uvarintField(1, uint64(m.Action))
+		msgW.stringField(2, m.FromAddress)
+		msgW.stringField(3, m.Name)
+		msgW.bytesField(4, encodeProtoPubKey(m.PubKey.Type, m.PubKey.Data))
+		msgW.stringField(5, m.StakeAddress)
+		msgW.bytesField(6, encodeProtoAmount(&m.StakeAmount))
+		msgW.uvarintField(7, m.ValidHeight)
+		msgW.uvarintField(8, uint64(m.SetFlag))
+		pw.rawBytesField(protoMsgValidator, msgW.Bytes())
+	case *metering.SetCertMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.DCName)
+		msgW.stringField(3, m.PemBase64)
+		pw.rawBytesField(protoMsgSetCert, msgW.Bytes())
+	case *metering.RemoveCertMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.DCName)
+		msgW.stringField(3, m.NSName)
+		pw.rawBytesField(protoMsgRemoveCert, msgW.Bytes())
+	case *metering.MeteringMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.DCName)
+		msgW.stringField(3, m.NSName)
+		msgW.stringField(4, m.Value)
+		pw.rawBytesField(protoMsgMetering, msgW.Bytes())
+	case *contract.ContractDeployMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.Name)
+		msgW.bytesField(3, m.Codes)
+		msgW.stringField(4, m.CodesDesc)
+		pw.rawBytesField(protoMsgContractDeploy, msgW.Bytes())
+	case *contract.ContractInvokeMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.ContractAddr)
+		msgW.stringField(3, m.Method)
+		msgW.stringField(4, m.Args)
+		msgW.stringField(5, m.RtnType)
+		pw.rawBytesField(protoMsgContractInvoke, msgW.Bytes())
+	case *multisig.MultiSigAccountMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.MultiSigAddr)
+		msgW.uvarintField(3, uint64(m.Threshold))
+		for _, pubKey := range m.PubKeys {
+			msgW.rawBytesField(4, []byte(pubKey))
+		}
+		pw.rawBytesField(protoMsgMultiSigAccount, msgW.Bytes())
+	case *batch.BatchMsg:
+		msgW.stringField(1, m.FromAddr)
+		for _, subMsg := range m.Msgs {
+			subBytes, err := encodeProtoTxData(subMsg)
+			if err != nil {
+				return nil, err
+			}
+			msgW.rawBytesField(2, subBytes)
+		}
+		pw.rawBytesField(protoMsgBatch, msgW.Bytes())
+	case *gasprice.MinGasPriceMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.bytesField(2, encodeProtoAmount(&m.MinGasPrice))
+		pw.rawBytesField(protoMsgMinGasPrice, msgW.Bytes())
+	case *gasprice.FeeCurrencyMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.Symbol)
+		msgW.stringField(3, m.ExchangeRate)
+		msgW.boolField(4, m.Remove)
+		pw.rawBytesField(protoMsgFeeCurrency, msgW.Bytes())
+	case *upgrade.UpgradeMsg:
+		msgW.stringField(1, m.FromAddr)
+		msgW.stringField(2, m.Name)
+		msgW.uvarintField(3, uint64(m.Height))
+		msgW.stringField(4, m.Info)
+		pw.rawBytesField(protoMsgUpgrade, msgW.Bytes())
+	default:
+		return nil, fmt.Errorf("unsupported tx msg type %T for proto encoding", msg)
+	}
+
+	return pw.Bytes(), nil
+}
+
+func decodeProtoTxData(data []byte) (tx.ImplTxMsg, error) {
+	var msg tx.ImplTxMsg
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		if msg != nil {
+			return true, errors.New("more than one msg in proto tx data")
+		}
+
+		msgBytes, err := readProtoBytes(pr, wireType)
+		if err != nil {
+			return true, err
+		}
+
+		switch field {
+		case protoMsgTransfer:
+			msg, err = decodeProtoTransferMsg(msgBytes)
+		case protoMsgValidator:
+			msg, err = decodeProtoValidatorMsg(msgBytes)
+		case protoMsgSetCert:
+			m := new(metering.SetCertMsg)
+			msg, err = m, decodeProtoStrings(msgBytes, &m.FromAddr, &m.DCName, &m.PemBase64)
+		case protoMsgRemoveCert:
+			m := new(metering.RemoveCertMsg)
+			msg, err = m, decodeProtoStrings(msgBytes, &m.FromAddr, &m.DCName, &m.NSName)
+		case protoMsgMetering:
+			m := new(metering.MeteringMsg)
+			msg, err = m, decodeProtoStrings(msgBytes, &m.FromAddr, &m.DCName, &m.NSName, &m.Value)
+		case protoMsgContractDeploy:
+			msg, err = decodeProtoContractDeployMsg(msgBytes)
+		case protoMsgContractInvoke:
+			m := new(contract.ContractInvokeMsg)
+			msg, err = m, decodeProtoStrings(msgBytes, &m.FromAddr, &m.ContractAddr, &m.Method, &m.Args, &m.RtnType)
+		case protoMsgMultiSigAccount:
+			msg, err = decodeProtoMultiSigAccountMsg(msgBytes)
+		case protoMsgBatch:
+			msg, err = decodeProtoBatchMsg(msgBytes)
+		case protoMsgMinGasPrice:
+			msg, err = decodeProtoMinGasPriceMsg(msgBytes)
+		case protoMsgFeeCurrency:
+			msg, err = decodeProtoFeeCurrencyMsg(msgBytes)
+		case protoMsgUpgrade:
+			msg, err = decodeProtoUpgradeMsg(msgBytes)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if msg == nil {
+		return nil, errors.New("blank proto tx data")
+	}
+
+	return msg, nil
+}
+
+// decodeProtoStrings decodes the msg whose fields are all strings numbered from 1 in order
+func decodeProtoStrings(data []byte, fields ...*string) error {
+	return decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		if field < 1 || field > len(fields) {
+			return false, nil
+		}
+
+		var err error
+		*fields[field-1], err = readProtoString(pr, wireType)
+		return true, err
+	})
+}
+
+func decodeProtoTransferMsg(data []byte) (*token.TransferMsg, error) {
+	m := new(token.TransferMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			m.FromAddr, err = readProtoString(pr, wireType)
+		case 2:
+			m.ToAddr, err = readProtoString(pr, wireType)
+		case 3:
+			var amountBytes []byte
+			amountBytes, err = readProtoBytes(pr, wireType)
+			if err != nil {
+				return true, err
+			}
+			var amount ankrcmm.Amount
+			amount, err = decodeProtoAmount(amountBytes)
+			m.Amounts = append(m.Amounts, amount)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return m, err
+}
+
+func decodeProtoValidatorMsg(data []byte) (*validator.ValidatorMsg, error) {
+	m := new(validator.ValidatorMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		var v uint64
+		var b []byte
+		switch field {
+		case 1:
+			v, err = readProtoUvarint(pr, wireType)
+			m.Action = uint8(v)
+		case 2:
+			m.FromAddress, err = readProtoString(pr, wireType)
+		case 3:
+			m.Name, err = readProtoString(pr, wireType)
+		case 4:
+			b, err = readProtoBytes(pr, wireType)
+			if err == nil {
+				m.PubKey.Type, m.PubKey.Data, err = decodeProtoPubKey(b)
+			}
+		case 5:
+			m.StakeAddress, err = readProtoString(pr, wireType)
+		case 6:
+			b, err = readProtoBytes(pr, wireType)
+			if err == nil {
+				m.StakeAmount, err = decodeProtoAmount(b)
+			}
+		case 7:
+			m.ValidHeight, err = readProtoUvarint(pr, wireType)
+		case 8:
+			v, err = readProtoUvarint(pr, wireType)
+			m.SetFlag = ankrcmm.ValidatorInfoSetFlag(v)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return m, err
+}
+
+func decodeProtoContractDeployMsg(data []byte) (*contract.ContractDeployMsg, error) {
+	m := new(contract.ContractDeployMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			m.FromAddr, err = readProtoString(pr, wireType)
+		case 2:
+			m.Name, err = readProtoString(pr, wireType)
+		case 3:
+			m.Codes, err = readProtoBytes(pr, wireType)
+		case 4:
+			m.CodesDesc, err = readProtoString(pr, wireType)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return m, err
+}
+
+func decodeProtoMultiSigAccountMsg(data []byte) (*multisig.MultiSigAccountMsg, error) {
+	m := new(multisig.MultiSigAccountMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			m.FromAddr, err = readProtoString(pr, wireType)
+		case 2:
+			m.MultiSigAddr, err = readProtoString(pr, wireType)
+		case 3:
+			var v uint64
+			v, err = readProtoUvarint(pr, wireType)
+			m.Threshold = uint32(v)
+		case 4:
+			var pubKey string
+			pubKey, err = readProtoString(pr, wireType)
+			m.PubKeys = append(m.PubKeys, pubKey)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return m, err
+}
+
+func decodeProtoBatchMsg(data []byte) (*batch.BatchMsg, error) {
+	m := new(batch.BatchMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			m.FromAddr, err = readProtoString(pr, wireType)
+		case 2:
+			var subBytes []byte
+			subBytes, err = readProtoBytes(pr, wireType)
+			if err != nil {
+				return true, err
+			}
+			var subMsg tx.ImplTxMsg
+			subMsg, err = decodeProtoTxData(subBytes)
+			m.Msgs = append(m.Msgs, subMsg)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return m, err
+}
+
+func decodeProtoMinGasPriceMsg(data []byte) (*gasprice.MinGasPriceMsg, error) {
+	m := new(gasprice.MinGasPriceMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			m.FromAddr, err = readProtoString(pr, wireType)
+		case 2:
+			var b []byte
+			b, err = readProtoBytes(pr, wireType)
+			if err == nil {
+				m.MinGasPrice, err = decodeProtoAmount(b)
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return m, err
+}
+
+func decodeProtoFeeCurrencyMsg(data []byte) (*gasprice.FeeCurrencyMsg, error) {
+	m := new(gasprice.FeeCurrencyMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			m.FromAddr, err = readProtoString(pr, wireType)
+		case 2:
+			m.Symbol, err = readProtoString(pr, wireType)
+		case 3:
+			m.ExchangeRate, err = readProtoString(pr, wireType)
+		case 4:
+			var v uint64
+			v, err = readProtoUvarint(pr, wireType)
+			m.Remove = v != 0
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return m, err
+}
+
+func decodeProtoUpgradeMsg(data []byte) (*upgrade.UpgradeMsg, error) {
+	m := new(upgrade.UpgradeMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		switch field {
+		case 1:
+			m.FromAddr, err = readProtoString(pr, wireType)
+		case 2:
+			m.Name, err = readProtoString(pr, wireType)
+		case 3:
+			var v uint64
+			v, err = readProtoUvarint(pr, wireType)
+			m.Height = int64(v)
+		case 4:
+			m.Info, err = readProtoString(pr, wireType)
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+
+	return m, err
+}
+
+// encodeProtoTxMsg encodes the tx as TxMsg, the signs are skipped if withSigns is false
+func encodeProtoTxMsg(txMsg *tx.TxMsg, withSigns bool) ([]byte, error) {
+	if txMsg.ImplTxMsg == nil {
+		return nil, errors.New("blank tx msg data")
+	}
+
+	dataBytes, err := encodeProtoTxData(txMsg.ImplTxMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	pw := &protoWriter{}
+	pw.stringField(1, string(txMsg.ChID))
+	pw.uvarintField(2, txMsg.Nonce)
+	pw.bytesField(3, txMsg.GasLimit)
+	pw.bytesField(4, encodeProtoAmount(&txMsg.GasPrice))
+	pw.stringField(5, txMsg.Memo)
+	pw.stringField(6, txMsg.Version)
+	pw.rawBytesField(7, dataBytes)
+	pw.stringField(8, txMsg.FeePayer)
+	pw.uvarintField(9, uint64(txMsg.TimeoutHeight))
+
+	if withSigns {
+		for i := range txMsg.Signs {
+			signBytes, err := encodeProtoSignature(&txMsg.Signs[i])
+			if err != nil {
+				return nil, err
+			}
+			pw.rawBytesField(protoTxFieldSigns, signBytes)
+		}
+	}
+
+	return pw.Bytes(), nil
+}
+
+func decodeProtoTxMsg(data []byte) (*tx.TxMsg, error) {
+	txMsg := new(tx.TxMsg)
+	err := decodeProtoFields(data, func(field int, pr *protoReader, wireType int) (bool, error) {
+		var err error
+		var v uint64
+		var b []byte
+		switch field {
+		case 1:
+			var chainID string
+			chainID, err = readProtoString(pr, wireType)
+			txMsg.ChID = ankrcmm.ChainID(chainID)
+		case 2:
+			txMsg.Nonce, err = readProtoUvarint(pr, wireType)
+		case 3:
+			txMsg.GasLimit, err = readProtoBytes(pr, wireType)
+		case 4:
+			b, err = readProtoBytes(pr, wireType)
+			if err == nil {
+				txMsg.GasPrice, err = decodeProtoAmount(b)
+			}
+		case 5:
+			txMsg.Memo, err = readProtoString(pr, wireType)
+		case 6:
+			txMsg.Version, err = readProtoString(pr, wireType)
+		case 7:
+			b, err = readProtoBytes(pr, wireType)
+			if err == nil {
+				txMsg.ImplTxMsg, err = decodeProtoTxData(b)
+			}
+		case 8:
+			txMsg.FeePayer, err = readProtoString(pr, wireType)
+		case 9:
+			v, err = readProtoUvarint(pr, wireType)
+			txMsg.TimeoutHeight = int64(v)
+		case protoTxFieldSigns:
+			b, err = readProtoBytes(pr, wireType)
+			if err == nil {
+				var sign ankrcrypto.Signature
+				sign, err = decodeProtoSignature(b)
+				txMsg.Signs = append(txMsg.Signs, sign)
+			}
+		default:
+			return false, nil
+		}
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if txMsg.ImplTxMsg == nil {
+		return nil, errors.New("no tx msg data")
+	}
+
+	return txMsg, nil
+}
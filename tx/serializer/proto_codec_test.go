@@ -0,0 +1,223 @@
+package serializer
+
+import (
+	"math/big"
+	"testing"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/batch"
+	"github.com/Ankr-network/ankr-chain/tx/contract"
+	"github.com/Ankr-network/ankr-chain/tx/gasprice"
+	"github.com/Ankr-network/ankr-chain/tx/metering"
+	"github.com/Ankr-network/ankr-chain/tx/multisig"
+	"github.com/Ankr-network/ankr-chain/tx/token"
+	"github.com/Ankr-network/ankr-chain/tx/upgrade"
+	"github.com/Ankr-network/ankr-chain/tx/validator"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+)
+
+func refCurrencyOf(cur ankrcmm.Currency) *refCurrency {
+	if cur.Symbol == "" && cur.Decimal == 0 {
+		return nil
+	}
+
+	return &refCurrency{Symbol: cur.Symbol, Decimal: cur.Decimal}
+}
+
+// refAmountOf returns nil for the blank amount, which is omitted as a singular field in the canonical form
+func refAmountOf(amount ankrcmm.Amount) *refAmount {
+	ref := &refAmount{Currency: refCurrencyOf(amount.Cur), Value: amount.Value}
+	if ref.Currency == nil && len(ref.Value) == 0 {
+		return nil
+	}
+
+	return ref
+}
+
+func refPubKeyOf(keyType string, data []byte) *refPubKey {
+	if keyType == "" && len(data) == 0 {
+		return nil
+	}
+
+	return &refPubKey{Type: keyType, Data: data}
+}
+
+func refTxDataOf(t *testing.T, msg tx.ImplTxMsg) *refTxData {
+	switch m := msg.(type) {
+	case *token.TransferMsg:
+		ref := &refTransferMsg{FromAddr: m.FromAddr, ToAddr: m.ToAddr}
+		for _, amount := range m.Amounts {
+			// the repeated element is written even if it is blank
+			amountRef := refAmountOf(amount)
+			if amountRef == nil {
+				amountRef = new(refAmount)
+			}
+			ref.Amounts = append(ref.Amounts, amountRef)
+		}
+		return &refTxData{Msg: &refTxDataTransfer{ref}}
+	case *validator.ValidatorMsg:
+		return &refTxData{Msg: &refTxDataValidator{&refValidatorMsg{
+			Action:       uint32(m.Action),
+			FromAddress:  m.FromAddress,
+			Name:         m.Name,
+			PubKey:       refPubKeyOf(m.PubKey.Type, m.PubKey.Data),
+			StakeAddress: m.StakeAddress,
+			StakeAmount:  refAmountOf(m.StakeAmount),
+			ValidHeight:  m.ValidHeight,
+			SetFlag:      uint32(m.SetFlag),
+		}}}
+	case *metering.SetCertMsg:
+		return &refTxData{Msg: &refTxDataSetCert{&refSetCertMsg{FromAddr: m.FromAddr, DcName: m.DCName, PemBase64: m.PemBase64}}}
+	case *metering.RemoveCertMsg:
+		return &refTxData{Msg: &refTxDataRemoveCert{&refRemoveCertMsg{FromAddr: m.FromAddr, DcName: m.DCName, NsName: m.NSName}}}
+	case *metering.MeteringMsg:
+		return &refTxData{Msg: &refTxDataMetering{&refMeteringMsg{FromAddr: m.FromAddr, DcName: m.DCName, NsName: m.NSName, Value: m.Value}}}
+	case *contract.ContractDeployMsg:
+		return &refTxData{Msg: &refTxDataContractDeploy{&refContractDeployMsg{FromAddr: m.FromAddr, Name: m.Name, Codes: m.Codes, CodesDesc: m.CodesDesc}}}
+	case *contract.ContractInvokeMsg:
+		return &refTxData{Msg: &refTxDataContractInvoke{&refContractInvokeMsg{FromAddr: m.FromAddr, ContractAddr: m.ContractAddr, Method: m.Method, Args: m.Args, RtnType: m.RtnType}}}
+	case *multisig.MultiSigAccountMsg:
+		return &refTxData{Msg: &refTxDataMultisigAccount{&refMultiSigAccountMsg{FromAddr: m.FromAddr, MultisigAddr: m.MultiSigAddr, Threshold: m.Threshold, PubKeys: m.PubKeys}}}
+	case *batch.BatchMsg:
+		ref := &refBatchMsg{FromAddr: m.FromAddr}
+		for _, subMsg := range m.Msgs {
+			ref.Msgs = append(ref.Msgs, refTxDataOf(t, subMsg))
+		}
+		return &refTxData{Msg: &refTxDataBatch{ref}}
+	case *gasprice.MinGasPriceMsg:
+		return &refTxData{Msg: &refTxDataMinGasPrice{&refMinGasPriceMsg{FromAddr: m.FromAddr, MinGasPrice: refAmountOf(m.MinGasPrice)}}}
+	case *gasprice.FeeCurrencyMsg:
+		return &refTxData{Msg: &refTxDataFeeCurrency{&refFeeCurrencyMsg{FromAddr: m.FromAddr, Symbol: m.Symbol, ExchangeRate: m.ExchangeRate, Remove: m.Remove}}}
+	case *upgrade.UpgradeMsg:
+		return &refTxData{Msg: &refTxDataUpgrade{&refUpgradeMsg{FromAddr: m.FromAddr, Name: m.Name, Height: m.Height, Info: m.Info}}}
+	default:
+		t.Fatalf("no reference type of the tx msg %T", msg)
+		return nil
+	}
+}
+
+func refTxMsgOf(t *testing.T, txMsg *tx.TxMsg) *refTxMsg {
+	ref := &refTxMsg{
+		ChainId:       string(txMsg.ChID),
+		Nonce:         txMsg.Nonce,
+		GasLimit:      txMsg.GasLimit,
+		GasPrice:      refAmountOf(txMsg.GasPrice),
+		Memo:          txMsg.Memo,
+		Version:       txMsg.Version,
+		Data:          refTxDataOf(t, txMsg.ImplTxMsg),
+		FeePayer:      txMsg.FeePayer,
+		TimeoutHeight: txMsg.TimeoutHeight,
+	}
+
+	for _, sign := range txMsg.Signs {
+		refSign := &refSignature{Signed: sign.Signed, R: sign.R, S: sign.S, PubPem: sign.PubPEM}
+		switch pubKey := sign.PubKey.(type) {
+		case ed25519.PubKeyEd25519:
+			refSign.PubKey = refPubKeyOf(ankrcrypto.CryptoED25519, pubKey[:])
+		case secp256k1.PubKeySecp256k1:
+			refSign.PubKey = refPubKeyOf(ankrcrypto.CryptoSECP256K1, pubKey[:])
+		}
+		ref.Signs = append(ref.Signs, refSign)
+	}
+
+	return ref
+}
+
+// protoSampleMsgs returns the msgs with every field set, keyed by the msg type
+func protoSampleMsgs() map[string]tx.ImplTxMsg {
+	ankr := ankrcmm.Currency{Symbol: "ANKR", Decimal: 18}
+	amount := ankrcmm.Amount{Cur: ankr, Value: new(big.Int).SetUint64(6000000000000000000).Bytes()}
+	fromAddr := "B508ED0D54597D516A680E7951F18CAD24C7EC9FCFCD67"
+
+	transferMsg := &token.TransferMsg{FromAddr: fromAddr, ToAddr: "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB",
+		Amounts: []ankrcmm.Amount{amount, {Cur: ankrcmm.Currency{Symbol: "USDX", Decimal: 6}, Value: []byte{0x01}}, {}}}
+	upgradeMsg := &upgrade.UpgradeMsg{FromAddr: fromAddr, Name: "v2", Height: 1 << 40, Info: "upgrade info"}
+
+	msgs := []tx.ImplTxMsg{
+		transferMsg,
+		&validator.ValidatorMsg{Action: 2, FromAddress: fromAddr, Name: "val1", PubKey: ankrcmm.ValPubKey{Type: "tendermint/PubKeyEd25519", Data: []byte{0x01, 0x02, 0x03}},
+			StakeAddress: fromAddr, StakeAmount: amount, ValidHeight: 300, SetFlag: ankrcmm.ValidatorInfoSetName | ankrcmm.ValidatorInfoSetStakeAmount},
+		&metering.SetCertMsg{FromAddr: fromAddr, DCName: "dc1", PemBase64: "cGVt"},
+		&metering.RemoveCertMsg{FromAddr: fromAddr, DCName: "dc1", NSName: "ns1"},
+		&metering.MeteringMsg{FromAddr: fromAddr, DCName: "dc1", NSName: "ns1", Value: "metering value"},
+		&contract.ContractDeployMsg{FromAddr: fromAddr, Name: "contract1", Codes: []byte{0x00, 0x61, 0x73, 0x6d}, CodesDesc: "codes desc"},
+		&contract.ContractInvokeMsg{FromAddr: fromAddr, ContractAddr: "CA1", Method: "transfer", Args: "[]", RtnType: "bool"},
+		&multisig.MultiSigAccountMsg{FromAddr: fromAddr, MultiSigAddr: "MA1", Threshold: 2, PubKeys: []string{"key1", "", "key3"}},
+		&batch.BatchMsg{FromAddr: fromAddr, Msgs: []tx.ImplTxMsg{transferMsg, upgradeMsg, &metering.SetCertMsg{}}},
+		&gasprice.MinGasPriceMsg{FromAddr: fromAddr, MinGasPrice: amount},
+		&gasprice.FeeCurrencyMsg{FromAddr: fromAddr, Symbol: "USDX", ExchangeRate: "1/3", Remove: true},
+		upgradeMsg,
+	}
+
+	msgMap := make(map[string]tx.ImplTxMsg)
+	for _, msg := range msgs {
+		msgMap[msg.Type()] = msg
+	}
+
+	return msgMap
+}
+
+func protoSampleTx(msg tx.ImplTxMsg) *tx.TxMsg {
+	edPubKey := ed25519.GenPrivKey().PubKey()
+	secpPubKey := secp256k1.GenPrivKey().PubKey()
+
+	return &tx.TxMsg{
+		ChID:          "ankr-chain",
+		Nonce:         1 << 40,
+		GasLimit:      new(big.Int).SetUint64(1000000).Bytes(),
+		GasPrice:      ankrcmm.Amount{Cur: ankrcmm.Currency{Symbol: "ANKR", Decimal: 18}, Value: new(big.Int).SetUint64(10000000000000).Bytes()},
+		Memo:          "proto parity",
+		Version:       "1.0.2",
+		ImplTxMsg:     msg,
+		FeePayer:      "454D92DC842F532683E820DF6C3784473AD9CCF222D8FB",
+		TimeoutHeight: 300,
+		Signs: []ankrcrypto.Signature{
+			{PubKey: edPubKey, Signed: []byte{0x01, 0x02}, R: "r", S: "s", PubPEM: "pem"},
+			{PubKey: secpPubKey, Signed: []byte{0x03}},
+			{Signed: []byte{0x04}},
+		},
+	}
+}
+
+// TestProtoCodecParity checks the hand-written codec against the protobuf runtime for every built-in msg: the tx
+// encoded by the codec is the same as the one marshaled from the reference types, and both decode to the same tx.
+func TestProtoCodecParity(t *testing.T) {
+	txSerializer := NewTxSerializerProto()
+	sampleMsgs := protoSampleMsgs()
+
+	var txMsgs []*tx.TxMsg
+	for _, info := range NewBuiltinTxMsgRegistry().Infos() {
+		sampleMsg, ok := sampleMsgs[info.Type]
+		if !assert.Equal(t, true, ok, "no sample of the tx msg %s", info.Type) {
+			continue
+		}
+
+		txMsgs = append(txMsgs, &tx.TxMsg{ImplTxMsg: info.Msg}, protoSampleTx(info.Msg), &tx.TxMsg{ImplTxMsg: sampleMsg}, protoSampleTx(sampleMsg))
+	}
+
+	// the negative int64 is encoded as the 10 bytes varint rather than zigzag
+	txMsgs = append(txMsgs, &tx.TxMsg{TimeoutHeight: -1, ImplTxMsg: &upgrade.UpgradeMsg{Height: -1}})
+
+	for _, txMsg := range txMsgs {
+		refTx := refTxMsgOf(t, txMsg)
+
+		txBytes, err := txSerializer.Serialize(txMsg)
+		assert.Equal(t, nil, err)
+		refBytes, err := proto.Marshal(refTx)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, append([]byte{ProtoTxVersion1}, refBytes...), txBytes, "%T", txMsg.ImplTxMsg)
+
+		var decodedRefTx refTxMsg
+		assert.Equal(t, nil, proto.Unmarshal(txBytes[1:], &decodedRefTx))
+		assert.Equal(t, true, proto.Equal(refTx, &decodedRefTx), "%T", txMsg.ImplTxMsg)
+
+		decodedTx, err := txSerializer.DeserializeProto(append([]byte{ProtoTxVersion1}, refBytes...))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, txMsg, decodedTx)
+	}
+}
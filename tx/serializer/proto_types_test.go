@@ -0,0 +1,286 @@
+package serializer
+
+import (
+	"github.com/golang/protobuf/proto"
+)
+
+// The reference types below are written in the form protoc-gen-go v1.3 generates from the definitions under
+// tx/serializer/proto, they are marshaled by the protobuf runtime to check the hand-written codec against.
+// Keep them in step with the .proto files.
+
+type refCurrency struct {
+	Symbol  string `protobuf:"bytes,1,opt,name=symbol,proto3"`
+	Decimal int64  `protobuf:"varint,2,opt,name=decimal,proto3"`
+}
+
+func (m *refCurrency) Reset()         { *m = refCurrency{} }
+func (m *refCurrency) String() string { return proto.CompactTextString(m) }
+func (*refCurrency) ProtoMessage()    {}
+
+type refAmount struct {
+	Currency *refCurrency `protobuf:"bytes,1,opt,name=currency,proto3"`
+	Value    []byte       `protobuf:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *refAmount) Reset()         { *m = refAmount{} }
+func (m *refAmount) String() string { return proto.CompactTextString(m) }
+func (*refAmount) ProtoMessage()    {}
+
+type refPubKey struct {
+	Type string `protobuf:"bytes,1,opt,name=type,proto3"`
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3"`
+}
+
+func (m *refPubKey) Reset()         { *m = refPubKey{} }
+func (m *refPubKey) String() string { return proto.CompactTextString(m) }
+func (*refPubKey) ProtoMessage()    {}
+
+type refSignature struct {
+	PubKey *refPubKey `protobuf:"bytes,1,opt,name=pub_key,json=pubKey,proto3"`
+	Signed []byte     `protobuf:"bytes,2,opt,name=signed,proto3"`
+	R      string     `protobuf:"bytes,3,opt,name=r,proto3"`
+	S      string     `protobuf:"bytes,4,opt,name=s,proto3"`
+	PubPem string     `protobuf:"bytes,5,opt,name=pub_pem,json=pubPem,proto3"`
+}
+
+func (m *refSignature) Reset()         { *m = refSignature{} }
+func (m *refSignature) String() string { return proto.CompactTextString(m) }
+func (*refSignature) ProtoMessage()    {}
+
+type refTransferMsg struct {
+	FromAddr string       `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	ToAddr   string       `protobuf:"bytes,2,opt,name=to_addr,json=toAddr,proto3"`
+	Amounts  []*refAmount `protobuf:"bytes,3,rep,name=amounts,proto3"`
+}
+
+func (m *refTransferMsg) Reset()         { *m = refTransferMsg{} }
+func (m *refTransferMsg) String() string { return proto.CompactTextString(m) }
+func (*refTransferMsg) ProtoMessage()    {}
+
+type refValidatorMsg struct {
+	Action       uint32     `protobuf:"varint,1,opt,name=action,proto3"`
+	FromAddress  string     `protobuf:"bytes,2,opt,name=from_address,json=fromAddress,proto3"`
+	Name         string     `protobuf:"bytes,3,opt,name=name,proto3"`
+	PubKey       *refPubKey `protobuf:"bytes,4,opt,name=pub_key,json=pubKey,proto3"`
+	StakeAddress string     `protobuf:"bytes,5,opt,name=stake_address,json=stakeAddress,proto3"`
+	StakeAmount  *refAmount `protobuf:"bytes,6,opt,name=stake_amount,json=stakeAmount,proto3"`
+	ValidHeight  uint64     `protobuf:"varint,7,opt,name=valid_height,json=validHeight,proto3"`
+	SetFlag      uint32     `protobuf:"varint,8,opt,name=set_flag,json=setFlag,proto3"`
+}
+
+func (m *refValidatorMsg) Reset()         { *m = refValidatorMsg{} }
+func (m *refValidatorMsg) String() string { return proto.CompactTextString(m) }
+func (*refValidatorMsg) ProtoMessage()    {}
+
+type refSetCertMsg struct {
+	FromAddr  string `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	DcName    string `protobuf:"bytes,2,opt,name=dc_name,json=dcName,proto3"`
+	PemBase64 string `protobuf:"bytes,3,opt,name=pem_base64,json=pemBase64,proto3"`
+}
+
+func (m *refSetCertMsg) Reset()         { *m = refSetCertMsg{} }
+func (m *refSetCertMsg) String() string { return proto.CompactTextString(m) }
+func (*refSetCertMsg) ProtoMessage()    {}
+
+type refRemoveCertMsg struct {
+	FromAddr string `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	DcName   string `protobuf:"bytes,2,opt,name=dc_name,json=dcName,proto3"`
+	NsName   string `protobuf:"bytes,3,opt,name=ns_name,json=nsName,proto3"`
+}
+
+func (m *refRemoveCertMsg) Reset()         { *m = refRemoveCertMsg{} }
+func (m *refRemoveCertMsg) String() string { return proto.CompactTextString(m) }
+func (*refRemoveCertMsg) ProtoMessage()    {}
+
+type refMeteringMsg struct {
+	FromAddr string `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	DcName   string `protobuf:"bytes,2,opt,name=dc_name,json=dcName,proto3"`
+	NsName   string `protobuf:"bytes,3,opt,name=ns_name,json=nsName,proto3"`
+	Value    string `protobuf:"bytes,4,opt,name=value,proto3"`
+}
+
+func (m *refMeteringMsg) Reset()         { *m = refMeteringMsg{} }
+func (m *refMeteringMsg) String() string { return proto.CompactTextString(m) }
+func (*refMeteringMsg) ProtoMessage()    {}
+
+type refContractDeployMsg struct {
+	FromAddr  string `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	Name      string `protobuf:"bytes,2,opt,name=name,proto3"`
+	Codes     []byte `protobuf:"bytes,3,opt,name=codes,proto3"`
+	CodesDesc string `protobuf:"bytes,4,opt,name=codes_desc,json=codesDesc,proto3"`
+}
+
+func (m *refContractDeployMsg) Reset()         { *m = refContractDeployMsg{} }
+func (m *refContractDeployMsg) String() string { return proto.CompactTextString(m) }
+func (*refContractDeployMsg) ProtoMessage()    {}
+
+type refContractInvokeMsg struct {
+	FromAddr     string `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	ContractAddr string `protobuf:"bytes,2,opt,name=contract_addr,json=contractAddr,proto3"`
+	Method       string `protobuf:"bytes,3,opt,name=method,proto3"`
+	Args         string `protobuf:"bytes,4,opt,name=args,proto3"`
+	RtnType      string `protobuf:"bytes,5,opt,name=rtn_type,json=rtnType,proto3"`
+}
+
+func (m *refContractInvokeMsg) Reset()         { *m = refContractInvokeMsg{} }
+func (m *refContractInvokeMsg) String() string { return proto.CompactTextString(m) }
+func (*refContractInvokeMsg) ProtoMessage()    {}
+
+type refMultiSigAccountMsg struct {
+	FromAddr     string   `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	MultisigAddr string   `protobuf:"bytes,2,opt,name=multisig_addr,json=multisigAddr,proto3"`
+	Threshold    uint32   `protobuf:"varint,3,opt,name=threshold,proto3"`
+	PubKeys      []string `protobuf:"bytes,4,rep,name=pub_keys,json=pubKeys,proto3"`
+}
+
+func (m *refMultiSigAccountMsg) Reset()         { *m = refMultiSigAccountMsg{} }
+func (m *refMultiSigAccountMsg) String() string { return proto.CompactTextString(m) }
+func (*refMultiSigAccountMsg) ProtoMessage()    {}
+
+type refBatchMsg struct {
+	FromAddr string       `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	Msgs     []*refTxData `protobuf:"bytes,2,rep,name=msgs,proto3"`
+}
+
+func (m *refBatchMsg) Reset()         { *m = refBatchMsg{} }
+func (m *refBatchMsg) String() string { return proto.CompactTextString(m) }
+func (*refBatchMsg) ProtoMessage()    {}
+
+type refMinGasPriceMsg struct {
+	FromAddr    string     `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	MinGasPrice *refAmount `protobuf:"bytes,2,opt,name=min_gas_price,json=minGasPrice,proto3"`
+}
+
+func (m *refMinGasPriceMsg) Reset()         { *m = refMinGasPriceMsg{} }
+func (m *refMinGasPriceMsg) String() string { return proto.CompactTextString(m) }
+func (*refMinGasPriceMsg) ProtoMessage()    {}
+
+type refFeeCurrencyMsg struct {
+	FromAddr     string `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	Symbol       string `protobuf:"bytes,2,opt,name=symbol,proto3"`
+	ExchangeRate string `protobuf:"bytes,3,opt,name=exchange_rate,json=exchangeRate,proto3"`
+	Remove       bool   `protobuf:"varint,4,opt,name=remove,proto3"`
+}
+
+func (m *refFeeCurrencyMsg) Reset()         { *m = refFeeCurrencyMsg{} }
+func (m *refFeeCurrencyMsg) String() string { return proto.CompactTextString(m) }
+func (*refFeeCurrencyMsg) ProtoMessage()    {}
+
+type refUpgradeMsg struct {
+	FromAddr string `protobuf:"bytes,1,opt,name=from_addr,json=fromAddr,proto3"`
+	Name     string `protobuf:"bytes,2,opt,name=name,proto3"`
+	Height   int64  `protobuf:"varint,3,opt,name=height,proto3"`
+	Info     string `protobuf:"bytes,4,opt,name=info,proto3"`
+}
+
+func (m *refUpgradeMsg) Reset()         { *m = refUpgradeMsg{} }
+func (m *refUpgradeMsg) String() string { return proto.CompactTextString(m) }
+func (*refUpgradeMsg) ProtoMessage()    {}
+
+type refTxData struct {
+	Msg isRefTxDataMsg `protobuf_oneof:"msg"`
+}
+
+func (m *refTxData) Reset()         { *m = refTxData{} }
+func (m *refTxData) String() string { return proto.CompactTextString(m) }
+func (*refTxData) ProtoMessage()    {}
+
+type isRefTxDataMsg interface {
+	isRefTxDataMsg()
+}
+
+type refTxDataTransfer struct {
+	Transfer *refTransferMsg `protobuf:"bytes,1,opt,name=transfer,proto3,oneof"`
+}
+
+type refTxDataValidator struct {
+	Validator *refValidatorMsg `protobuf:"bytes,2,opt,name=validator,proto3,oneof"`
+}
+
+type refTxDataSetCert struct {
+	SetCert *refSetCertMsg `protobuf:"bytes,3,opt,name=set_cert,json=setCert,proto3,oneof"`
+}
+
+type refTxDataRemoveCert struct {
+	RemoveCert *refRemoveCertMsg `protobuf:"bytes,4,opt,name=remove_cert,json=removeCert,proto3,oneof"`
+}
+
+type refTxDataMetering struct {
+	Metering *refMeteringMsg `protobuf:"bytes,5,opt,name=metering,proto3,oneof"`
+}
+
+type refTxDataContractDeploy struct {
+	ContractDeploy *refContractDeployMsg `protobuf:"bytes,6,opt,name=contract_deploy,json=contractDeploy,proto3,oneof"`
+}
+
+type refTxDataContractInvoke struct {
+	ContractInvoke *refContractInvokeMsg `protobuf:"bytes,7,opt,name=contract_invoke,json=contractInvoke,proto3,oneof"`
+}
+
+type refTxDataMultisigAccount struct {
+	MultisigAccount *refMultiSigAccountMsg `protobuf:"bytes,8,opt,name=multisig_account,json=multisigAccount,proto3,oneof"`
+}
+
+type refTxDataBatch struct {
+	Batch *refBatchMsg `protobuf:"bytes,9,opt,name=batch,proto3,oneof"`
+}
+
+type refTxDataMinGasPrice struct {
+	MinGasPrice *refMinGasPriceMsg `protobuf:"bytes,10,opt,name=min_gas_price,json=minGasPrice,proto3,oneof"`
+}
+
+type refTxDataFeeCurrency struct {
+	FeeCurrency *refFeeCurrencyMsg `protobuf:"bytes,11,opt,name=fee_currency,json=feeCurrency,proto3,oneof"`
+}
+
+type refTxDataUpgrade struct {
+	Upgrade *refUpgradeMsg `protobuf:"bytes,12,opt,name=upgrade,proto3,oneof"`
+}
+
+func (*refTxDataTransfer) isRefTxDataMsg()        {}
+func (*refTxDataValidator) isRefTxDataMsg()       {}
+func (*refTxDataSetCert) isRefTxDataMsg()         {}
+func (*refTxDataRemoveCert) isRefTxDataMsg()      {}
+func (*refTxDataMetering) isRefTxDataMsg()        {}
+func (*refTxDataContractDeploy) isRefTxDataMsg()  {}
+func (*refTxDataContractInvoke) isRefTxDataMsg()  {}
+func (*refTxDataMultisigAccount) isRefTxDataMsg() {}
+func (*refTxDataBatch) isRefTxDataMsg()           {}
+func (*refTxDataMinGasPrice) isRefTxDataMsg()     {}
+func (*refTxDataFeeCurrency) isRefTxDataMsg()     {}
+func (*refTxDataUpgrade) isRefTxDataMsg()         {}
+
+// XXX_OneofWrappers is for the internal use of the proto package
+func (*refTxData) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*refTxDataTransfer)(nil),
+		(*refTxDataValidator)(nil),
+		(*refTxDataSetCert)(nil),
+		(*refTxDataRemoveCert)(nil),
+		(*refTxDataMetering)(nil),
+		(*refTxDataContractDeploy)(nil),
+		(*refTxDataContractInvoke)(nil),
+		(*refTxDataMultisigAccount)(nil),
+		(*refTxDataBatch)(nil),
+		(*refTxDataMinGasPrice)(nil),
+		(*refTxDataFeeCurrency)(nil),
+		(*refTxDataUpgrade)(nil),
+	}
+}
+
+type refTxMsg struct {
+	ChainId       string          `protobuf:"bytes,1,opt,name=chain_id,json=chainId,proto3"`
+	Nonce         uint64          `protobuf:"varint,2,opt,name=nonce,proto3"`
+	GasLimit      []byte          `protobuf:"bytes,3,opt,name=gas_limit,json=gasLimit,proto3"`
+	GasPrice      *refAmount      `protobuf:"bytes,4,opt,name=gas_price,json=gasPrice,proto3"`
+	Memo          string          `protobuf:"bytes,5,opt,name=memo,proto3"`
+	Version       string          `protobuf:"bytes,6,opt,name=version,proto3"`
+	Data          *refTxData      `protobuf:"bytes,7,opt,name=data,proto3"`
+	FeePayer      string          `protobuf:"bytes,8,opt,name=fee_payer,json=feePayer,proto3"`
+	TimeoutHeight int64           `protobuf:"varint,9,opt,name=timeout_height,json=timeoutHeight,proto3"`
+	Signs         []*refSignature `protobuf:"bytes,15,rep,name=signs,proto3"`
+}
+
+func (m *refTxMsg) Reset()         { *m = refTxMsg{} }
+func (m *refTxMsg) String() string { return proto.CompactTextString(m) }
+func (*refTxMsg) ProtoMessage()    {}
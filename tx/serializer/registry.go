@@ -0,0 +1,49 @@
+package serializer
+
+import (
+	"strings"
+
+	"github.com/Ankr-network/ankr-chain/tx"
+	"github.com/Ankr-network/ankr-chain/tx/batch"
+	"github.com/Ankr-network/ankr-chain/tx/contract"
+	"github.com/Ankr-network/ankr-chain/tx/gasprice"
+	"github.com/Ankr-network/ankr-chain/tx/metering"
+	"github.com/Ankr-network/ankr-chain/tx/multisig"
+	"github.com/Ankr-network/ankr-chain/tx/token"
+	"github.com/Ankr-network/ankr-chain/tx/upgrade"
+	"github.com/Ankr-network/ankr-chain/tx/validator"
+)
+
+// the built-in msgs charge no base gas, so their fees are the same as before
+var builtinTxMsgInfos = []tx.TxMsgInfo{
+	{Type: new(token.TransferMsg).Type(), CodecName: "ankr-chain/tx/token/tranferTxMsg", Msg: &token.TransferMsg{}},
+	{Type: new(validator.ValidatorMsg).Type(), CodecName: "ankr-chain/tx/validator/validatorMsg", Msg: &validator.ValidatorMsg{}},
+	{Type: new(metering.SetCertMsg).Type(), CodecName: "ankr-chain/tx/metering/setCertMsg", Msg: &metering.SetCertMsg{}},
+	{Type: new(metering.RemoveCertMsg).Type(), CodecName: "ankr-chain/tx/metering/removeCertMsg", Msg: &metering.RemoveCertMsg{}},
+	{Type: new(metering.MeteringMsg).Type(), CodecName: "ankr-chain/tx/metering/meteringMsg", Msg: &metering.MeteringMsg{}},
+	{Type: new(contract.ContractDeployMsg).Type(), CodecName: "ankr-chain/tx/contract/ContractDeployMsg", Msg: &contract.ContractDeployMsg{}},
+	{Type: new(contract.ContractInvokeMsg).Type(), CodecName: "ankr-chain/tx/contract/ContractInvokeMsg", Msg: &contract.ContractInvokeMsg{}},
+	{Type: new(multisig.MultiSigAccountMsg).Type(), CodecName: "ankr-chain/tx/multisig/MultiSigAccountMsg", Msg: &multisig.MultiSigAccountMsg{}},
+	{Type: new(batch.BatchMsg).Type(), CodecName: "ankr-chain/tx/batch/BatchMsg", Msg: &batch.BatchMsg{}},
+	{Type: new(gasprice.MinGasPriceMsg).Type(), CodecName: "ankr-chain/tx/gasprice/MinGasPriceMsg", Msg: &gasprice.MinGasPriceMsg{}},
+	{Type: new(gasprice.FeeCurrencyMsg).Type(), CodecName: "ankr-chain/tx/gasprice/FeeCurrencyMsg", Msg: &gasprice.FeeCurrencyMsg{}},
+	{Type: new(upgrade.UpgradeMsg).Type(), CodecName: "ankr-chain/tx/upgrade/UpgradeMsg", Msg: &upgrade.UpgradeMsg{}},
+}
+
+// NewBuiltinTxMsgRegistry returns the registry with the built-in tx msgs, more msgs can be registered into it
+// before the application is created
+func NewBuiltinTxMsgRegistry() *tx.TxMsgRegistry {
+	registry := tx.NewTxMsgRegistry()
+	for _, info := range builtinTxMsgInfos {
+		if err := registry.Register(info); err != nil {
+			panic(err)
+		}
+	}
+
+	return registry
+}
+
+// IsUnknownTxMsgErr reports whether the decoding error is caused by a tx msg not registered into the tx codec
+func IsUnknownTxMsgErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "unrecognized prefix bytes")
+}
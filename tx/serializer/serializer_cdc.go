@@ -17,6 +17,10 @@ func NewTxSerializerCDC() *TxSerializerCDC {
 	return &TxSerializerCDC {CreateTxCDC(), cdcv0.CreateTxCDC()}
 }
 
+func NewTxSerializerCDCWithRegistry(registry *tx.TxMsgRegistry) *TxSerializerCDC {
+	return &TxSerializerCDC {CreateTxCDCWithRegistry(registry), cdcv0.CreateTxCDC()}
+}
+
 func (txs *TxSerializerCDC) Serialize(txMsg *tx.TxMsg) ([]byte, error) {
 	return txs.txCDCV1.MarshalBinaryLengthPrefixed(txMsg)
 }
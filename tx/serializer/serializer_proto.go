@@ -0,0 +1,76 @@
+package serializer
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/Ankr-network/ankr-chain/tx"
+)
+
+const (
+	ProtoTxVersion1 byte = 0x01
+)
+
+// IsProtoTx reports whether the tx bytes are in the proto format. An amino length prefixed tx can't start with
+// the version byte 0x01 unless it is only 2 bytes long, which is too short to be any valid proto tx.
+func IsProtoTx(txBytes []byte) bool {
+	return len(txBytes) > 2 && txBytes[0] == ProtoTxVersion1
+}
+
+// TxSerializerProto serializes the tx into the deterministic protobuf format defined under tx/serializer/proto,
+// it still decodes the amino txs and its MarshalJSON keeps the amino JSON.
+type TxSerializerProto struct {
+	*TxSerializerCDC
+}
+
+func NewTxSerializerProto() *TxSerializerProto {
+	return &TxSerializerProto{NewTxSerializerCDC()}
+}
+
+// NewTxSerializerProtoWithRegistry creates the proto serializer whose amino codec knows every msg in the registry,
+// while only the built-in msgs have the proto encoding
+func NewTxSerializerProtoWithRegistry(registry *tx.TxMsgRegistry) *TxSerializerProto {
+	return &TxSerializerProto{NewTxSerializerCDCWithRegistry(registry)}
+}
+
+func (txs *TxSerializerProto) Serialize(txMsg *tx.TxMsg) ([]byte, error) {
+	txBytes, err := encodeProtoTxMsg(txMsg, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{ProtoTxVersion1}, txBytes...), nil
+}
+
+// SignBytes returns the version byte followed by the proto encoding of the tx without signs
+func (txs *TxSerializerProto) SignBytes(txMsg *tx.TxMsg) ([]byte, error) {
+	txBytes, err := encodeProtoTxMsg(txMsg, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{ProtoTxVersion1}, txBytes...), nil
+}
+
+// DeserializeProto decodes the proto tx, and the tx not encoded in the canonical form is rejected so the same
+// content always has the same tx hash
+func (txs *TxSerializerProto) DeserializeProto(txBytes []byte) (*tx.TxMsg, error) {
+	if !IsProtoTx(txBytes) {
+		return nil, errors.New("not proto tx")
+	}
+
+	txMsg, err := decodeProtoTxMsg(txBytes[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	canonicalBytes, err := txs.Serialize(txMsg)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(canonicalBytes, txBytes) {
+		return nil, errors.New("non-canonical proto tx encoding")
+	}
+
+	return txMsg, nil
+}
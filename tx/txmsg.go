@@ -1,7 +1,10 @@
 package tx
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"math"
 	"math/big"
 
 	"github.com/Ankr-network/ankr-chain/account"
@@ -10,6 +13,7 @@ import (
 	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
 	"github.com/Ankr-network/ankr-chain/store/appstore"
 	ankrtxcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/ankr-chain/upgrade"
 	"github.com/Ankr-network/wagon/exec/gas"
 	"github.com/tendermint/tendermint/abci/types"
 	cmn "github.com/tendermint/tendermint/libs/common"
@@ -25,6 +29,12 @@ type ImplTxMsg interface {
 	ProcessTx(context ContextTx, metric gas.GasMetric, flag TxExeFlag) (uint32, string, []cmn.KVPair)
 }
 
+// TxMsgContainer is implemented by the msgs carrying other msgs, each inner msg is permitted and charged by the registry
+// as if it was sent alone, and it should be processed by ProcessInnerMsg
+type TxMsgContainer interface {
+	InnerMsgs() []ImplTxMsg
+}
+
 type TxExeFlag uint32
 const (
 	_ TxExeFlag = iota
@@ -49,6 +59,8 @@ type TxMsg struct {
 	Memo        string                  `json:"memo"`
 	Version     string                  `json:"version"`
     ImplTxMsg                           `json:"data"`
+	FeePayer    string                  `json:"feepayer,omitempty"`
+	TimeoutHeight int64                 `json:"timeoutheight,omitempty"`
 }
 
 type txSignMsg struct {
@@ -59,6 +71,8 @@ type txSignMsg struct {
 	Memo     string            `json:"memo"`
 	Version  string            `json:"version"`
 	Data     []byte            `json:"data"`
+	FeePayer string            `json:"feepayer,omitempty"`
+	TimeoutHeight int64        `json:"timeoutheight,omitempty"`
 }
 
 func spendGasExe(gasLimit []byte, gasUsed *big.Int, gas *big.Int) bool {
@@ -107,24 +121,106 @@ func (tx *TxMsg) signMsg(txSerializer TxSerializer) *txSignMsg {
 		Memo:     tx.Memo,
 		Version:  tx.Version,
 		Data:     tx.ImplTxMsg.Bytes(txSerializer),
+		FeePayer: tx.FeePayer,
+		TimeoutHeight: tx.TimeoutHeight,
 	}
 }
 
-func (tx *TxMsg) SignAndMarshal(txSerializer TxSerializer, key ankrcrypto.SecretKey) ([]byte, error) {
-	signMsg := tx.signMsg(txSerializer)
-	if signMsg != nil {
-		signMsgBytes := signMsg.Bytes(txSerializer)
-		signature, err := key.Sign(signMsgBytes)
+// FeePayerAddr returns the address paying the tx fee, it is the sponsor if FeePayer is set or else the first signer
+func (tx *TxMsg) FeePayerAddr() string {
+	if tx.FeePayer != "" {
+		return tx.FeePayer
+	}
+
+	return tx.SignerAddr()[0]
+}
+
+func (tx *TxMsg) SignBytes(txSerializer TxSerializer) []byte {
+	if signSerializer, ok := txSerializer.(TxSignSerializer); ok {
+		signBytes, err := signSerializer.SignBytes(tx)
 		if err != nil {
 			panic(err)
 		}
 
-		tx.Signs = []ankrcrypto.Signature{*signature}
+		return signBytes
+	}
+
+	return tx.signMsg(txSerializer).Bytes(txSerializer)
+}
+
+func (tx *TxMsg) SignAndMarshal(txSerializer TxSerializer, key ankrcrypto.SecretKey) ([]byte, error) {
+	signMsgBytes := tx.SignBytes(txSerializer)
+	signature, err := key.Sign(signMsgBytes)
+	if err != nil {
+		panic(err)
+	}
+
+	tx.Signs = []ankrcrypto.Signature{*signature}
+
+	return txSerializer.Serialize(tx)
+}
+
+// AppendSign adds the key's signature to the tx without invalidating the existed ones,
+// the former signature of the same public key will be replaced.
+func (tx *TxMsg) AppendSign(txSerializer TxSerializer, key ankrcrypto.SecretKey) error {
+	signature, err := key.Sign(tx.SignBytes(txSerializer))
+	if err != nil {
+		return err
+	}
 
-		return txSerializer.Serialize(tx)
+	for i := range tx.Signs {
+		if tx.Signs[i].PubKey != nil && tx.Signs[i].PubKey.Equals(signature.PubKey) {
+			tx.Signs[i] = *signature
+			return nil
+		}
 	}
 
-	return nil, nil
+	tx.Signs = append(tx.Signs, *signature)
+
+	return nil
+}
+
+// MergeSigns collects the signatures of other which signs the same content with tx, the duplicated ones are skipped.
+func (tx *TxMsg) MergeSigns(txSerializer TxSerializer, other *TxMsg) error {
+	if !bytes.Equal(tx.SignBytes(txSerializer), other.SignBytes(txSerializer)) {
+		return errors.New("can't merge the signatures of the different tx content")
+	}
+
+	for _, otherSign := range other.Signs {
+		isExist := false
+		for _, sign := range tx.Signs {
+			if sign.PubKey != nil && otherSign.PubKey != nil && sign.PubKey.Equals(otherSign.PubKey) {
+				isExist = true
+				break
+			}
+		}
+
+		if !isExist {
+			tx.Signs = append(tx.Signs, otherSign)
+		}
+	}
+
+	return nil
+}
+
+// VerifySigns checks every attached signature against the tx content without accessing the app store.
+func (tx *TxMsg) VerifySigns(txSerializer TxSerializer) error {
+	if len(tx.Signs) == 0 {
+		return errors.New("no signature attached")
+	}
+
+	toVerifyBytes := tx.SignBytes(txSerializer)
+	for i := range tx.Signs {
+		if tx.Signs[i].PubKey == nil {
+			return fmt.Errorf("blank public key of signature %d", i)
+		}
+
+		if !tx.SecretKey().Verify(toVerifyBytes, &tx.Signs[i]) {
+			return fmt.Errorf("can't pass sign verifying: signer=%s", tx.Signs[i].PubKey.Address().String())
+		}
+	}
+
+	return nil
 }
 
 func (tx *TxMsg) SpendGas(gas *big.Int) bool {
@@ -134,37 +230,127 @@ func (tx *TxMsg) SpendGas(gas *big.Int) bool {
 	return spendGasExe(tx.GasLimit, tx.GasUsed, gas)
 }
 
-func (tx *TxMsg) verifySignature(store appstore.AppStore, txSerializer TxSerializer) (uint32, string) {
-	signMsg := tx.signMsg(txSerializer)
-	toVerifyBytes := signMsg.Bytes(txSerializer)
+func (tx *TxMsg) verifySignature(context ContextTx, info *TxMsgInfo) (uint32, string) {
+	toVerifyBytes := tx.SignBytes(context.TxSerializer())
 	for i, signerAddr := range tx.SignerAddr() {
 		if len(signerAddr) != ankrcmm.KeyAddressLen {
 			return  code.CodeTypeInvalidAddress, fmt.Sprintf("Unexpected signer address. Got %v, len=%d", signerAddr, len(signerAddr))
 		}
 
-		var pubKeyBytes []byte
-		if tx.Signs[i].PubKey != nil {
-			pubKeyBytes = tx.Signs[i].PubKey.Bytes()
+		var sign *ankrcrypto.Signature
+		if i < len(tx.Signs) {
+			sign = &tx.Signs[i]
 		}
 
-		if !tx.PermitKey(store, pubKeyBytes) {
+		codeV, log := tx.verifySigner(context, signerAddr, sign, toVerifyBytes, info)
+		if codeV != code.CodeTypeOK {
+			return codeV, log
+		}
+	}
+
+	if tx.FeePayer != "" {
+		return tx.verifyFeePayerSignature(context, toVerifyBytes, info)
+	}
+
+	return code.CodeTypeOK, ""
+}
+
+// verifySigner verifies the signer by the signatures of its members if it is a multisig account, otherwise by the
+// signature given, whose key must be permitted too
+func (tx *TxMsg) verifySigner(context ContextTx, signerAddr string, sign *ankrcrypto.Signature, toVerifyBytes []byte, info *TxMsgInfo) (uint32, string) {
+	msInfo, _, _, _, err := context.AppStore().MultiSigAccount(signerAddr, 0, false)
+	if err == nil && msInfo != nil {
+		return tx.verifyMultiSignature(context, msInfo, toVerifyBytes, info)
+	}
+
+	if sign == nil {
+		return code.CodeTypeVerifySignaError, fmt.Sprintf("can't find the signature for signer: signerAddr=%s", signerAddr)
+	}
+
+	var pubKeyBytes []byte
+	if sign.PubKey != nil {
+		pubKeyBytes = sign.PubKey.Bytes()
+	}
+
+	if !tx.permitKey(context, info, pubKeyBytes) {
+		return code.CodeTypeNotPermitPubKey, fmt.Sprintf("not permit public key: %v", pubKeyBytes)
+	}
+
+	isOk := tx.SecretKey().Verify(toVerifyBytes, sign)
+	if !isOk {
+		return code.CodeTypeVerifySignaError, fmt.Sprintf("can't pass sign verifying for signer: pubKey=%v", pubKeyBytes)
+	}
+
+	return code.CodeTypeOK, ""
+}
+
+// verifyFeePayerSignature requires the sponsor paying the fee signs the same content with the signers, it is verified
+// like a signer whose signature is the one of its key, so the multisig account can pay the fee too
+func (tx *TxMsg) verifyFeePayerSignature(context ContextTx, toVerifyBytes []byte, info *TxMsgInfo) (uint32, string) {
+	if len(tx.FeePayer) != ankrcmm.KeyAddressLen {
+		return code.CodeTypeInvalidFeePayer, fmt.Sprintf("Unexpected fee payer address. Got %v, len=%d", tx.FeePayer, len(tx.FeePayer))
+	}
+
+	var sign *ankrcrypto.Signature
+	for i := range tx.Signs {
+		if tx.Signs[i].PubKey != nil && tx.Signs[i].PubKey.Address().String() == tx.FeePayer {
+			sign = &tx.Signs[i]
+			break
+		}
+	}
+
+	return tx.verifySigner(context, tx.FeePayer, sign, toVerifyBytes, info)
+}
+
+// verifyMultiSignature accepts the tx if there are at least threshold valid signatures from the different member keys
+// of the multisig account, the signatures of non member keys are ignored.
+func (tx *TxMsg) verifyMultiSignature(context ContextTx, msInfo *ankrcmm.MultiSigAccountInfo, toVerifyBytes []byte, info *TxMsgInfo) (uint32, string) {
+	memberMap := make(map[string]bool)
+	for _, pubKey := range msInfo.PubKeys {
+		addr, err := ankrcmm.AddressByPublicKey(pubKey)
+		if err == nil {
+			memberMap[addr] = true
+		}
+	}
+
+	signedMap := make(map[string]bool)
+	for i := range tx.Signs {
+		if tx.Signs[i].PubKey == nil {
+			continue
+		}
+
+		addr := tx.Signs[i].PubKey.Address().String()
+		if !memberMap[addr] || signedMap[addr] {
+			continue
+		}
+
+		if !tx.permitKey(context, info, tx.Signs[i].PubKey.Bytes()) {
 			return code.CodeTypeNotPermitPubKey, fmt.Sprintf("not permit public key: %v", tx.Signs[i].PubKey.Bytes())
 		}
 
-		isOk := tx.SecretKey().Verify(toVerifyBytes, &tx.Signs[i])
-		if !isOk {
-			return code.CodeTypeVerifySignaError, fmt.Sprintf("can't pass sign verifying for signer: pubKey=%v", tx.Signs[i].PubKey.Bytes())
+		if !tx.SecretKey().Verify(toVerifyBytes, &tx.Signs[i]) {
+			return code.CodeTypeVerifySignaError, fmt.Sprintf("can't pass sign verifying for multisig member: pubKey=%v", tx.Signs[i].PubKey.Bytes())
 		}
+
+		signedMap[addr] = true
+	}
+
+	if uint32(len(signedMap)) < msInfo.Threshold {
+		return code.CodeTypeMultiSigNotEnoughSigns, fmt.Sprintf("not enough signatures for multisig account: addr=%s, got %d, expected %d", msInfo.Address, len(signedMap), msInfo.Threshold)
 	}
 
 	return code.CodeTypeOK, ""
 }
 
 func (tx *TxMsg) verifyMinGasPrice(context ContextTx) (uint32, string) {
-	minGasPrice := context.MinGasPrice()
+	minGasPrice, ok := context.MinGasPrice(tx.GasPrice.Cur.Symbol)
+	if !ok {
+		return code.CodeTypeGasPriceIrregular, fmt.Sprintf("irregular tx gas price: unaccepted txGasSymbol=%s", tx.GasPrice.Cur.Symbol)
+	}
+
 	gasPriceVal := new(big.Int).SetBytes(tx.GasPrice.Value)
 	minGasPriceVal :=  new(big.Int).SetBytes(minGasPrice.Value)
-	if tx.GasPrice.Cur.Symbol != minGasPrice.Cur.Symbol || gasPriceVal.Cmp(minGasPriceVal) == -1 {
+	if gasPriceVal.Cmp(minGasPriceVal) == -1 {
 		return code.CodeTypeGasPriceIrregular, fmt.Sprintf("irregular tx gas price: txGasSymbol=%s, txGasPriceVal=%s, minGasSymbol=%s, minGasPriceVal=%s",
 			tx.GasPrice.Cur.Symbol, gasPriceVal.String(),
 			minGasPrice.Cur.Symbol, minGasPriceVal.String())
@@ -173,21 +359,137 @@ func (tx *TxMsg) verifyMinGasPrice(context ContextTx) (uint32, string) {
 	return code.CodeTypeOK, ""
 }
 
+// verifyTimeoutHeight rejects the tx if it is executed in the block after its TimeoutHeight, 0 means never expired
+func (tx *TxMsg) verifyTimeoutHeight(height int64) (uint32, string) {
+	if tx.TimeoutHeight > 0 && height > tx.TimeoutHeight {
+		return code.CodeTypeTxExpired, fmt.Sprintf("tx expired: timeoutHeight=%d, height=%d", tx.TimeoutHeight, height)
+	}
+
+	return code.CodeTypeOK, ""
+}
+
+// msgInfo looks up the registered info of the msg type, every type is accepted if the context has no registry
+func msgInfo(context ContextTx, msg ImplTxMsg) (*TxMsgInfo, uint32, string) {
+	registry := context.TxMsgRegistry()
+	if registry == nil {
+		return nil, code.CodeTypeOK, ""
+	}
+
+	info, ok := registry.Info(msg.Type())
+	if !ok {
+		return nil, code.CodeTypeUnknownTxMsgType, fmt.Sprintf("unknown tx msg type: %s", msg.Type())
+	}
+
+	return info, code.CodeTypeOK, ""
+}
+
+func (tx *TxMsg) txMsgInfo(context ContextTx) (*TxMsgInfo, uint32, string) {
+	return msgInfo(context, tx.ImplTxMsg)
+}
+
+func permitMsgKey(info *TxMsgInfo, store appstore.AppStore, msg ImplTxMsg, pubKey []byte) bool {
+	if info != nil && info.Permission != nil {
+		return info.Permission(store, msg, pubKey)
+	}
+
+	return msg.PermitKey(store, pubKey)
+}
+
+// permitKey checks the key by the permission of the msg type, and by the ones of the inner msgs if the msg is a container
+func (tx *TxMsg) permitKey(context ContextTx, info *TxMsgInfo, pubKey []byte) bool {
+	if !permitMsgKey(info, context.AppStore(), tx.ImplTxMsg, pubKey) {
+		return false
+	}
+
+	container, ok := tx.ImplTxMsg.(TxMsgContainer)
+	if !ok {
+		return true
+	}
+
+	for _, msg := range container.InnerMsgs() {
+		if msg == nil {
+			return false
+		}
+
+		innerInfo, codeT, _ := msgInfo(context, msg)
+		if codeT != code.CodeTypeOK || !permitMsgKey(innerInfo, context.AppStore(), msg, pubKey) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// processMsg charges the base gas of the msg type before processing the msg
+func processMsg(context ContextTx, info *TxMsgInfo, msg ImplTxMsg, metric gas.GasMetric, flag TxExeFlag) (uint32, string, []cmn.KVPair) {
+	if info != nil && info.BaseGas > 0 && !metric.SpendGas(new(big.Int).SetUint64(info.BaseGas)) {
+		return code.CodeTypeGasNotEnough, fmt.Sprintf("TxMsg, gas not enough for the base gas %d of %s", info.BaseGas, msg.Type()), nil
+	}
+
+	return msg.ProcessTx(context, metric, flag)
+}
+
+func (tx *TxMsg) process(context ContextTx, info *TxMsgInfo, metric gas.GasMetric, flag TxExeFlag) (uint32, string, []cmn.KVPair) {
+	return processMsg(context, info, tx.ImplTxMsg, metric, flag)
+}
+
+// ProcessInnerMsg processes the inner msg of a TxMsgContainer like a tx msg is processed: its type must be registered,
+// and its base gas is charged before it is processed
+func ProcessInnerMsg(context ContextTx, msg ImplTxMsg, metric gas.GasMetric, flag TxExeFlag) (uint32, string, []cmn.KVPair) {
+	info, codeT, log := msgInfo(context, msg)
+	if codeT != code.CodeTypeOK {
+		return codeT, log, nil
+	}
+
+	return processMsg(context, info, msg, metric, flag)
+}
+
 func (tx *TxMsg) BasicVerify(context ContextTx) (uint32, string) {
-	codeV, log := tx.verifySignature(context.AppStore(), context.TxSerializer())
+	// the tx checked now will be included in the next block at the earliest, and the mempool rechecks drop the expired ones after each commit
+	codeV, log := tx.verifyTimeoutHeight(context.ChainStateInfo().LatestHeight() + 1)
+	if codeV != code.CodeTypeOK {
+		return codeV, log
+	}
+
+	info, codeV, log := tx.txMsgInfo(context)
 	if codeV != code.CodeTypeOK {
 		return codeV, log
 	}
 
+	codeV, log = tx.verifySignature(context, info)
+	if codeV != code.CodeTypeOK {
+		return codeV, log
+	}
+
+	return tx.verifyNonce(context, context.NoncePool())
+}
+
+// normalizeNonce treats 0 as 1, since an account starts with nonce 1 and the store reports 0 for an account not created yet
+func normalizeNonce(nonce uint64) uint64 {
+	if nonce == 0 {
+		return 1
+	}
+
+	return nonce
+}
+
+// verifyNonce requires the tx nonce to equal the store nonce if noncePool is nil, otherwise the future nonces within the
+// pool window are accepted too
+func (tx *TxMsg) verifyNonce(context ContextTx, noncePool *NoncePool) (uint32, string) {
 	onceStore, _, _, _, err := context.AppStore().Nonce(tx.SignerAddr()[0], 0, false)
 	if err != nil {
 		return code.CodeTypeGetStoreNonceError, err.Error()
 	}
-	if onceStore != tx.Nonce && (tx.Nonce == 0 && onceStore != tx.Nonce + 1){
+
+	if noncePool != nil {
+		return noncePool.Verify(tx.SignerAddr()[0], normalizeNonce(onceStore), normalizeNonce(tx.Nonce))
+	}
+
+	if normalizeNonce(onceStore) != normalizeNonce(tx.Nonce) {
 		return code.CodeTypeBadNonce, fmt.Sprintf("bad nonce: address=%s, onceStore=%d, tx.Nonce=%d", tx.SignerAddr()[0], onceStore, tx.Nonce)
 	}
 
-    return code.CodeTypeOK, ""
+	return code.CodeTypeOK, ""
 }
 
 func (tx *TxMsg) verifyFromAddress() (uint32, string) {
@@ -209,73 +511,134 @@ func (tx *TxMsg) verifyFromAddress() (uint32, string) {
 	return code.CodeTypeOK, ""
 }
 
-func (tx *TxMsg) preRunForCheckTx(context ContextTx) types.ResponseCheckTx {
+// preRunForCheckTx runs the tx on a branch of the check state discarded after it, and returns the fee it would pay
+func (tx *TxMsg) preRunForCheckTx(context ContextTx) (types.ResponseCheckTx, *big.Int) {
+	info, codeT, log := tx.txMsgInfo(context)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
+	}
+
+	preRunContext, branch := BranchContext(context)
+	defer branch.Discard()
+
 	txSInfo := NewTxStateInfo(tx.GasLimit)
-	codeT, log, _ := tx.ProcessTx(context, txSInfo, TxExeFlag_PreRun)
+	codeT, log, _ = tx.process(preRunContext, info, txSInfo, TxExeFlag_PreRun)
 	if codeT != code.CodeTypeOK {
-		context.AppStore().Rollback()
-		return types.ResponseCheckTx{Code: codeT, Log: log}
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
 	}
 
 	if txSInfo.GasUsed == nil || txSInfo.GasUsed.Cmp(big.NewInt(0)) == 0 {
-		return types.ResponseCheckTx{Code: code.CodeTypeOK, GasWanted: 0}
+		return types.ResponseCheckTx{Code: code.CodeTypeOK, GasWanted: 0}, new(big.Int)
 	}
 
 	subGas := new(big.Int).Sub(txSInfo.GasUsed, new(big.Int).SetBytes(txSInfo.GasLimit))
 	if subGas.Cmp(big.NewInt(0)) == 1 || subGas.Cmp(big.NewInt(0)) == 0 {
-		context.AppStore().Rollback()
-		return types.ResponseCheckTx{Code: code.CodeTypeGasNotEnough, Log: fmt.Sprintf("TxMsg CheckTx, gas not enough, got %s", tx.GasUsed.String())}
+		return types.ResponseCheckTx{Code: code.CodeTypeGasNotEnough, Log: fmt.Sprintf("TxMsg CheckTx, gas not enough, got %s", tx.GasUsed.String())}, nil
 	}
 
 	usedFee := new(big.Int).Mul(txSInfo.GasUsed, new(big.Int).SetBytes(tx.GasPrice.Value))
-	balFrom, _, _, _, err := context.AppStore().Balance(tx.SignerAddr()[0], tx.GasPrice.Cur.Symbol, 0, false)
+	codeT, log = tx.verifyFee(preRunContext, usedFee)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
+	}
+
+	return types.ResponseCheckTx{Code: code.CodeTypeOK, GasWanted: new(big.Int).SetBytes(tx.GasLimit).Int64()}, usedFee
+}
+
+// verifyFee requires the balance of the fee payer to cover the fee besides the fees of its pending txs
+func (tx *TxMsg) verifyFee(context ContextTx, fee *big.Int) (uint32, string) {
+	if fee.Sign() == 0 {
+		return code.CodeTypeOK, ""
+	}
+
+	balFrom, _, _, _, err := context.AppStore().Balance(tx.FeePayerAddr(), tx.GasPrice.Cur.Symbol, 0, false)
 	if err != nil {
-		context.AppStore().Rollback()
-		return types.ResponseCheckTx{Code: code.CodeTypeLoadBalError, Log: fmt.Sprintf("TxMsg CheckTx, get bal err=%s， addr=%s", err.Error(), tx.SignerAddr()[0])}
+		return code.CodeTypeLoadBalError, fmt.Sprintf("TxMsg CheckTx, get bal err=%s， addr=%s", err.Error(), tx.FeePayerAddr())
 	}
-	if usedFee.Cmp(balFrom) == 1 || usedFee.Cmp(balFrom) == 0 {
-		context.AppStore().Rollback()
-		return types.ResponseCheckTx{Code: code.CodeTypeFeeNotEnough, Log: fmt.Sprintf("TxMsg CheckTx, fee not enough, got %s, expected %s", usedFee.String(), balFrom.String())}
+
+	totalFee := fee
+	if context.NoncePool() != nil {
+		totalFee = new(big.Int).Add(fee, context.NoncePool().PendingFee(tx.FeePayerAddr(), tx.GasPrice.Cur.Symbol))
 	}
 
-	context.AppStore().Rollback()
+	if totalFee.Cmp(balFrom) == 1 || totalFee.Cmp(balFrom) == 0 {
+		return code.CodeTypeFeeNotEnough, fmt.Sprintf("TxMsg CheckTx, fee not enough, got %s, expected %s", totalFee.String(), balFrom.String())
+	}
+
+	return code.CodeTypeOK, ""
+}
+
+func (tx *TxMsg) CheckTx(context ContextTx) types.ResponseCheckTx {
+	respCheckTx, _ := tx.CheckTxWithType(context, CheckTxTypeNew, nil)
 
-	return types.ResponseCheckTx{Code: code.CodeTypeOK, GasWanted: new(big.Int).SetBytes(tx.GasLimit).Int64()}
+	return respCheckTx
 }
 
-func (tx *TxMsg) CheckTx(context ContextTx) (respCheckTx types.ResponseCheckTx) {
+// CheckTxWithType fully checks the new tx and returns the fee it would pay. The recheck only verifies the nonce and
+// the fee balance with the fee returned by the check before, since the signature and the msg have been checked.
+func (tx *TxMsg) CheckTxWithType(context ContextTx, checkType CheckTxType, checkedFee *big.Int) (respCheckTx types.ResponseCheckTx, fee *big.Int) {
 	defer func() {
 		if rErr := recover(); rErr != nil {
-			context.AppStore().Rollback()
 			respCheckTx = types.ResponseCheckTx{Code: code.CodeTypeCheckTxError, Log: fmt.Sprintf("TxMsg CheckTx, err %v", rErr)}
+			fee = nil
 		}
 	}()
 
+	if checkType == CheckTxTypeRecheck && checkedFee != nil {
+		respCheckTx, fee = tx.recheckTx(context, checkedFee)
+	} else {
+		respCheckTx, fee = tx.checkNewTx(context)
+	}
+
+	if respCheckTx.Code != code.CodeTypeOK {
+		return respCheckTx, nil
+	}
+
+	if context.NoncePool() != nil {
+		context.NoncePool().Accept(tx.SignerAddr()[0], normalizeNonce(tx.Nonce))
+		context.NoncePool().AcceptFee(tx.FeePayerAddr(), tx.GasPrice.Cur.Symbol, fee)
+	}
+
+	return respCheckTx, fee
+}
+
+func (tx *TxMsg) checkNewTx(context ContextTx) (types.ResponseCheckTx, *big.Int) {
 	codeT, log := tx.BasicVerify(context)
 	if codeT != code.CodeTypeOK {
-		return types.ResponseCheckTx{Code: codeT, Log: log}
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
 	}
 
 	codeT, log = tx.verifyMinGasPrice(context)
 	if codeT != code.CodeTypeOK {
-		return types.ResponseCheckTx{Code: codeT, Log: log}
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
 	}
 
 	codeT, log = tx.verifyFromAddress()
 	if codeT != code.CodeTypeOK {
-		return types.ResponseCheckTx{Code: codeT, Log: log}
+		return types.ResponseCheckTx{Code: codeT, Log: log}, nil
 	}
 
 	return tx.preRunForCheckTx(context)
 }
 
+// feeCurrency returns the currency the fee is paid in, its decimal is taken from the currency info rather than the tx
+func (tx *TxMsg) feeCurrency(context ContextTx) ankrcmm.Currency {
+	curInfo, _, _, _, err := context.AppStore().CurrencyInfo(tx.GasPrice.Cur.Symbol, 0, false)
+	if err != nil || curInfo == nil {
+		return ankrcmm.Currency{tx.GasPrice.Cur.Symbol, 18}
+	}
+
+	return ankrcmm.Currency{tx.GasPrice.Cur.Symbol, curInfo.Decimal}
+}
+
 func (tx *TxMsg) gasCharge(context ContextTx, usedFee *big.Int) error {
 	foundBal, _, _, _, err := context.AppStore().Balance(account.AccountManagerInstance().FoundAccountAddress(), tx.GasPrice.Cur.Symbol, 0, false)
 	if err != nil {
-		return  fmt.Errorf("TxMsg DeliverTx, get bal err=%s， addr=%s", err.Error(), account.AccountManagerInstance().FoundAccountAddress())
+		// the found account may not hold the fee currency other than ANKR yet
+		foundBal = new(big.Int).SetUint64(0)
 	}
 	foundBal = new(big.Int).Add(foundBal, usedFee)
-	context.AppStore().SetBalance(account.AccountManagerInstance().FoundAccountAddress(), ankrcmm.Amount{ankrcmm.Currency{tx.GasPrice.Cur.Symbol, 18}, foundBal.Bytes()})
+	context.AppStore().SetBalance(account.AccountManagerInstance().FoundAccountAddress(), ankrcmm.Amount{tx.feeCurrency(context), foundBal.Bytes()})
 
 	return nil
 }
@@ -283,53 +646,123 @@ func (tx *TxMsg) gasCharge(context ContextTx, usedFee *big.Int) error {
 func (tx *TxMsg) DeliverTx(context ContextTx) (respDeliverTx types.ResponseDeliverTx) {
 	defer func() {
 		if rErr := recover(); rErr != nil {
-			context.AppStore().Rollback()
 			respDeliverTx = types.ResponseDeliverTx{Code: code.CodeTypeDeliverTxError, Log: fmt.Sprintf("TxMsg DeliverTx, err %v", rErr)}
 		}
 	}()
 
+	info, codeT, log := tx.txMsgInfo(context)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseDeliverTx{Code: codeT, Log: log}
+	}
+
+	codeT, log = tx.verifyTimeoutHeight(context.ChainStateInfo().LatestHeight())
+	if codeT != code.CodeTypeOK {
+		return types.ResponseDeliverTx{Code: codeT, Log: log}
+	}
+
+	// the proposer may include the txs which never pass CheckTx, so the signatures are verified again once the upgrade
+	// is applied, the blocks before it are replayed without the verification as they were delivered
+	if context.AppStore().UpgradeDoneHeight(upgrade.UpgradeDeliverTxSignature) > 0 {
+		codeT, log = tx.verifySignature(context, info)
+		if codeT != code.CodeTypeOK {
+			return types.ResponseDeliverTx{Code: codeT, Log: log}
+		}
+	}
+
+	codeT, log = tx.verifyNonce(context, nil)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseDeliverTx{Code: codeT, Log: log}
+	}
+
+	codeT, log = tx.verifyMinGasPrice(context)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseDeliverTx{Code: codeT, Log: log}
+	}
+
 	context.AppStore().IncTotalTx()
 
-	codeT, log, tags := tx.ProcessTx(context, tx, TxExeFlag_Run)
+	// the writes of the tx are kept in the branch and written only if the tx succeeds, so a failed tx never touches the
+	// writes of the txs before it in the block
+	txContext, branch := BranchContext(context)
+	defer branch.Discard()
+
+	codeT, log, tags := tx.process(txContext, info, tx, TxExeFlag_Run)
 	if codeT != code.CodeTypeOK {
-		context.AppStore().Rollback()
 		return types.ResponseDeliverTx{Code: codeT, Log: log}
 	}
 
 	if tx.GasUsed == nil || tx.GasUsed.Cmp(big.NewInt(0)) == 0 {
+		branch.Write()
 		return types.ResponseDeliverTx{Code: code.CodeTypeOK, Log: log, GasWanted: 0, GasUsed: 0, Tags: tags}
 	}
 
 	subGas := new(big.Int).Sub(tx.GasUsed, new(big.Int).SetBytes(tx.GasLimit))
 	if subGas.Cmp(big.NewInt(0)) == 1 || subGas.Cmp(big.NewInt(0)) == 0 {
-		context.AppStore().Rollback()
 		return types.ResponseDeliverTx{Code: code.CodeTypeGasNotEnough, Log: fmt.Sprintf("TxMsg DeliverTx, gas not enough, got %s", tx.GasUsed.String())}
 	}
 
 	usedFee := new(big.Int).Mul(tx.GasUsed, new(big.Int).SetBytes(tx.GasPrice.Value))
-	balFrom, _, _, _, err := context.AppStore().Balance(tx.SignerAddr()[0], tx.GasPrice.Cur.Symbol, 0, false)
+	balFrom, _, _, _, err := txContext.AppStore().Balance(tx.FeePayerAddr(), tx.GasPrice.Cur.Symbol, 0, false)
 	if err != nil {
-		context.AppStore().Rollback()
-		return types.ResponseDeliverTx{Code: code.CodeTypeLoadBalError, Log: fmt.Sprintf("TxMsg DeliverTx, get bal err=%s， addr=%s", err.Error(), tx.SignerAddr()[0])}
+		return types.ResponseDeliverTx{Code: code.CodeTypeLoadBalError, Log: fmt.Sprintf("TxMsg DeliverTx, get bal err=%s， addr=%s", err.Error(), tx.FeePayerAddr())}
 	}
 	if usedFee.Cmp(balFrom) == 1 || usedFee.Cmp(balFrom) == 0 {
-		context.AppStore().Rollback()
-		err = tx.gasCharge(context, balFrom)
-		if err != nil {
-			context.AppStore().Rollback()
-		}
+		// the writes of the tx are discarded, but the gas is still charged
+		branch.Discard()
+		tx.gasCharge(context, balFrom)
 		return types.ResponseDeliverTx{Code: code.CodeTypeFeeNotEnough, Log: fmt.Sprintf("TxMsg DeliverTx, fee not enough, got %s, expected %s", usedFee.String(), balFrom.String())}
 	}
 
 	balFrom = new(big.Int).Sub(balFrom, usedFee)
 
-	context.AppStore().SetBalance(tx.SignerAddr()[0], ankrcmm.Amount{ankrcmm.Currency{tx.GasPrice.Cur.Symbol, 18}, balFrom.Bytes()})
+	txContext.AppStore().SetBalance(tx.FeePayerAddr(), ankrcmm.Amount{tx.feeCurrency(txContext), balFrom.Bytes()})
 
-	err = tx.gasCharge(context, usedFee)
+	err = tx.gasCharge(txContext, usedFee)
 	if err != nil {
-		context.AppStore().Rollback()
 		return types.ResponseDeliverTx{Code: code.CodeTypeGasChargeError, Log: fmt.Sprintf("TxMsg DeliverTx, gas charge err=%s", err.Error())}
 	}
 
+	branch.Write()
+
 	return types.ResponseDeliverTx{Code: code.CodeTypeOK, Log: log, GasWanted: new(big.Int).SetBytes(tx.GasLimit).Int64(), GasUsed: tx.GasUsed.Int64(), Tags: tags}
 }
+
+// Simulate executes the tx against the context's store without charging fee, the caller should pass a context whose store
+// is a throwaway one. The signatures are verified only if they are attached, so an unsigned tx can be estimated too.
+// The tx is run in Run mode since PreRun skips building the log and tags, and the gas limit is lifted if it is blank.
+func (tx *TxMsg) Simulate(context ContextTx) (respSimulate types.ResponseDeliverTx) {
+	defer func() {
+		if rErr := recover(); rErr != nil {
+			respSimulate = types.ResponseDeliverTx{Code: code.CodeTypeDeliverTxError, Log: fmt.Sprintf("TxMsg Simulate, err %v", rErr)}
+		}
+	}()
+
+	info, codeT, log := tx.txMsgInfo(context)
+	if codeT != code.CodeTypeOK {
+		return types.ResponseDeliverTx{Code: codeT, Log: log}
+	}
+
+	if len(tx.Signs) > 0 {
+		codeT, log := tx.BasicVerify(context)
+		if codeT != code.CodeTypeOK {
+			return types.ResponseDeliverTx{Code: codeT, Log: log}
+		}
+	} else {
+		codeT, log := tx.verifyTimeoutHeight(context.ChainStateInfo().LatestHeight() + 1)
+		if codeT != code.CodeTypeOK {
+			return types.ResponseDeliverTx{Code: codeT, Log: log}
+		}
+	}
+
+	gasLimit := tx.GasLimit
+	if len(gasLimit) == 0 {
+		gasLimit = new(big.Int).SetUint64(math.MaxInt64).Bytes()
+	}
+
+	txSInfo := NewTxStateInfo(gasLimit)
+	txSInfo.Flag = TxExeFlag_Run
+
+	codeT, log, tags := tx.process(context, info, txSInfo, TxExeFlag_Run)
+
+	return types.ResponseDeliverTx{Code: codeT, Log: log, GasWanted: new(big.Int).SetBytes(tx.GasLimit).Int64(), GasUsed: txSInfo.GasUsed.Int64(), Tags: tags}
+}
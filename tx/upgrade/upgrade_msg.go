@@ -0,0 +1,107 @@
+package upgrade
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Ankr-network/ankr-chain/account"
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/common/code"
+	ankrcrypto "github.com/Ankr-network/ankr-chain/crypto"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	"github.com/Ankr-network/ankr-chain/tx"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+	"github.com/Ankr-network/wagon/exec/gas"
+	cmn "github.com/tendermint/tendermint/libs/common"
+)
+
+// UpgradeMsg schedules the upgrade named at the height, which replaces the plan scheduled before. The height 0 cancels
+// the plan of the name. It can only be signed by the admin op account.
+type UpgradeMsg struct {
+	FromAddr string `json:"fromaddr"`
+	Name     string `json:"name"`
+	Height   int64  `json:"height"`
+	Info     string `json:"info"`
+}
+
+func NewUpgradeTxMsg() *tx.TxMsg {
+	return &tx.TxMsg{ImplTxMsg: new(UpgradeMsg)}
+}
+
+func (um *UpgradeMsg) SignerAddr() []string {
+	return []string {um.FromAddr}
+}
+
+func (um *UpgradeMsg) Type() string {
+	return txcmm.TxMsgTypeUpgradeMsg
+}
+
+func (um *UpgradeMsg) Bytes(txSerializer tx.TxSerializer) []byte {
+	bytes, _ := txSerializer.MarshalJSON(um)
+	return bytes
+}
+
+func (um *UpgradeMsg) SetSecretKey(sk ankrcrypto.SecretKey) {
+
+}
+
+func (um *UpgradeMsg) SecretKey() ankrcrypto.SecretKey {
+	return &ankrcrypto.SecretKeyEd25519{}
+}
+
+func (um *UpgradeMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
+	return account.AccountManagerInstance().IsAdminPubKey(store, ankrcmm.AccountAdminOP, pubKey)
+}
+
+func (um *UpgradeMsg) SenderAddr() string {
+	return um.FromAddr
+}
+
+func (um *UpgradeMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string, []cmn.KVPair) {
+	if len(um.FromAddr) != ankrcmm.KeyAddressLen {
+		return code.CodeTypeInvalidAddress, fmt.Sprintf("UpgradeMsg ProcessTx, unexpected from address. Got %s, addr len=%d", um.FromAddr, len(um.FromAddr)), nil
+	}
+
+	if um.Name == "" {
+		return code.CodeTypeInvalidUpgradePlan, "UpgradeMsg ProcessTx, blank upgrade name", nil
+	}
+
+	store := context.AppStore()
+	if doneHeight := store.UpgradeDoneHeight(um.Name); doneHeight > 0 {
+		return code.CodeTypeInvalidUpgradePlan, fmt.Sprintf("UpgradeMsg ProcessTx, the upgrade has been applied: name=%s, height=%d", um.Name, doneHeight), nil
+	}
+
+	plan, _, _, _, _ := store.UpgradePlan(0, false)
+	if um.Height == 0 {
+		if plan == nil || plan.Name != um.Name {
+			return code.CodeTypeInvalidUpgradePlan, fmt.Sprintf("UpgradeMsg ProcessTx, no upgrade plan to cancel: name=%s", um.Name), nil
+		}
+	} else if curHeight := store.Height() + 1; um.Height <= curHeight {
+		// the plan must be applied by the BeginBlock of a later block
+		return code.CodeTypeInvalidUpgradePlan, fmt.Sprintf("UpgradeMsg ProcessTx, the upgrade height must be greater than the current height: height=%d, curHeight=%d", um.Height, curHeight), nil
+	}
+
+	if flag == tx.TxExeFlag_OnlyCheck || flag == tx.TxExeFlag_PreRun {
+		return code.CodeTypeOK, "", nil
+	}
+
+	if um.Height == 0 {
+		store.DeleteUpgradePlan()
+	} else {
+		store.SetUpgradePlan(&ankrcmm.UpgradePlan{um.Name, um.Height, um.Info})
+	}
+
+	store.IncNonce(um.FromAddr)
+
+	tvalue := time.Now().UnixNano()
+	tags := []cmn.KVPair{
+		{Key: []byte("app.fromaddress"), Value: []byte(um.FromAddr)},
+		{Key: []byte("app.upgradename"), Value: []byte(um.Name)},
+		{Key: []byte("app.upgradeheight"), Value: []byte(strconv.FormatInt(um.Height, 10))},
+		{Key: []byte("app.timestamp"), Value: []byte(strconv.FormatInt(tvalue, 10))},
+		{Key: []byte("app.type"), Value: []byte(txcmm.TxMsgTypeUpgradeMsg)},
+	}
+
+	return code.CodeTypeOK, "", tags
+}
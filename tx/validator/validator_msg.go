@@ -1,8 +1,6 @@
 package validator
 
 import (
-	"bytes"
-	"encoding/base64"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -90,17 +88,7 @@ func (v *ValidatorMsg) SecretKey() ankrcrypto.SecretKey {
 }
 
 func (v *ValidatorMsg) PermitKey(store appstore.AppStore, pubKey []byte) bool {
-	adminPubkey := store.Get([]byte(ankrcmm.ADMIN_OP_VAL_PUBKEY_NAME))
-	if len(adminPubkey) == 0 {
-		adminPubkey = []byte(account.AccountManagerInstance().AdminOpAccount(ankrcmm.AccountAdminValidator))
-	}
-
-	adminPubKeyStr, err := base64.StdEncoding.DecodeString(string(adminPubkey))
-	if err != nil {
-		return false
-	}
-
-	return  bytes.Equal(pubKey, []byte(adminPubKeyStr))
+	return account.AccountManagerInstance().IsAdminPubKey(store, ankrcmm.AccountAdminValidator, pubKey)
 }
 
 func (v *ValidatorMsg) ProcessTx(context tx.ContextTx, metric gas.GasMetric, flag tx.TxExeFlag) (uint32, string,  []cmn.KVPair) {
@@ -0,0 +1,220 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	ankrcmm "github.com/Ankr-network/ankr-chain/common"
+	"github.com/Ankr-network/ankr-chain/store/appstore"
+	txcmm "github.com/Ankr-network/ankr-chain/tx/common"
+)
+
+// the tx formats whose versions are checked
+const (
+	TxFormatCDCV0 = "cdcv0"
+	TxFormatCDCV1 = "cdcv1"
+	TxFormatProto = "proto"
+)
+
+// TxVersionRule limits the versions of the txs of the format and msg type, the blank msg type means all the msg types
+// of the format without their own rules
+type TxVersionRule struct {
+	Format   string
+	MsgType  string
+	Versions []string
+}
+
+// MigrateHandler migrates the app state when the upgrade is applied, and it runs only once at the upgrade height
+type MigrateHandler func(store appstore.AppStore) error
+
+// Upgrade is registered by the binary supporting it before the application is created. Its tx version rules replace
+// the ones of the same format and msg type from the upgrade height.
+type Upgrade struct {
+	Name       string
+	Migrate    MigrateHandler
+	TxVersions []TxVersionRule
+}
+
+// the upgrades of the built-in features, which take effect from the heights the admin schedules them at
+const (
+	UpgradeDeliverTxSignature = "delivertx-signature" // DeliverTx verifies the signatures as CheckTx does
+
+	// UpgradeStoreAppHash makes the app hash commit to the root hashes of the stores, so the snapshots taken from its
+	// height are checked against the app hash when restored. The nodes restored from the snapshots taken before it have
+	// root hashes other than the ones of the chain, they must be reset and restored again after the upgrade height.
+	UpgradeStoreAppHash = "store-apphash"
+)
+
+var builtinUpgrades = []*Upgrade{
+	{Name: UpgradeDeliverTxSignature},
+	{Name: UpgradeStoreAppHash},
+}
+
+var builtinTxVersions = []TxVersionRule{
+	{Format: TxFormatCDCV0, Versions: []string{"0.31.5", "1.0", ""}},
+	{Format: TxFormatCDCV1, MsgType: txcmm.TxMsgTypeTransfer, Versions: []string{"1.0.2"}},
+}
+
+type UpgradeManager struct {
+	upgradeMap    map[string]*Upgrade
+	txVersionMap  map[string]map[string][]string
+	upgradeLocker sync.RWMutex
+}
+
+// ErrUpgradeNeeded is returned at the height of the plan which the binary doesn't support, the node must halt there
+// and be restarted with the binary supporting it
+type ErrUpgradeNeeded struct {
+	Plan ankrcmm.UpgradePlan
+}
+
+func (e *ErrUpgradeNeeded) Error() string {
+	return fmt.Sprintf("UPGRADE \"%s\" NEEDED at height %d: %s", e.Plan.Name, e.Plan.Height, e.Plan.Info)
+}
+
+func (um *UpgradeManager) resetTxVersions() {
+	um.txVersionMap = make(map[string]map[string][]string)
+	for _, rule := range builtinTxVersions {
+		um.setTxVersions(rule)
+	}
+}
+
+func (um *UpgradeManager) setTxVersions(rule TxVersionRule) {
+	if _, ok := um.txVersionMap[rule.Format]; !ok {
+		um.txVersionMap[rule.Format] = make(map[string][]string)
+	}
+
+	um.txVersionMap[rule.Format][rule.MsgType] = rule.Versions
+}
+
+// Register adds the upgrade supported by the binary
+func (um *UpgradeManager) Register(upgrade *Upgrade) error {
+	if upgrade == nil || upgrade.Name == "" {
+		return errors.New("blank upgrade name")
+	}
+
+	um.upgradeLocker.Lock()
+	defer um.upgradeLocker.Unlock()
+
+	if _, ok := um.upgradeMap[upgrade.Name]; ok {
+		return fmt.Errorf("upgrade %s has been registered", upgrade.Name)
+	}
+
+	um.upgradeMap[upgrade.Name] = upgrade
+
+	return nil
+}
+
+// Load rebuilds the tx version rules from the upgrades applied, in the order of their heights
+func (um *UpgradeManager) Load(store appstore.AppStore) {
+	um.upgradeLocker.Lock()
+	defer um.upgradeLocker.Unlock()
+
+	var doneUpgrades []*Upgrade
+	doneHeights := make(map[string]int64)
+	for name, upgrade := range um.upgradeMap {
+		if height := store.UpgradeDoneHeight(name); height > 0 {
+			doneUpgrades = append(doneUpgrades, upgrade)
+			doneHeights[name] = height
+		}
+	}
+
+	sort.Slice(doneUpgrades, func(i, j int) bool {
+		return doneHeights[doneUpgrades[i].Name] < doneHeights[doneUpgrades[j].Name]
+	})
+
+	um.resetTxVersions()
+	for _, upgrade := range doneUpgrades {
+		for _, rule := range upgrade.TxVersions {
+			um.setTxVersions(rule)
+		}
+	}
+}
+
+// BeginBlock applies the plan scheduled at the height. ErrUpgradeNeeded is returned if the binary doesn't support the
+// plan, and the error of the migration is returned if it fails, the node must halt in both cases.
+func (um *UpgradeManager) BeginBlock(height int64, store appstore.AppStore) (*ankrcmm.UpgradePlan, error) {
+	plan, _, _, _, err := store.UpgradePlan(0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan == nil || plan.Height > height {
+		return nil, nil
+	}
+
+	um.upgradeLocker.Lock()
+	defer um.upgradeLocker.Unlock()
+
+	upgrade, ok := um.upgradeMap[plan.Name]
+	if !ok {
+		return plan, &ErrUpgradeNeeded{*plan}
+	}
+
+	if upgrade.Migrate != nil {
+		if err := upgrade.Migrate(store); err != nil {
+			return plan, fmt.Errorf("upgrade %s migration failed: %v", plan.Name, err)
+		}
+	}
+
+	store.SetUpgradeDone(plan.Name, height)
+	store.DeleteUpgradePlan()
+
+	for _, rule := range upgrade.TxVersions {
+		um.setTxVersions(rule)
+	}
+
+	return plan, nil
+}
+
+// AcceptTxVersion reports whether the tx version is accepted by the rules in effect, the txs without rules are all accepted
+func (um *UpgradeManager) AcceptTxVersion(format string, msgType string, version string) bool {
+	um.upgradeLocker.RLock()
+	defer um.upgradeLocker.RUnlock()
+
+	msgTypeMap, ok := um.txVersionMap[format]
+	if !ok {
+		return true
+	}
+
+	versions, ok := msgTypeMap[msgType]
+	if !ok {
+		if versions, ok = msgTypeMap[""]; !ok {
+			return true
+		}
+	}
+
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TxVersions returns the versions accepted of the tx format and msg type, nil if all are accepted
+func (um *UpgradeManager) TxVersions(format string, msgType string) []string {
+	um.upgradeLocker.RLock()
+	defer um.upgradeLocker.RUnlock()
+
+	if versions, ok := um.txVersionMap[format][msgType]; ok {
+		return versions
+	}
+
+	return um.txVersionMap[format][""]
+}
+
+// NewUpgradeManager returns the manager with the built-in upgrades and tx version rules, the upgrades supported by the binary should
+// be registered into it before the application owning it is created
+func NewUpgradeManager() *UpgradeManager {
+	um := &UpgradeManager{upgradeMap: make(map[string]*Upgrade)}
+	um.resetTxVersions()
+
+	for _, upgrade := range builtinUpgrades {
+		um.upgradeMap[upgrade.Name] = upgrade
+	}
+
+	return um
+}